@@ -1,11 +1,12 @@
 package api
 
 import (
+	"context"
 	"fmt"
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go-movie-api/configs"
+	"go-movie-api/database"
 	m "go-movie-api/middleware"
 	_authController "go-movie-api/modules/auth/controller/http"
 	_authService "go-movie-api/modules/auth/service"
@@ -13,21 +14,65 @@ import (
 	_userController "go-movie-api/modules/user/controller/http"
 	_userRepo "go-movie-api/modules/user/repository"
 	_userService "go-movie-api/modules/user/service"
+	"go-movie-api/storage"
 	"go-movie-api/token"
 	"go-movie-api/utils"
+	"go-movie-api/utils/etag"
+	"go-movie-api/utils/patch"
 	"gorm.io/gorm"
 	"net/http"
 	"time"
 
+	_autocompleteController "go-movie-api/modules/autocomplete/controller/http"
+	_autocompleteRepo "go-movie-api/modules/autocomplete/repository"
+	_autocompleteService "go-movie-api/modules/autocomplete/service"
+	_collectionController "go-movie-api/modules/collection/controller/http"
+	_collectionRepo "go-movie-api/modules/collection/repository"
+	_collectionService "go-movie-api/modules/collection/service"
+	_commentController "go-movie-api/modules/comment/controller/http"
+	_commentRepo "go-movie-api/modules/comment/repository"
+	_commentService "go-movie-api/modules/comment/service"
+	_exportService "go-movie-api/modules/export/service"
 	_genreController "go-movie-api/modules/genre/controller/http"
 	_genreRepo "go-movie-api/modules/genre/repository"
 	_genreService "go-movie-api/modules/genre/service"
+	_imageController "go-movie-api/modules/image/controller/http"
+	_imageService "go-movie-api/modules/image/service"
+	_importerController "go-movie-api/modules/importer/controller/http"
+	_importerRepo "go-movie-api/modules/importer/repository"
+	_importerService "go-movie-api/modules/importer/service"
+	_importerSource "go-movie-api/modules/importer/source"
+	_importJobController "go-movie-api/modules/importjob/controller/http"
+	_importJobRepo "go-movie-api/modules/importjob/repository"
+	_importJobService "go-movie-api/modules/importjob/service"
+	_listController "go-movie-api/modules/list/controller/http"
+	_listRepo "go-movie-api/modules/list/repository"
+	_listService "go-movie-api/modules/list/service"
+	_moderationController "go-movie-api/modules/moderation/controller/http"
+	_moderationRepo "go-movie-api/modules/moderation/repository"
+	_moderationService "go-movie-api/modules/moderation/service"
 	_movieController "go-movie-api/modules/movie/controller/http"
 	_movieRepo "go-movie-api/modules/movie/repository"
 	_movieService "go-movie-api/modules/movie/service"
+	_personController "go-movie-api/modules/person/controller/http"
+	_personRepo "go-movie-api/modules/person/repository"
+	_personService "go-movie-api/modules/person/service"
 	_ratingController "go-movie-api/modules/rating/controller/http"
 	_ratingRepo "go-movie-api/modules/rating/repository"
 	_ratingService "go-movie-api/modules/rating/service"
+	_releaseController "go-movie-api/modules/release/controller/http"
+	_releaseRepo "go-movie-api/modules/release/repository"
+	_releaseService "go-movie-api/modules/release/service"
+	_revisionController "go-movie-api/modules/revision/controller/http"
+	_revisionRepo "go-movie-api/modules/revision/repository"
+	_revisionService "go-movie-api/modules/revision/service"
+	_translationController "go-movie-api/modules/translation/controller/http"
+	_translationRepo "go-movie-api/modules/translation/repository"
+	_translationService "go-movie-api/modules/translation/service"
+	_trashService "go-movie-api/modules/trash/service"
+	_watchlistController "go-movie-api/modules/watchlist/controller/http"
+	_watchlistRepo "go-movie-api/modules/watchlist/repository"
+	_watchlistService "go-movie-api/modules/watchlist/service"
 )
 
 func InitializedRouter(db *gorm.DB) *echo.Echo {
@@ -37,7 +82,8 @@ func InitializedRouter(db *gorm.DB) *echo.Echo {
 	router.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		Skipper:          middleware.DefaultSkipper,
 		AllowOrigins:     []string{"*"},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXCSRFToken},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXCSRFToken, etag.HeaderIfMatch, etag.HeaderIfNoneMatch},
+		ExposeHeaders:    []string{etag.HeaderETag, patch.HeaderAcceptPatch},
 		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -48,11 +94,18 @@ func InitializedRouter(db *gorm.DB) *echo.Echo {
 	router.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(100)))
 
 	// Config Validator to Router
-	router.Validator = &utils.RequestValidator{Validator: validator.New()}
+	requestValidator, err := utils.NewValidator()
+	if err != nil {
+		utils.Logger.Fatal(fmt.Sprintf("failed to register validation: %s", err))
+	}
+	router.Validator = &utils.RequestValidator{Validator: requestValidator}
 
 	// Register RequestLog to Router Middleware
 	router.Use(utils.RequestLog)
 
+	// Negotiate the locale of translated content from Accept-Language
+	router.Use(m.Locale)
+
 	// Register HTTP Error Handler function
 	router.HTTPErrorHandler = utils.ErrorHandler
 
@@ -70,36 +123,144 @@ func registerRoutes(router *echo.Echo, db *gorm.DB) {
 		})
 	})
 
+	transactor := database.NewTransactor(db)
+
 	tokenMaker, err := token.NewJWTMaker(configs.Env.JWTKey)
 	if err != nil {
 		utils.Logger.Fatal(fmt.Sprintf("failed to create token maker: %s", err))
 	}
 	token.TokenMaker = tokenMaker
 
+	blobStore, err := storage.NewBlobStore()
+	if err != nil {
+		utils.Logger.Fatal(fmt.Sprintf("failed to create blob store: %s", err))
+	}
+	// the local store serves its own files, other stores serve them from the bucket
+	if local, ok := blobStore.(*storage.LocalStore); ok {
+		route, err := local.Route()
+		if err != nil {
+			utils.Logger.Fatal(fmt.Sprintf("failed to route the local store: %s", err))
+		}
+		router.GET(route+"/*", echo.WrapHandler(http.StripPrefix(route, local)))
+	}
+
 	userRepo := _userRepo.NewUserRepository(db)
 	sessionRepo := _sessionRepo.NewSessionRepository(db)
 	m.AuthMiddleware = m.NewAuthMiddleware(sessionRepo, userRepo)
+	movieRepo := _movieRepo.NewMovieRepository(db)
+	genreRepo := _genreRepo.NewGenreRepository(db)
+	ratingRepo := _ratingRepo.NewRatingRepository(db)
+	provenanceRepo := _importerRepo.NewProvenanceRepository(db)
+	commentRepo := _commentRepo.NewCommentRepository(db)
 
-	// User
-	userService := _userService.NewUserService(userRepo, timeout)
+	// User, purging a user refreshes the rating aggregates of the movies it rated
+	userService := _userService.NewUserService(userRepo, ratingRepo, movieRepo, commentRepo, transactor, timeout)
 	_userController.NewUserController(router, userService)
 
 	// Auth
 	authService := _authService.NewAuthService(userRepo, sessionRepo, timeout)
 	_authController.NewAuthController(router, authService, userService)
 
+	// Autocomplete
+	autocompleteRepo := _autocompleteRepo.NewAutocompleteRepository(db)
+	autocompleteService := _autocompleteService.NewAutocompleteService(autocompleteRepo, timeout)
+	_autocompleteController.NewAutocompleteController(router, autocompleteService)
+
+	// Revisions, the movie and genre services record one for every change they make
+	revisionRepo := _revisionRepo.NewRevisionRepository(db)
+	revisionService := _revisionService.NewRevisionService(revisionRepo, movieRepo, genreRepo, provenanceRepo, autocompleteService, transactor, timeout)
+	_revisionController.NewRevisionController(router, revisionService)
+
 	// Genre
-	genreRepo := _genreRepo.NewGenreRepository(db)
-	genreService := _genreService.NewGenreService(genreRepo, timeout)
+	genreService := _genreService.NewGenreService(genreRepo, autocompleteService, revisionService, transactor, timeout)
 	_genreController.NewGenreController(router, genreService)
 
 	// Movies
-	movieRepo := _movieRepo.NewMovieRepository(db)
-	movieService := _movieService.NewMovieService(movieRepo, genreRepo, timeout)
+	watchlistRepo := _watchlistRepo.NewWatchlistRepository(db)
+	imageService := _imageService.NewImageService(movieRepo, blobStore, transactor, timeout)
+	movieService := _movieService.NewMovieService(movieRepo, genreRepo, watchlistRepo, autocompleteService, imageService, provenanceRepo, revisionService, transactor, timeout)
 	_movieController.NewMovieController(router, movieService)
+	_imageController.NewImageController(router, imageService)
+
+	// People
+	personRepo := _personRepo.NewPersonRepository(db)
+	personService := _personService.NewPersonService(personRepo, movieRepo, transactor, timeout)
+	_personController.NewPersonController(router, personService)
+
+	// Translations
+	translationRepo := _translationRepo.NewTranslationRepository(db)
+	translationService := _translationService.NewTranslationService(translationRepo, movieRepo, genreRepo, timeout)
+	_translationController.NewTranslationController(router, translationService)
+
+	// Releases
+	releaseRepo := _releaseRepo.NewReleaseRepository(db)
+	releaseService := _releaseService.NewReleaseService(releaseRepo, movieRepo, transactor, timeout)
+	_releaseController.NewReleaseController(router, releaseService)
+
+	// Imports
+	externalIDRepo := _importerRepo.NewExternalIDRepository(db)
+	importService := _importerService.NewImportService(_importerSource.NewImporters(), externalIDRepo, provenanceRepo, movieRepo, movieService, revisionService, autocompleteService, transactor, timeout)
+	_importerController.NewImportController(router, importService)
+
+	// Collections
+	collectionRepo := _collectionRepo.NewCollectionRepository(db)
+	collectionService := _collectionService.NewCollectionService(collectionRepo, movieRepo, transactor, timeout)
+	_collectionController.NewCollectionController(router, collectionService)
+
+	// Watchlist
+	watchlistService := _watchlistService.NewWatchlistService(watchlistRepo, movieRepo, transactor, timeout)
+	_watchlistController.NewWatchlistController(router, watchlistService)
+
+	// Movie lists
+	movieListRepo := _listRepo.NewMovieListRepository(db)
+	movieListService := _listService.NewMovieListService(movieListRepo, movieRepo, transactor, timeout)
+	_listController.NewMovieListController(router, movieListService)
+
+	// Moderation, the rating service screens reviews through it
+	moderationRepo := _moderationRepo.NewModerationRepository(db)
+	moderationService, err := _moderationService.NewModerationService(moderationRepo, ratingRepo, movieRepo, userRepo, transactor, timeout)
+	if err != nil {
+		utils.Logger.Fatal(fmt.Sprintf("failed to create moderation service: %s", err))
+	}
+	_moderationController.NewModerationController(router, moderationService)
 
 	// Rating
-	ratingRepo := _ratingRepo.NewRatingRepository(db)
-	ratingService := _ratingService.NewRatingService(ratingRepo, movieRepo, timeout)
+	ratingVoteRepo := _ratingRepo.NewRatingVoteRepository(db)
+	ratingService := _ratingService.NewRatingService(ratingRepo, movieRepo, userRepo, ratingVoteRepo, moderationService, transactor, timeout)
 	_ratingController.NewRatingController(router, ratingService)
+	if interval, err := time.ParseDuration(configs.Env.Rating.ReconcileInterval); err == nil && interval > 0 {
+		go ratingService.ScheduleReconcile(context.Background(), interval)
+	}
+
+	// Comment
+	commentService := _commentService.NewCommentService(commentRepo, ratingRepo, transactor, timeout)
+	_commentController.NewCommentController(router, commentService)
+
+	// Bulk imports
+	importValidator, err := utils.NewValidator()
+	if err != nil {
+		utils.Logger.Fatal(fmt.Sprintf("failed to register validation: %s", err))
+	}
+	importJobRepo := _importJobRepo.NewImportJobRepository(db)
+	importJobService := _importJobService.NewImportJobService(importJobRepo, genreRepo, movieRepo, userRepo, ratingRepo, provenanceRepo, revisionService, autocompleteService, moderationService, transactor, importValidator, timeout)
+	if err = importJobService.FailInterrupted(context.Background()); err != nil {
+		utils.Logger.Error(fmt.Sprintf("failed to fail the interrupted import jobs: %s", err))
+	}
+	_importJobController.NewImportJobController(router, importJobService)
+
+	// Export snapshots, the exports themselves are served by the movie and rating controllers
+	snapshotStore, err := storage.NewSnapshotStore()
+	if err != nil {
+		utils.Logger.Fatal(fmt.Sprintf("failed to create snapshot store: %s", err))
+	}
+	snapshotService := _exportService.NewSnapshotService(movieService, ratingService, snapshotStore, timeout)
+	if interval, err := time.ParseDuration(configs.Env.Export.Snapshot.Interval); err == nil && interval > 0 {
+		go snapshotService.Schedule(context.Background(), interval)
+	}
+
+	// Trash retention, the trash endpoints are served by the controllers of each resource
+	retentionService := _trashService.NewRetentionService(movieRepo, genreRepo, ratingRepo, userRepo, movieService, genreService, ratingService, userService, timeout)
+	if interval, err := time.ParseDuration(configs.Env.Trash.PurgeInterval); err == nil && interval > 0 && configs.Env.Trash.RetentionDays > 0 {
+		go retentionService.Schedule(context.Background(), time.Duration(configs.Env.Trash.RetentionDays)*24*time.Hour, interval)
+	}
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/database"
+	"go-movie-api/database/seeder"
+	"go-movie-api/domain"
+	_autocompleteRepo "go-movie-api/modules/autocomplete/repository"
+	_autocompleteService "go-movie-api/modules/autocomplete/service"
+	_genreRepo "go-movie-api/modules/genre/repository"
+	_importerRepo "go-movie-api/modules/importer/repository"
+	_importJobRepo "go-movie-api/modules/importjob/repository"
+	_importJobService "go-movie-api/modules/importjob/service"
+	_moderationRepo "go-movie-api/modules/moderation/repository"
+	_moderationService "go-movie-api/modules/moderation/service"
+	_movieRepo "go-movie-api/modules/movie/repository"
+	_ratingRepo "go-movie-api/modules/rating/repository"
+	_revisionRepo "go-movie-api/modules/revision/repository"
+	_revisionService "go-movie-api/modules/revision/service"
+	_userRepo "go-movie-api/modules/user/repository"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"os"
+	"time"
+)
+
+// commands are run instead of the HTTP server when their name is given as the first argument
+var commands = map[string]func(db *gorm.DB, args []string) error{
+	"seed":              seedCommand,
+	"reconcile-ratings": reconcileRatingsCommand,
+	"import":            importCommand,
+}
+
+func runCommand(db *gorm.DB, name string, args []string) error {
+	command, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	return command(db, args)
+}
+
+// seedCommand fills an empty database with deterministic data, or replaces its data with -reset,
+// e.g. `go-movie-api seed -reset -preset load -seed 42`
+func seedCommand(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	seed := flags.Int64("seed", 1, "random seed, the same seed always produces the same data")
+	preset := flags.String("preset", "default", "data set size: fixture, default or load")
+	genres := flags.Int("genres", 0, "number of genres, overrides the preset")
+	movies := flags.Int("movies", 0, "number of movies, overrides the preset")
+	users := flags.Int("users", 0, "number of users, overrides the preset")
+	ratings := flags.Int("ratings", 0, "number of ratings, overrides the preset")
+	batchSize := flags.Int("batch", 0, "rows per insert statement for ratings, overrides the preset")
+	reset := flags.Bool("reset", false, "empty the seeded tables and every table referencing them first, e.g. sessions, lists and comments")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var opts seeder.Options
+	switch *preset {
+	case "fixture":
+		opts = seeder.FixtureOptions(*seed)
+	case "default":
+		opts = seeder.DefaultOptions(*seed)
+	case "load":
+		opts = seeder.LoadTestOptions(*seed)
+	default:
+		return fmt.Errorf("unknown preset %q", *preset)
+	}
+
+	overrides := []struct {
+		value  int
+		target *int
+	}{
+		{*genres, &opts.Genres},
+		{*movies, &opts.Movies},
+		{*users, &opts.Users},
+		{*ratings, &opts.Ratings},
+		{*batchSize, &opts.BatchSize},
+	}
+	for _, override := range overrides {
+		if override.value > 0 {
+			*override.target = override.value
+		}
+	}
+
+	opts.WeightedMinimumVotes = configs.Env.Rating.WeightedMinimumVotes
+	opts.ScaleMin = configs.Env.Rating.ScaleMin
+	opts.ScaleMax = configs.Env.Rating.ScaleMax
+	opts.ScaleStep = configs.Env.Rating.ScaleStep
+	opts.Reset = *reset
+
+	timeout, _ := time.ParseDuration(configs.Env.Context.Timeout)
+	userRepo := _userRepo.NewUserRepository(db)
+	ratingRepo := _ratingRepo.NewRatingRepository(db)
+	movieRepo := _movieRepo.NewMovieRepository(db)
+	moderationService, err := _moderationService.NewModerationService(_moderationRepo.NewModerationRepository(db), ratingRepo, movieRepo, userRepo, database.NewTransactor(db), timeout)
+	if err != nil {
+		return err
+	}
+
+	s := seeder.NewSeeder(
+		db,
+		_genreRepo.NewGenreRepository(db),
+		movieRepo,
+		userRepo,
+		ratingRepo,
+		moderationService,
+	)
+	_, err = s.Run(context.Background(), opts)
+
+	return err
+}
+
+// reconcileRatingsCommand recomputes the rating aggregates of every movie from the ratings table
+func reconcileRatingsCommand(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("reconcile-ratings", flag.ContinueOnError)
+	minimumVotes := flags.Int("minimum-votes", configs.Env.Rating.WeightedMinimumVotes, "prior weight of the Bayesian weighted rating")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	movieRepo := _movieRepo.NewMovieRepository(db)
+	transactor := database.NewTransactor(db)
+
+	return transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		return movieRepo.RefreshRatingStats(ctx, nil, *minimumVotes)
+	})
+}
+
+// importCommand runs a bulk import job in the foreground and prints its row errors,
+// e.g. `go-movie-api import -kind movies -dry-run movies.csv`
+func importCommand(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	kind := flags.String("kind", domain.ImportKindMovies, "records in the file: movies, genres or ratings")
+	format := flags.String("format", "", "csv, json or ndjson, guessed from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "validate every row without inserting any")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import [flags] <file>")
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = domain.ImportFormatOf(path)
+	}
+	if *format == "" {
+		return fmt.Errorf("unknown format of %q, use -format", path)
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	validate, err := utils.NewValidator()
+	if err != nil {
+		return err
+	}
+
+	jobRepo := _importJobRepo.NewImportJobRepository(db)
+	genreRepo := _genreRepo.NewGenreRepository(db)
+	movieRepo := _movieRepo.NewMovieRepository(db)
+	provenanceRepo := _importerRepo.NewProvenanceRepository(db)
+	transactor := database.NewTransactor(db)
+	timeout, _ := time.ParseDuration(configs.Env.Context.Timeout)
+	// suggestions cached by a running API only pick up the imported rows once evicted or restarted
+	autocompleteService := _autocompleteService.NewAutocompleteService(_autocompleteRepo.NewAutocompleteRepository(db), timeout)
+	userRepo := _userRepo.NewUserRepository(db)
+	ratingRepo := _ratingRepo.NewRatingRepository(db)
+	moderationService, err := _moderationService.NewModerationService(_moderationRepo.NewModerationRepository(db), ratingRepo, movieRepo, userRepo, transactor, timeout)
+	if err != nil {
+		return err
+	}
+	service := _importJobService.NewImportJobService(
+		jobRepo,
+		genreRepo,
+		movieRepo,
+		userRepo,
+		ratingRepo,
+		provenanceRepo,
+		_revisionService.NewRevisionService(_revisionRepo.NewRevisionRepository(db), movieRepo, genreRepo, provenanceRepo, autocompleteService, transactor, timeout),
+		autocompleteService,
+		moderationService,
+		transactor,
+		validate,
+		timeout,
+	)
+
+	job, err := service.Run(context.Background(), &domain.ImportJob{Kind: *kind, Format: *format, DryRun: *dryRun}, file)
+	if err != nil {
+		return err
+	}
+
+	for page := 1; ; page++ {
+		rowErrors, pagination, err := service.FetchErrors(context.Background(), job.Uuid, page, 500)
+		if err != nil {
+			return err
+		}
+		for _, rowError := range rowErrors {
+			fmt.Printf("row %d: %s\n", rowError.RowNumber, rowError.Message)
+		}
+		if page >= pagination.PageCount {
+			break
+		}
+	}
+
+	fmt.Printf("import %s %s: %d rows, %d succeeded, %d failed\n", job.Uuid, job.Status, job.TotalRows, job.SucceededRows, job.FailedRows)
+	if job.Status == domain.ImportJobStatusFailed {
+		return fmt.Errorf("import failed: %s", job.Error)
+	}
+
+	return nil
+}
@@ -23,5 +23,83 @@ type Config struct {
 		AccessTokenExpiration  string `koanf:"access_token_expiration"`
 		RefreshTokenExpiration string `koanf:"refresh_token_expiration"`
 	} `koanf:"auth"`
+	Rating struct {
+		WeightedMinimumVotes int     `koanf:"weighted_minimum_votes"`
+		ScaleMin             float32 `koanf:"scale_min"`
+		ScaleMax             float32 `koanf:"scale_max"`
+		ScaleStep            float32 `koanf:"scale_step"`
+		// ReconcileInterval is how often the weighted scores are recomputed against the current mean rating, never when empty
+		ReconcileInterval string `koanf:"reconcile_interval"`
+	} `koanf:"rating"`
+	Moderation struct {
+		ReportThreshold int      `koanf:"report_threshold"`
+		Words           []string `koanf:"words"`
+		Patterns        []string `koanf:"patterns"`
+	} `koanf:"moderation"`
+	Comment struct {
+		MaxDepth int32 `koanf:"max_depth"`
+	} `koanf:"comment"`
+	Storage struct {
+		Driver              string `koanf:"driver"`
+		Private             bool   `koanf:"private"`
+		SignedURLExpiration string `koanf:"signed_url_expiration"`
+		Local               struct {
+			Path       string `koanf:"path"`
+			BaseURL    string `koanf:"base_url"`
+			SigningKey string `koanf:"signing_key"`
+		} `koanf:"local"`
+		S3 struct {
+			Endpoint  string `koanf:"endpoint"`
+			Region    string `koanf:"region"`
+			Bucket    string `koanf:"bucket"`
+			AccessKey string `koanf:"access_key"`
+			SecretKey string `koanf:"secret_key"`
+			PathStyle bool   `koanf:"path_style"`
+			BaseURL   string `koanf:"base_url"`
+		} `koanf:"s3"`
+	} `koanf:"storage"`
+	Image struct {
+		MaxUploadSize int64 `koanf:"max_upload_size"`
+		MaxPixels     int   `koanf:"max_pixels"`
+	} `koanf:"image"`
+	Import struct {
+		// Priority lists the sources from the most to the least trusted, a source only overwrites fields of sources after it
+		Priority []string `koanf:"priority"`
+		Timeout  string   `koanf:"timeout"`
+		TMDb     struct {
+			BaseURL string `koanf:"base_url"`
+			APIKey  string `koanf:"api_key"`
+		} `koanf:"tmdb"`
+		Wikidata struct {
+			BaseURL string `koanf:"base_url"`
+		} `koanf:"wikidata"`
+		Custom struct {
+			BaseURL string `koanf:"base_url"`
+		} `koanf:"custom"`
+		// Bulk bounds the files of bulk import jobs, rows are inserted BatchSize per transaction
+		Bulk struct {
+			MaxFileSize int64 `koanf:"max_file_size"`
+			BatchSize   int   `koanf:"batch_size"`
+		} `koanf:"bulk"`
+	} `koanf:"import"`
+	// Trash purges the rows soft deleted more than RetentionDays ago every PurgeInterval, rows stay when RetentionDays is 0
+	Trash struct {
+		RetentionDays int    `koanf:"retention_days"`
+		PurgeInterval string `koanf:"purge_interval"`
+	} `koanf:"trash"`
+	// ETag makes the writes of movies, genres, ratings and users fail with 428 when they have no If-Match header
+	ETag struct {
+		RequireIfMatch bool `koanf:"require_if_match"`
+	} `koanf:"etag"`
+	Export struct {
+		// Snapshot writes the exports to the blob store under Prefix every Interval, it is off when Interval is empty.
+		// With the local driver they are written under Path, which is never served.
+		Snapshot struct {
+			Interval string `koanf:"interval"`
+			Format   string `koanf:"format"`
+			Prefix   string `koanf:"prefix"`
+			Path     string `koanf:"path"`
+		} `koanf:"snapshot"`
+	} `koanf:"export"`
 	JWTKey string `koanf:"jwt_key"`
 }
@@ -0,0 +1,15 @@
+package database
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolationCode is the Postgres SQLSTATE raised when a unique constraint or index rejects a row
+const uniqueViolationCode = "23505"
+
+// IsUniqueViolation reports whether err was caused by a unique constraint or index
+func IsUniqueViolation(err error) bool {
+	var pgError *pgconn.PgError
+	return errors.As(err, &pgError) && pgError.Code == uniqueViolationCode
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// ratingScaleLimit bounds the top of a rating scale, the rating aggregates of a movie are NUMERIC(6, 3)
+const ratingScaleLimit = 1000
+
+// EnforceRatingScale rebuilds the check of ratings.rating from the rating scale unless it holds that scale already,
+// its comment records the scale. The check is added NOT VALID, so the ratings given on a previous scale stay.
+func EnforceRatingScale(ctx context.Context, db *gorm.DB, scaleMin float32, scaleMax float32) error {
+	if scaleMin < 0 || scaleMax <= scaleMin || scaleMax >= ratingScaleLimit {
+		return fmt.Errorf("the rating scale %g to %g is not within 0 to %d", scaleMin, scaleMax, ratingScaleLimit)
+	}
+
+	low := strconv.FormatFloat(float64(scaleMin), 'f', -1, 32)
+	high := strconv.FormatFloat(float64(scaleMax), 'f', -1, 32)
+	scale := low + ".." + high
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current sql.NullString
+		err := tx.Raw(`SELECT obj_description(oid, 'pg_constraint') FROM pg_constraint
+			WHERE conname = 'ratings_rating_check' AND conrelid = 'ratings'::regclass`).Scan(&current).Error
+		if err != nil {
+			return err
+		}
+		if current.String == scale {
+			return nil
+		}
+
+		// the bounds are formatted numbers, DDL takes no parameters
+		err = tx.Exec(`ALTER TABLE ratings
+			DROP CONSTRAINT IF EXISTS ratings_rating_check,
+			ADD CONSTRAINT ratings_rating_check CHECK ( rating >= ` + low + ` AND rating <= ` + high + ` ) NOT VALID`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec("COMMENT ON CONSTRAINT ratings_rating_check ON ratings IS '" + scale + "'").Error
+	})
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,341 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	_genreRepo "go-movie-api/modules/genre/repository"
+	_moderationRepo "go-movie-api/modules/moderation/repository"
+	_moderationService "go-movie-api/modules/moderation/service"
+	_movieRepo "go-movie-api/modules/movie/repository"
+	_ratingRepo "go-movie-api/modules/rating/repository"
+	_userRepo "go-movie-api/modules/user/repository"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"math/rand"
+	"time"
+)
+
+// DefaultPassword is the plain password of every seeded user
+const DefaultPassword = "password"
+
+// baseTime anchors every generated timestamp so that the same seed always produces the same rows
+var baseTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Options controls the size and randomness of the generated data set
+type Options struct {
+	Seed      int64
+	Genres    int
+	Movies    int
+	Users     int
+	Ratings   int
+	BatchSize int
+	// WeightedMinimumVotes is the prior weight used for the Bayesian weighted rating of every movie
+	WeightedMinimumVotes int
+	// ScaleMin, ScaleMax and ScaleStep are the rating scale, ratings are generated on every step of it
+	ScaleMin  float32
+	ScaleMax  float32
+	ScaleStep float32
+	// Reset empties the seeded tables, and every table referencing them, before seeding. Without it the seeding
+	// refuses to run on a database that has genres, movies or users already.
+	Reset bool
+}
+
+// Dataset holds the rows created by a seeding run. Ratings are streamed in batches and only counted.
+type Dataset struct {
+	Genres  []domain.Genre
+	Movies  []domain.Movie
+	Users   []domain.User
+	Ratings int
+}
+
+// DefaultOptions returns a data set suited for local development and demos
+func DefaultOptions(seed int64) Options {
+	return Options{Seed: seed, Genres: 20, Movies: 1000, Users: 500, Ratings: 20000, BatchSize: 1000}
+}
+
+// FixtureOptions returns a small data set suited for tests
+func FixtureOptions(seed int64) Options {
+	return Options{Seed: seed, Genres: 5, Movies: 20, Users: 10, Ratings: 50, BatchSize: 100}
+}
+
+// LoadTestOptions returns a data set of about one million ratings for load testing
+func LoadTestOptions(seed int64) Options {
+	return Options{Seed: seed, Genres: 30, Movies: 20000, Users: 50000, Ratings: 1000000, BatchSize: 2000}
+}
+
+type Seeder struct {
+	db         *gorm.DB
+	genreRepo  domain.GenreRepository
+	movieRepo  domain.MovieRepository
+	userRepo   domain.UserRepository
+	ratingRepo domain.RatingRepository
+	moderation domain.ModerationService
+}
+
+func NewSeeder(db *gorm.DB, genreRepo domain.GenreRepository, movieRepo domain.MovieRepository, userRepo domain.UserRepository, ratingRepo domain.RatingRepository, moderation domain.ModerationService) *Seeder {
+	return &Seeder{
+		db:         db,
+		genreRepo:  genreRepo,
+		movieRepo:  movieRepo,
+		userRepo:   userRepo,
+		ratingRepo: ratingRepo,
+		moderation: moderation,
+	}
+}
+
+// Fixture replaces the data of the database with a small deterministic data set, stored through the regular
+// repositories on the configured rating scale, to be used as a test fixture
+func Fixture(ctx context.Context, db *gorm.DB, seed int64) (Dataset, error) {
+	genreRepo := _genreRepo.NewGenreRepository(db)
+	movieRepo := _movieRepo.NewMovieRepository(db)
+	userRepo := _userRepo.NewUserRepository(db)
+	ratingRepo := _ratingRepo.NewRatingRepository(db)
+	timeout, _ := time.ParseDuration(configs.Env.Context.Timeout)
+	moderation, err := _moderationService.NewModerationService(_moderationRepo.NewModerationRepository(db), ratingRepo, movieRepo, userRepo, database.NewTransactor(db), timeout)
+	if err != nil {
+		return Dataset{}, err
+	}
+
+	opts := FixtureOptions(seed)
+	opts.WeightedMinimumVotes = configs.Env.Rating.WeightedMinimumVotes
+	opts.ScaleMin = configs.Env.Rating.ScaleMin
+	opts.ScaleMax = configs.Env.Rating.ScaleMax
+	opts.ScaleStep = configs.Env.Rating.ScaleStep
+	opts.Reset = true
+
+	return NewSeeder(db, genreRepo, movieRepo, userRepo, ratingRepo, moderation).Run(ctx, opts)
+}
+
+// Run generates genres, movies, users and ratings from opts.Seed and stores them. With opts.Reset it empties the
+// seeded tables, and those referencing them, first, so that running it again gives the same data set.
+func (seeder *Seeder) Run(ctx context.Context, opts Options) (Dataset, error) {
+	if opts.Genres <= 0 || opts.Movies <= 0 || opts.Users <= 0 {
+		return Dataset{}, fmt.Errorf("genres, movies and users must be positive")
+	}
+	if opts.ScaleMax <= opts.ScaleMin {
+		return Dataset{}, fmt.Errorf("the rating scale %g to %g is empty", opts.ScaleMin, opts.ScaleMax)
+	}
+	if opts.Ratings > opts.Users*opts.Movies {
+		return Dataset{}, fmt.Errorf("cannot generate %d ratings from %d users and %d movies", opts.Ratings, opts.Users, opts.Movies)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	if opts.Reset {
+		if err := database.Truncate(ctx, seeder.db, "genres", "movies", "users", "ratings"); err != nil {
+			return Dataset{}, err
+		}
+	} else {
+		var seeded bool
+		err := seeder.db.WithContext(ctx).
+			Raw("SELECT EXISTS (SELECT 1 FROM genres) OR EXISTS (SELECT 1 FROM movies) OR EXISTS (SELECT 1 FROM users)").
+			Scan(&seeded).Error
+		if err != nil {
+			return Dataset{}, err
+		}
+		if seeded {
+			return Dataset{}, fmt.Errorf("the database has data already, seed with -reset to replace it")
+		}
+	}
+
+	random := rand.New(rand.NewSource(opts.Seed))
+	var dataset Dataset
+	var err error
+
+	if dataset.Genres, err = seeder.seedGenres(ctx, opts.Genres); err != nil {
+		return Dataset{}, err
+	}
+	utils.Logger.Info(fmt.Sprintf("seeded %d genres", len(dataset.Genres)))
+
+	if dataset.Movies, err = seeder.seedMovies(ctx, random, opts.Movies, dataset.Genres); err != nil {
+		return Dataset{}, err
+	}
+	utils.Logger.Info(fmt.Sprintf("seeded %d movies", len(dataset.Movies)))
+
+	if dataset.Users, err = seeder.seedUsers(ctx, random, opts.Users); err != nil {
+		return Dataset{}, err
+	}
+	utils.Logger.Info(fmt.Sprintf("seeded %d users", len(dataset.Users)))
+
+	if dataset.Ratings, err = seeder.seedRatings(ctx, random, opts, dataset.Movies, dataset.Users); err != nil {
+		return Dataset{}, err
+	}
+	utils.Logger.Info(fmt.Sprintf("seeded %d ratings", dataset.Ratings))
+
+	// ratings are inserted in batches, bypassing the rating service, so the aggregates are computed once at the end,
+	// the rules of its Store are kept while generating them
+	if err = seeder.movieRepo.RefreshRatingStats(ctx, nil, opts.WeightedMinimumVotes); err != nil {
+		return Dataset{}, err
+	}
+
+	return dataset, nil
+}
+
+func (seeder *Seeder) seedGenres(ctx context.Context, count int) ([]domain.Genre, error) {
+	genres := make([]domain.Genre, 0, count)
+	for i := 0; i < count; i++ {
+		name := genreNames[i%len(genreNames)]
+		if i >= len(genreNames) {
+			name = fmt.Sprintf("%s %d", name, i/len(genreNames)+1)
+		}
+
+		genre, err := seeder.genreRepo.Store(ctx, &domain.Genre{
+			Name:      name,
+			CreatedAt: baseTime,
+			UpdatedAt: baseTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	return genres, nil
+}
+
+func (seeder *Seeder) seedMovies(ctx context.Context, random *rand.Rand, count int, genres []domain.Genre) ([]domain.Movie, error) {
+	movies := make([]domain.Movie, 0, count)
+	for i := 0; i < count; i++ {
+		title := fmt.Sprintf("%s %s %s", pick(random, titleOpenings), pick(random, titleAdjectives), pick(random, titleNouns))
+		createdAt := baseTime.Add(time.Duration(random.Intn(365*24*4)) * time.Hour)
+
+		movie, err := seeder.movieRepo.Store(ctx, &domain.Movie{
+			Title:     title,
+			Duration:  int32(80 + random.Intn(100)),
+			Year:      int32(1950 + random.Intn(74)),
+			Synopsis:  synopsis(random),
+			Genres:    pickGenres(random, genres),
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, movie)
+	}
+
+	return movies, nil
+}
+
+func (seeder *Seeder) seedUsers(ctx context.Context, random *rand.Rand, count int) ([]domain.User, error) {
+	// Hashing once keeps large runs fast, every seeded user shares DefaultPassword
+	hashedPassword, err := utils.HashPassword(DefaultPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, 0, count)
+	for i := 1; i <= count; i++ {
+		createdAt := baseTime.Add(time.Duration(random.Intn(365*24*4)) * time.Hour)
+
+		user, err := seeder.userRepo.Store(ctx, &domain.User{
+			Username:          fmt.Sprintf("user%06d", i),
+			Email:             fmt.Sprintf("user%06d@example.com", i),
+			FullName:          fmt.Sprintf("%s %s", pick(random, firstNames), pick(random, lastNames)),
+			Password:          hashedPassword,
+			IsAdmin:           i == 1,
+			IsEmailVerified:   true,
+			PasswordChangedAt: createdAt,
+			CreatedAt:         createdAt,
+			UpdatedAt:         createdAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (seeder *Seeder) seedRatings(ctx context.Context, random *rand.Rand, opts Options, movies []domain.Movie, users []domain.User) (int, error) {
+	// every user rates a movie at most once
+	seen := make(map[uint64]struct{}, opts.Ratings)
+	batch := make([]domain.Rating, 0, opts.BatchSize)
+	stored := 0
+
+	for stored+len(batch) < opts.Ratings {
+		movie := movies[random.Intn(len(movies))]
+		user := users[random.Intn(len(users))]
+
+		key := uint64(user.ID)<<32 | uint64(movie.ID)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		createdAt := baseTime.Add(time.Duration(random.Intn(365*24*4)) * time.Hour)
+		comment := ""
+		if random.Intn(3) == 0 {
+			comment = pick(random, comments)
+		}
+
+		rating := domain.Rating{
+			UserID:           user.ID,
+			MovieID:          movie.ID,
+			Rating:           ratingOnScale(random, opts),
+			Comment:          comment,
+			ModerationStatus: domain.ModerationStatusPublished,
+			CreatedAt:        createdAt,
+			UpdatedAt:        createdAt,
+		}
+		if comment != "" && seeder.moderation.Screen(comment) {
+			rating.ModerationStatus = domain.ModerationStatusPendingReview
+		}
+		batch = append(batch, rating)
+
+		if len(batch) == opts.BatchSize {
+			if err := seeder.ratingRepo.StoreBatch(ctx, batch, opts.BatchSize); err != nil {
+				return stored, err
+			}
+			stored += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := seeder.ratingRepo.StoreBatch(ctx, batch, opts.BatchSize); err != nil {
+			return stored, err
+		}
+		stored += len(batch)
+	}
+
+	return stored, nil
+}
+
+// ratingOnScale picks a step of the rating scale, or any value of it when it has no step
+func ratingOnScale(random *rand.Rand, opts Options) float32 {
+	if opts.ScaleStep <= 0 {
+		return opts.ScaleMin + random.Float32()*(opts.ScaleMax-opts.ScaleMin)
+	}
+
+	steps := int((opts.ScaleMax-opts.ScaleMin)/opts.ScaleStep + 1e-6)
+	return opts.ScaleMin + float32(random.Intn(steps+1))*opts.ScaleStep
+}
+
+func pickGenres(random *rand.Rand, genres []domain.Genre) []domain.Genre {
+	count := 1 + random.Intn(3)
+	if count > len(genres) {
+		count = len(genres)
+	}
+
+	picked := make([]domain.Genre, 0, count)
+	for _, index := range random.Perm(len(genres))[:count] {
+		picked = append(picked, genres[index])
+	}
+
+	return picked
+}
+
+func synopsis(random *rand.Rand) string {
+	return fmt.Sprintf("%s %s must %s before %s.",
+		pick(random, protagonists), pick(random, firstNames), pick(random, goals), pick(random, deadlines))
+}
+
+func pick(random *rand.Rand, values []string) string {
+	return values[random.Intn(len(values))]
+}
@@ -0,0 +1,114 @@
+package seeder
+
+import (
+	"context"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestRatingOnScale(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"half stars", Options{ScaleMin: 0.5, ScaleMax: 5, ScaleStep: 0.5}},
+		{"ten points", Options{ScaleMin: 1, ScaleMax: 10, ScaleStep: 1}},
+		{"continuous", Options{ScaleMin: 0, ScaleMax: 100}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			random := rand.New(rand.NewSource(1))
+			seen := make(map[float32]bool)
+
+			for i := 0; i < 1000; i++ {
+				value := ratingOnScale(random, test.opts)
+				if value < test.opts.ScaleMin || value > test.opts.ScaleMax {
+					t.Fatalf("%g is off the scale", value)
+				}
+				if test.opts.ScaleStep > 0 {
+					steps := float64((value - test.opts.ScaleMin) / test.opts.ScaleStep)
+					if math.Abs(steps-math.Round(steps)) > 1e-6 {
+						t.Fatalf("%g is not on a step", value)
+					}
+				}
+				seen[value] = true
+			}
+
+			if test.opts.ScaleStep > 0 && !seen[test.opts.ScaleMax] {
+				t.Errorf("the top of the scale is never picked")
+			}
+		})
+	}
+}
+
+// TestFixture runs against the migrated database of TEST_DATABASE_DSN, whose data it replaces
+func TestFixture(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utils.Logger = zap.NewNop()
+	configs.Env.Context.Timeout = "1m"
+	configs.Env.Rating.ScaleMin = 0.5
+	configs.Env.Rating.ScaleMax = 5
+	configs.Env.Rating.ScaleStep = 0.5
+	configs.Env.Moderation.Words = []string{"rushed"}
+
+	ctx := context.Background()
+	first, err := Fixture(ctx, db, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a second run replaces the data set instead of conflicting with it
+	second, err := Fixture(ctx, db, 7)
+	if err != nil {
+		t.Fatalf("seeding again: %v", err)
+	}
+
+	opts := FixtureOptions(7)
+	if len(second.Genres) != opts.Genres || len(second.Movies) != opts.Movies || len(second.Users) != opts.Users || second.Ratings != opts.Ratings {
+		t.Errorf("dataset has %d genres, %d movies, %d users and %d ratings", len(second.Genres), len(second.Movies), len(second.Users), second.Ratings)
+	}
+	for i := range second.Movies {
+		if second.Movies[i].ID != first.Movies[i].ID || second.Movies[i].Title != first.Movies[i].Title {
+			t.Fatalf("movie %d is %q, it was %q", i, second.Movies[i].Title, first.Movies[i].Title)
+		}
+	}
+
+	var counts struct {
+		Ratings  int64
+		OffScale int64
+		Flagged  int64
+	}
+	db.Raw(`SELECT COUNT(*) AS ratings,
+	               COUNT(*) FILTER (WHERE rating < 0.5 OR rating > 5 OR MOD((rating * 2)::NUMERIC, 1) <> 0) AS off_scale,
+	               COUNT(*) FILTER (WHERE comment LIKE '%rushed%' AND moderation_status <> ?) AS flagged
+	        FROM ratings`, domain.ModerationStatusPendingReview).Scan(&counts)
+
+	if counts.Ratings != int64(opts.Ratings) {
+		t.Errorf("%d ratings are stored, want %d", counts.Ratings, opts.Ratings)
+	}
+	if counts.OffScale != 0 {
+		t.Errorf("%d ratings are off the scale", counts.OffScale)
+	}
+	if counts.Flagged != 0 {
+		t.Errorf("%d ratings matching the moderation words are published", counts.Flagged)
+	}
+}
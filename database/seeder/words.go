@@ -0,0 +1,53 @@
+package seeder
+
+/*
+	Word lists used to build realistic looking seed data.
+*/
+
+var genreNames = []string{
+	"Action", "Adventure", "Animation", "Biography", "Comedy", "Crime", "Documentary", "Drama",
+	"Family", "Fantasy", "Film Noir", "History", "Horror", "Music", "Musical", "Mystery",
+	"Romance", "Sci-Fi", "Sport", "Thriller", "War", "Western",
+}
+
+var titleOpenings = []string{"The", "Return of the", "Beyond the", "Rise of the", "Last", "Night of the", "Secret of the"}
+
+var titleAdjectives = []string{
+	"Silent", "Crimson", "Forgotten", "Broken", "Golden", "Hidden", "Endless", "Frozen",
+	"Burning", "Electric", "Hollow", "Midnight", "Savage", "Velvet", "Wandering", "Iron",
+}
+
+var titleNouns = []string{
+	"Harbor", "Kingdom", "Signal", "Orchard", "Frontier", "Empire", "Witness", "Garden",
+	"Horizon", "Machine", "Heist", "River", "Protocol", "Lighthouse", "Dynasty", "Echo",
+}
+
+var protagonists = []string{"A retired detective", "A young pilot", "An exiled prince", "A small-town mechanic", "A disgraced scientist", "A street musician"}
+
+var goals = []string{
+	"uncover a decades-old conspiracy", "win back a lost family fortune", "stop a rogue artificial intelligence",
+	"lead a crew through an impossible heist", "find a missing sister", "survive a winter alone in the mountains",
+}
+
+var deadlines = []string{"the city falls", "the last train leaves", "the truth is buried forever", "the storm reaches the coast", "time runs out"}
+
+var firstNames = []string{
+	"Alice", "Bima", "Carlos", "Dewi", "Elena", "Farhan", "Grace", "Hiro",
+	"Indra", "Julia", "Kevin", "Lina", "Marco", "Nadia", "Oscar", "Putri",
+}
+
+var lastNames = []string{
+	"Anderson", "Budiman", "Chen", "Dharma", "Evans", "Fernandez", "Gunawan", "Hartono",
+	"Ito", "Johnson", "Kusuma", "Lee", "Martin", "Nguyen", "Oliveira", "Pratama",
+}
+
+var comments = []string{
+	"Loved every minute of it.",
+	"Great performances, but the pacing drags in the middle.",
+	"Not my kind of movie.",
+	"A modern classic, the soundtrack alone is worth it.",
+	"The ending felt rushed.",
+	"Beautifully shot and surprisingly moving.",
+	"Predictable plot, still a fun watch.",
+	"I would happily watch it again.",
+}
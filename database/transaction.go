@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"go-movie-api/domain"
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(gormDB *gorm.DB) domain.Transactor {
+	return &transactor{db: gormDB}
+}
+
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// nested calls join the outer transaction
+	if _, ok := ctx.Value(transactionKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, transactionKey{}, tx))
+	})
+}
+
+// Conn returns the transaction started by a Transactor for ctx, or db when ctx is not inside one
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(transactionKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
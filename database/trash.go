@@ -0,0 +1,18 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Trashed scopes a query to the soft deleted rows, to those deleted before deletedBefore unless it is zero
+func Trashed(deletedBefore time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+		if !deletedBefore.IsZero() {
+			db = db.Where("deleted_at < ?", deletedBefore)
+		}
+
+		return db
+	}
+}
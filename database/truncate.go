@@ -0,0 +1,19 @@
+package database
+
+import (
+	"context"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Truncate empties the tables and every table referencing them, and restarts their ids
+func Truncate(ctx context.Context, db *gorm.DB, tables ...string) error {
+	result := Conn(ctx, db).Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE")
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+const (
+	SuggestionTypeMovie = "movie"
+	SuggestionTypeGenre = "genre"
+)
+
+// Suggestion is a single autocomplete result, Score is higher for better matches
+type Suggestion struct {
+	Type  string    `json:"type"`
+	Uuid  uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Score float32   `json:"score"`
+}
+
+type AutocompleteService interface {
+	Suggest(ctx context.Context, query string, types []string, limit int) ([]Suggestion, error)
+	// Invalidate drops every cached suggestion, it must be called whenever a movie title or genre name changes
+	Invalidate()
+}
+
+type AutocompleteRepository interface {
+	Suggest(ctx context.Context, query string, suggestionType string, limit int) ([]Suggestion, error)
+}
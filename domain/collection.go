@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Collection groups movies of a franchise, like a trilogy, in their order within the collection
+type Collection struct {
+	ID          uint              `gorm:"primarykey" json:"-"`
+	Uuid        uuid.UUID         `json:"id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt    `json:"-" gorm:"index"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Entries     []CollectionMovie `json:"entries,omitempty"`
+
+	// Rating aggregates are computed from the movies of the collection when it is read
+	RatingCount   int32   `json:"rating_count" gorm:"-"`
+	RatingAverage float32 `json:"rating_average" gorm:"-"`
+}
+
+// CollectionMovie is a movie in a collection, movies are ordered by Position starting at 1
+type CollectionMovie struct {
+	CollectionID uint   `json:"-" gorm:"primaryKey"`
+	MovieID      uint   `json:"-" gorm:"primaryKey"`
+	Position     int32  `json:"position"`
+	Movie        *Movie `json:"movie,omitempty"`
+}
+
+type CollectionService interface {
+	FetchPagination(ctx context.Context, page int, perPage int) ([]Collection, utils.Pagination, error)
+	// FindByID returns the collection with its ordered movies and their aggregate rating
+	FindByID(ctx context.Context, uuid uuid.UUID) (Collection, error)
+	Store(ctx context.Context, collection *Collection) (Collection, error)
+	Update(ctx context.Context, collection *Collection) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID) error
+	// ReplaceMovies replaces the movies of the collection, in the order of movieUuids
+	ReplaceMovies(ctx context.Context, uuid uuid.UUID, movieUuids []uuid.UUID) (Collection, error)
+}
+
+type CollectionRepository interface {
+	FetchPagination(ctx context.Context, pagination *utils.Pagination) ([]Collection, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (Collection, error)
+	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (Collection, error)
+	Store(ctx context.Context, collection *Collection) (Collection, error)
+	Update(ctx context.Context, collection *Collection) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID) error
+	ReplaceMovies(ctx context.Context, collectionID uint, entries []CollectionMovie) error
+}
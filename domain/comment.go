@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"time"
+)
+
+// DeletedCommentBody replaces the body of a deleted comment, which stays in its thread so that the replies keep their place
+const DeletedCommentBody = "[deleted]"
+
+// Comment is a comment on a review or a reply to another comment, Depth is 0 for comments on the review itself
+type Comment struct {
+	ID         uint       `gorm:"primarykey" json:"-"`
+	Uuid       uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	RatingID   uint       `json:"-"`
+	UserID     uint       `json:"-"` // 0 once the author is purged
+	ParentID   *uint      `json:"-"`
+	Depth      int32      `json:"depth"`
+	Body       string     `json:"body"`
+	ReplyCount int32      `json:"reply_count" gorm:"->"`
+	User       *User      `json:"user,omitempty"`
+	Rating     *Rating    `json:"-"`
+	Parent     *Comment   `json:"-"`
+}
+
+type CommentService interface {
+	// FetchByRating lists the top level comments of the review, replies are listed with FetchReplies
+	FetchByRating(ctx context.Context, ratingUuid uuid.UUID, page int, perPage int) ([]Comment, utils.Pagination, error)
+	FetchReplies(ctx context.Context, uuid uuid.UUID, page int, perPage int) ([]Comment, utils.Pagination, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (Comment, error)
+	// Store adds the comment to comment.Rating, or replies to comment.Parent when it is set
+	Store(ctx context.Context, comment *Comment) (Comment, error)
+	Update(ctx context.Context, comment *Comment) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID, user *User) error
+}
+
+type CommentRepository interface {
+	FetchByRating(ctx context.Context, ratingID uint, pagination *utils.Pagination) ([]Comment, error)
+	FetchReplies(ctx context.Context, parentID uint, pagination *utils.Pagination) ([]Comment, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (Comment, error)
+	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (Comment, error)
+	Store(ctx context.Context, comment *Comment) (Comment, error)
+	Update(ctx context.Context, comment *Comment) error
+	SoftDelete(ctx context.Context, id uint) error
+	// SoftDeleteByUser marks the comments of the user as deleted and drops their bodies
+	SoftDeleteByUser(ctx context.Context, userID uint) error
+	IncrementReplyCount(ctx context.Context, id uint) error
+}
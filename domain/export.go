@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// MovieExport is a movie as written to an export, with the names of its genres
+type MovieExport struct {
+	Uuid           uuid.UUID
+	Title          string
+	Year           int32
+	Duration       int32
+	Synopsis       string
+	Genres         []string
+	RatingCount    int32
+	RatingAverage  float32
+	RatingWeighted float32
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      *time.Time
+}
+
+// RatingExport is a published rating as written to an export, with the ids of its movie and user
+type RatingExport struct {
+	Uuid           uuid.UUID
+	MovieUuid      uuid.UUID
+	UserUuid       uuid.UUID
+	Rating         float32
+	Comment        string
+	HelpfulCount   int32
+	UnhelpfulCount int32
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// RatingExportFilter narrows a ratings export like the listings, to a movie or a user when their ids are set
+type RatingExportFilter struct {
+	RatingFilter
+	MovieUuid uuid.UUID
+	UserUuid  uuid.UUID
+}
+
+type SnapshotService interface {
+	// Snapshot writes a movies and a ratings export to the blob store and returns their keys
+	Snapshot(ctx context.Context) ([]string, error)
+	// Schedule takes a snapshot every interval until ctx is done
+	Schedule(ctx context.Context, interval time.Duration)
+}
@@ -14,26 +14,48 @@ type Genre struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Version   uint           `json:"version" gorm:"default:1"`
 	Name      string         `json:"name"`
 	Movies    []Movie        `json:"movies,omitempty" gorm:"many2many:movie_genres;" `
+
+	// Translations are preloaded for the locales of the request, Locale is the one Localize picked
+	Translations []GenreTranslation `json:"-"`
+	Locale       string             `json:"locale,omitempty" gorm:"-"`
 }
 
 type GenreService interface {
 	FetchPagination(ctx context.Context, page int, perPage int) ([]Genre, utils.Pagination, error)
+	FetchCursor(ctx context.Context, pagination utils.CursorPagination) ([]Genre, utils.CursorPagination, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (Genre, error)
 	Store(ctx context.Context, genre *Genre) (Genre, error)
-	Update(ctx context.Context, genre *Genre) error
-	SoftDelete(ctx context.Context, uuid uuid.UUID) error
-	Delete(ctx context.Context, uuid2 uuid.UUID) error
+	// Update, SoftDelete and Delete fail with PreconditionFailedErr unless the genre is at version, or version is AnyVersion
+	Update(ctx context.Context, genre *Genre, version uint) error
+	// Patch locks the genre, lets apply change it and saves the fields apply may change, zero values included
+	Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(genre *Genre) error) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// Delete removes the genre for good, from the trash or not, with what hangs off it
+	Delete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// FetchTrashed lists the soft deleted genres, the most recently deleted first
+	FetchTrashed(ctx context.Context, page int, perPage int) ([]Genre, utils.Pagination, error)
+	// Restore brings a soft deleted genre back
+	Restore(ctx context.Context, uuid uuid.UUID) error
 }
 
 type GenreRepository interface {
 	FetchPagination(ctx context.Context, pagination *utils.Pagination) ([]Genre, error)
+	FetchCursor(ctx context.Context, pagination *utils.CursorPagination) ([]Genre, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (Genre, error)
 	FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]Genre, error)
+	// FindByNames returns the genres named like any of the names, ignoring case
+	FindByNames(ctx context.Context, names []string) ([]Genre, error)
 	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (Genre, error)
 	Store(ctx context.Context, genre *Genre) (Genre, error)
 	Update(ctx context.Context, genre *Genre) error
 	SoftDelete(ctx context.Context, uuid uuid.UUID) error
 	Delete(ctx context.Context, uuid uuid.UUID) error
+	// FindByIDWithTrashedForUpdate finds and locks the genre whether it is soft deleted or not
+	FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (Genre, error)
+	// FetchTrashed lists the soft deleted genres, only those deleted before deletedBefore unless it is zero
+	FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]Genre, error)
+	Restore(ctx context.Context, uuid uuid.UUID) error
 }
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"io"
+	"time"
+)
+
+const (
+	ImageKindPoster   = "poster"
+	ImageKindBackdrop = "backdrop"
+	// ImageSizeOriginal is the uploaded image, stored as it was received
+	ImageSizeOriginal = "original"
+)
+
+// ImageSize is a thumbnail width an uploaded image is resized into, the height keeps the aspect ratio
+type ImageSize struct {
+	Name  string
+	Width int
+}
+
+// ImageSizes are the thumbnails generated for each kind of image, from smallest to largest
+var ImageSizes = map[string][]ImageSize{
+	ImageKindPoster:   {{"w92", 92}, {"w185", 185}, {"w342", 342}, {"w500", 500}},
+	ImageKindBackdrop: {{"w300", 300}, {"w780", 780}, {"w1280", 1280}},
+}
+
+// ImageURLs maps each size of an image to the address it is served from
+type ImageURLs map[string]string
+
+// BlobStore keeps uploaded files under slash separated keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL returns the public address of the blob
+	URL(key string) string
+	// SignedURL returns an address of the blob that stops working after expiry, for private stores
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+type ImageService interface {
+	// Upload stores the image and its thumbnails as the poster or backdrop of the movie, replacing the previous one
+	Upload(ctx context.Context, movieUuid uuid.UUID, kind string, body io.Reader) (ImageURLs, error)
+	Delete(ctx context.Context, movieUuid uuid.UUID, kind string) error
+	// Purge removes the files of every image of the movie, once the movie itself is deleted
+	Purge(ctx context.Context, movie Movie)
+	// Resolve fills the poster and backdrop URLs of the movie from its image keys
+	Resolve(movie *Movie)
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	ExternalSourceIMDb     = "imdb"
+	ExternalSourceTMDb     = "tmdb"
+	ExternalSourceWikidata = "wikidata"
+	ExternalSourceCustom   = "custom"
+	// FieldSourceManual is the provenance of a field edited through the API, imports never overwrite it
+	FieldSourceManual = "manual"
+)
+
+// ExternalSources are the catalogs a movie can be identified in
+var ExternalSources = []string{ExternalSourceIMDb, ExternalSourceTMDb, ExternalSourceWikidata, ExternalSourceCustom}
+
+const (
+	MovieFieldTitle    = "title"
+	MovieFieldYear     = "year"
+	MovieFieldDuration = "duration"
+	MovieFieldSynopsis = "synopsis"
+)
+
+// ImportableMovieFields are the movie fields an import can write, each has its own provenance
+var ImportableMovieFields = []string{MovieFieldTitle, MovieFieldYear, MovieFieldDuration, MovieFieldSynopsis}
+
+// ImportableFields lists the importable fields the movie has a value for
+func (movie *Movie) ImportableFields() []string {
+	var fields []string
+	if movie.Title != "" {
+		fields = append(fields, MovieFieldTitle)
+	}
+	if movie.Year != 0 {
+		fields = append(fields, MovieFieldYear)
+	}
+	if movie.Duration != 0 {
+		fields = append(fields, MovieFieldDuration)
+	}
+	if movie.Synopsis != "" {
+		fields = append(fields, MovieFieldSynopsis)
+	}
+
+	return fields
+}
+
+// ChangedFields lists the importable fields whose value differs in other
+func (movie *Movie) ChangedFields(other *Movie) []string {
+	var fields []string
+	if movie.Title != other.Title {
+		fields = append(fields, MovieFieldTitle)
+	}
+	if movie.Year != other.Year {
+		fields = append(fields, MovieFieldYear)
+	}
+	if movie.Duration != other.Duration {
+		fields = append(fields, MovieFieldDuration)
+	}
+	if movie.Synopsis != other.Synopsis {
+		fields = append(fields, MovieFieldSynopsis)
+	}
+
+	return fields
+}
+
+// ExternalID identifies a movie in another catalog, an id is unique per source and a movie has one id per source
+type ExternalID struct {
+	ID         uint      `gorm:"primarykey" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	MovieID    uint      `json:"-"`
+	Source     string    `json:"source"`
+	ExternalID string    `json:"external_id"`
+}
+
+// FieldProvenance records where the current value of a movie field comes from, a source or FieldSourceManual
+type FieldProvenance struct {
+	MovieID   uint      `json:"-" gorm:"primaryKey"`
+	Field     string    `json:"field" gorm:"primaryKey"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ImportedMovie is a movie as read from an external catalog, zero values are fields the catalog does not know
+type ImportedMovie struct {
+	Source     string
+	ExternalID string
+	Title      string
+	Year       int32
+	Duration   int32
+	Synopsis   string
+	// ExternalIDs are the ids of the movie in other sources the catalog links to, used to find duplicates
+	ExternalIDs map[string]string
+}
+
+// ImportResult is the movie after an import, with the fields the import wrote
+type ImportResult struct {
+	Movie   Movie    `json:"movie"`
+	Created bool     `json:"created"`
+	Fields  []string `json:"fields"`
+}
+
+// Importer reads movies from one external catalog
+type Importer interface {
+	// Source is the ExternalSource the importer reads
+	Source() string
+	// Fetch returns the movie with the id in the catalog, NotFoundErr when the catalog does not have it
+	Fetch(ctx context.Context, externalID string) (ImportedMovie, error)
+}
+
+type ImportService interface {
+	// Import fetches the movie from the source and merges it into the movie with a matching external id, or creates one.
+	// A field is only written when it is empty or was last written by a source of the same or a lower priority,
+	// fields edited manually are never overwritten.
+	Import(ctx context.Context, source string, externalID string) (ImportResult, error)
+	FindByExternalID(ctx context.Context, source string, externalID string) (Movie, error)
+	FetchExternalIDs(ctx context.Context, movieUuid uuid.UUID) ([]ExternalID, error)
+	StoreExternalID(ctx context.Context, movieUuid uuid.UUID, externalID *ExternalID) (ExternalID, error)
+	DeleteExternalID(ctx context.Context, movieUuid uuid.UUID, source string) error
+	FetchProvenance(ctx context.Context, movieUuid uuid.UUID) ([]FieldProvenance, error)
+}
+
+type ExternalIDRepository interface {
+	// FindMovieUuid returns the uuid of the movie with the external id, NotFoundErr when none has it and a
+	// ConflictErr linking to its restore when the movie is in the trash
+	FindMovieUuid(ctx context.Context, source string, externalID string) (uuid.UUID, error)
+	// Lock keeps other transactions locking any of the external ids waiting until the transaction ends
+	Lock(ctx context.Context, externalIDs []ExternalID) error
+	FetchByMovie(ctx context.Context, movieID uint) ([]ExternalID, error)
+	Store(ctx context.Context, externalID *ExternalID) (ExternalID, error)
+	// StoreMissing stores the ids that no movie has yet and skips the others, it is meant for the ids an import
+	// links to, the id of the import itself is stored with Store so that a taken one fails
+	StoreMissing(ctx context.Context, externalIDs []ExternalID) error
+	Delete(ctx context.Context, movieID uint, source string) error
+}
+
+type ProvenanceRepository interface {
+	FetchByMovie(ctx context.Context, movieID uint) ([]FieldProvenance, error)
+	// Record sets the source of the fields of the movie
+	Record(ctx context.Context, movieID uint, fields []string, source string) error
+}
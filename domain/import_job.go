@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of records a bulk import job can load
+const (
+	ImportKindMovies  = "movies"
+	ImportKindGenres  = "genres"
+	ImportKindRatings = "ratings"
+)
+
+// Formats of bulk import files, NDJSON holds one JSON object per line
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatJSON   = "json"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// ImportFormatOf guesses the format of a file from its extension, "" when the extension is not known
+func ImportFormatOf(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".json":
+		return ImportFormatJSON
+	case ".ndjson", ".jsonl":
+		return ImportFormatNDJSON
+	}
+
+	return ""
+}
+
+const (
+	ImportJobStatusPending   = "pending"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+)
+
+// ImportJob loads a file of movies, genres or ratings. Rows are validated one by one and the valid ones are inserted
+// in batched transactions, a dry run validates every row without inserting any.
+type ImportJob struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	Uuid      uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// UserID is the admin who uploaded the file, nil for jobs run from the command line
+	UserID *uint  `json:"-"`
+	Kind   string `json:"kind"`
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+	Status string `json:"status" gorm:"default:pending"`
+
+	TotalRows     int32 `json:"total_rows"`
+	ProcessedRows int32 `json:"processed_rows"`
+	// SucceededRows counts the rows inserted, or the rows that would be in a dry run
+	SucceededRows int32 `json:"succeeded_rows"`
+	FailedRows    int32 `json:"failed_rows"`
+	// Error is why a failed job stopped, rows of batches committed before it stay inserted
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// ImportRowError is why a row of an import file was not inserted, RowNumber counts records from 1 without the CSV header
+type ImportRowError struct {
+	ID          uint   `gorm:"primarykey" json:"-"`
+	ImportJobID uint   `json:"-"`
+	RowNumber   int32  `json:"row"`
+	Message     string `json:"message"`
+}
+
+type ImportJobService interface {
+	// Start stores a pending job for the file and runs it in the background
+	Start(ctx context.Context, job *ImportJob, file []byte) (ImportJob, error)
+	// Run stores the job and processes the file before returning, for the command line
+	Run(ctx context.Context, job *ImportJob, file []byte) (ImportJob, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (ImportJob, error)
+	FetchErrors(ctx context.Context, uuid uuid.UUID, page int, perPage int) ([]ImportRowError, utils.Pagination, error)
+	// FailInterrupted fails the jobs a previous process left pending or running, nothing finishes them anymore
+	FailInterrupted(ctx context.Context) error
+}
+
+type ImportJobRepository interface {
+	FindByID(ctx context.Context, uuid uuid.UUID) (ImportJob, error)
+	Store(ctx context.Context, job *ImportJob) (ImportJob, error)
+	// UpdateProgress writes the status, counters, error and timestamps of the job
+	UpdateProgress(ctx context.Context, job *ImportJob) error
+	StoreErrors(ctx context.Context, rowErrors []ImportRowError) error
+	FetchErrors(ctx context.Context, jobID uint, pagination *utils.Pagination) ([]ImportRowError, error)
+	// FailUnfinished fails every pending or running job with the error message and returns how many there were
+	FailUnfinished(ctx context.Context, message string) (int64, error)
+}
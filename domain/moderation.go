@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"time"
+)
+
+const (
+	ModerationStatusPublished     = "published"
+	ModerationStatusPendingReview = "pending_review"
+	ModerationStatusRejected      = "rejected"
+)
+
+const (
+	ModerationActionApprove = "approve"
+	ModerationActionReject  = "reject"
+	ModerationActionBan     = "ban"
+)
+
+// RatingReport is a report of a review by a user, it is resolved by the next moderation decision on the review
+type RatingReport struct {
+	ID         uint       `gorm:"primarykey" json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	RatingID   uint       `json:"-"`
+	UserID     uint       `json:"-"`
+	Reason     string     `json:"reason"`
+	User       *User      `json:"user,omitempty"`
+}
+
+// ModerationDecision records the action an admin took on a review
+type ModerationDecision struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	RatingID    uint      `json:"-"`
+	ModeratorID *uint     `json:"-"` // nil once the moderator is deleted
+	Action      string    `json:"action"`
+	Note        string    `json:"note"`
+	Rating      *Rating   `json:"rating,omitempty"`
+	Moderator   *User     `json:"moderator,omitempty"`
+}
+
+// ModerationQueueItem is a review waiting for a decision together with its open reports
+type ModerationQueueItem struct {
+	Rating  Rating         `json:"rating"`
+	Reports []RatingReport `json:"reports"`
+}
+
+type ModerationService interface {
+	// Screen reports whether the comment matches the configured word list or regex rules
+	Screen(comment string) bool
+	Report(ctx context.Context, ratingUuid uuid.UUID, userID uint, reason string) error
+	FetchQueue(ctx context.Context, page int, perPage int) ([]ModerationQueueItem, utils.Pagination, error)
+	Decide(ctx context.Context, ratingUuid uuid.UUID, moderatorID uint, action string, note string) (ModerationDecision, error)
+}
+
+type ModerationRepository interface {
+	StoreReport(ctx context.Context, report *RatingReport) error
+	CountOpenReports(ctx context.Context, ratingID uint) (int64, error)
+	FetchOpenReports(ctx context.Context, ratingIDs []uint) ([]RatingReport, error)
+	ResolveReports(ctx context.Context, ratingID uint) error
+	StoreDecision(ctx context.Context, decision *ModerationDecision) error
+}
@@ -5,38 +5,129 @@ import (
 	"github.com/google/uuid"
 	"go-movie-api/utils"
 	"gorm.io/gorm"
+	"io"
 	"time"
 )
 
 type Movie struct {
-	ID        uint           `gorm:"primarykey" json:"-"`
-	Uuid      uuid.UUID      `json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-	Title     string         `json:"title"`
-	Duration  int32          `json:"duration"`
-	Year      int32          `json:"year"`
-	Synopsis  string         `json:"synopsis"`
-	Genres    []Genre        `json:"genres,omitempty" gorm:"many2many:movie_genres;"`
-	Ratings   []Rating       `json:"ratings,omitempty"`
+	ID          uint           `gorm:"primarykey" json:"-"`
+	Uuid        uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	Version     uint           `json:"version" gorm:"default:1"`
+	Title       string         `json:"title"`
+	Duration    int32          `json:"duration"`
+	Year        int32          `json:"year"`
+	Synopsis    string         `json:"synopsis"`
+	Genres      []Genre        `json:"genres,omitempty" gorm:"many2many:movie_genres;"`
+	Ratings     []Rating       `json:"ratings,omitempty"`
+	Credits     []Credit       `json:"credits,omitempty"`
+	Collections []Collection   `json:"collections,omitempty" gorm:"many2many:collection_movies;"`
+	Releases    []Release      `json:"releases,omitempty"`
+	ExternalIDs []ExternalID   `json:"external_ids,omitempty"`
+
+	// Rating aggregates are maintained by the rating service and are never written from a movie
+	RatingCount     int32           `json:"rating_count" gorm:"->"`
+	RatingAverage   float32         `json:"rating_average" gorm:"->"`
+	RatingWeighted  float32         `json:"rating_weighted" gorm:"->"`
+	RatingHistogram RatingHistogram `json:"rating_histogram" gorm:"->"`
+
+	// Images are stored under their key prefix, the URLs are resolved by the image service when the movie is read
+	PosterKey   string    `json:"-"`
+	BackdropKey string    `json:"-"`
+	Poster      ImageURLs `json:"poster,omitempty" gorm:"-"`
+	Backdrop    ImageURLs `json:"backdrop,omitempty" gorm:"-"`
+
+	// Translations are preloaded for the locales of the request, Locale is the one Localize picked
+	Translations []MovieTranslation `json:"-"`
+	Locale       string             `json:"locale,omitempty" gorm:"-"`
+}
+
+const (
+	GenreMatchAny = "any"
+	GenreMatchAll = "all"
+)
+
+// MovieSortFields are the fields a movie listing can be sorted by
+var MovieSortFields = []string{
+	"id", "title", "year", "duration", "created_at", "updated_at", "rating_count", "rating_average", "rating_weighted",
+}
+
+// MovieFilter narrows and orders a movie listing, zero values are ignored
+type MovieFilter struct {
+	YearFrom       int32
+	YearTo         int32
+	DurationFrom   int32
+	DurationTo     int32
+	GenreIDs       []uuid.UUID
+	GenreMatch     string
+	MinRating      float32
+	MinRatingCount int32
+	CreatedAfter   time.Time
+	IncludeDeleted bool
+	// ReleaseStatus keeps the released or the upcoming movies, in ReleaseRegion when it is set
+	ReleaseStatus string
+	ReleaseRegion string
+	Sort          []utils.SortField
+}
+
+// MovieSearchResult is a movie matched by a full-text search, ranked and with highlighted snippets
+type MovieSearchResult struct {
+	Movie
+	Rank              float32 `json:"rank"`
+	TitleHighlight    string  `json:"title_highlight"`
+	SynopsisHighlight string  `json:"synopsis_highlight"`
 }
 
 type MovieService interface {
-	FetchPagination(ctx context.Context, page int, perPage int) ([]Movie, utils.Pagination, error)
+	FetchPagination(ctx context.Context, filter MovieFilter, page int, perPage int) ([]Movie, utils.Pagination, error)
+	FetchCursor(ctx context.Context, filter MovieFilter, pagination utils.CursorPagination) ([]Movie, utils.CursorPagination, error)
+	Search(ctx context.Context, query string, page int, perPage int) ([]MovieSearchResult, utils.Pagination, error)
+	// Export streams the movies matching the filter to w in the export format, without holding them in memory
+	Export(ctx context.Context, filter MovieFilter, format string, w io.Writer) error
 	FindByID(ctx context.Context, uuid uuid.UUID) (Movie, error)
 	Store(ctx context.Context, movie *Movie) (Movie, error)
-	Update(ctx context.Context, movie *Movie) error
-	SoftDelete(ctx context.Context, uuid uuid.UUID) error
-	Delete(ctx context.Context, uuid uuid.UUID) error
+	// Update, SoftDelete and Delete fail with PreconditionFailedErr unless the movie is at version, or version is AnyVersion
+	Update(ctx context.Context, movie *Movie, version uint) error
+	// Patch locks the movie, lets apply change it and saves the fields apply may change, zero values included
+	Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(movie *Movie) error) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// Delete removes the movie for good, from the trash or not, with what hangs off it
+	Delete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// FetchTrashed lists the soft deleted movies, the most recently deleted first
+	FetchTrashed(ctx context.Context, page int, perPage int) ([]Movie, utils.Pagination, error)
+	// Restore brings a soft deleted movie back
+	Restore(ctx context.Context, uuid uuid.UUID) error
 }
 
 type MovieRepository interface {
-	FetchPagination(ctx context.Context, pagination *utils.Pagination) ([]Movie, error)
+	FetchPagination(ctx context.Context, filter MovieFilter, pagination *utils.Pagination) ([]Movie, error)
+	FetchCursor(ctx context.Context, filter MovieFilter, pagination *utils.CursorPagination) ([]Movie, error)
+	Search(ctx context.Context, query string, pagination *utils.Pagination) ([]MovieSearchResult, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (Movie, error)
 	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (Movie, error)
+	FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]Movie, error)
+	// Export reads the movies matching the filter from a cursor and calls fn with each, in the order of the filter
+	Export(ctx context.Context, filter MovieFilter, fn func(MovieExport) error) error
+	// UpdateImageKey sets the key prefix of the poster or backdrop of the movie, an empty key removes it
+	UpdateImageKey(ctx context.Context, movieID uint, kind string, key string) error
+	// UpdateFields writes the given columns of the movie, leaving its genres untouched
+	UpdateFields(ctx context.Context, movieID uint, fields map[string]interface{}) error
+	// ReplaceGenres sets the genres of the movie, an empty slice removes them all
+	ReplaceGenres(ctx context.Context, movieID uint, genres []Genre) error
 	Store(ctx context.Context, movie *Movie) (Movie, error)
 	Update(ctx context.Context, movie *Movie) error
 	SoftDelete(ctx context.Context, uuid uuid.UUID) error
 	Delete(ctx context.Context, uuid uuid.UUID) error
+	// FindByIDWithTrashedForUpdate finds and locks the movie whether it is soft deleted or not
+	FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (Movie, error)
+	// FetchTrashed lists the soft deleted movies, only those deleted before deletedBefore unless it is zero
+	FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]Movie, error)
+	Restore(ctx context.Context, uuid uuid.UUID) error
+	// RefreshRatingStats recomputes the rating aggregates of the given movies, or of every movie when movieIDs is nil.
+	// The weighted scores of the other movies drift from the mean rating it changes until RefreshWeightedRatings runs.
+	RefreshRatingStats(ctx context.Context, movieIDs []uint, minimumVotes int) error
+	// RefreshWeightedRatings recomputes the weighted score of every movie from the current mean rating
+	RefreshWeightedRatings(ctx context.Context, minimumVotes int) error
 }
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	// ListVisibilityPrivate lists are only visible to their owner
+	ListVisibilityPrivate = "private"
+	// ListVisibilityUnlisted lists are visible to anyone with the share slug
+	ListVisibilityUnlisted = "unlisted"
+	ListVisibilityPublic   = "public"
+)
+
+// MovieList is a named, ordered list of movies curated by a user and shared through its slug
+type MovieList struct {
+	ID            uint             `gorm:"primarykey" json:"-"`
+	Uuid          uuid.UUID        `json:"id"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt   `json:"-" gorm:"index"`
+	UserID        uint             `json:"-"`
+	Title         string           `json:"title"`
+	Description   string           `json:"description"`
+	Visibility    string           `json:"visibility"`
+	Slug          string           `json:"slug"`
+	FollowerCount int32            `json:"follower_count" gorm:"->"`
+	User          *User            `json:"user,omitempty"`
+	Entries       []MovieListEntry `json:"entries,omitempty"`
+}
+
+// MovieListEntry is a movie on a list, entries are ordered by Position starting at 1
+type MovieListEntry struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	MovieListID uint      `json:"-"`
+	MovieID     uint      `json:"-"`
+	Position    int32     `json:"position"`
+	Note        string    `json:"note"`
+	Movie       *Movie    `json:"movie,omitempty"`
+}
+
+// MovieListService changes a list only on behalf of its owner, every change takes the id of the acting user
+type MovieListService interface {
+	FetchByOwner(ctx context.Context, userID uint, page int, perPage int) ([]MovieList, utils.Pagination, error)
+	// FindBySlug returns a public or unlisted list, private lists are only returned to their owner
+	FindBySlug(ctx context.Context, slug string, viewer *User) (MovieList, error)
+	FindByID(ctx context.Context, uuid uuid.UUID, userID uint) (MovieList, error)
+	Store(ctx context.Context, list *MovieList) (MovieList, error)
+	Update(ctx context.Context, list *MovieList, userID uint) error
+	Delete(ctx context.Context, uuid uuid.UUID, userID uint) error
+	AddEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID, note string) (MovieListEntry, error)
+	UpdateEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID, note string) error
+	RemoveEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID) error
+	// ReorderEntries moves the entries into the order of movieUuids, which must list every movie of the list once
+	ReorderEntries(ctx context.Context, uuid uuid.UUID, userID uint, movieUuids []uuid.UUID) error
+	Follow(ctx context.Context, slug string, user *User) error
+	Unfollow(ctx context.Context, slug string, user *User) error
+}
+
+type MovieListRepository interface {
+	FetchByUser(ctx context.Context, userID uint, pagination *utils.Pagination) ([]MovieList, error)
+	FindBySlug(ctx context.Context, slug string) (MovieList, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (MovieList, error)
+	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (MovieList, error)
+	Store(ctx context.Context, list *MovieList) (MovieList, error)
+	// Update saves the title, description and visibility of the list, an empty description included
+	Update(ctx context.Context, list *MovieList) error
+	SoftDelete(ctx context.Context, id uint) error
+
+	// FetchEntryMovieIDs maps the uuid of every movie on the list to its id
+	FetchEntryMovieIDs(ctx context.Context, listID uint) (map[uuid.UUID]uint, error)
+	// NextPosition returns the position after the last entry, the list must be locked with FindByIDForUpdate first
+	NextPosition(ctx context.Context, listID uint) (int32, error)
+	StoreEntry(ctx context.Context, entry *MovieListEntry) (MovieListEntry, error)
+	UpdateEntryNote(ctx context.Context, listID uint, movieID uint, note string) error
+	UpdateEntryPosition(ctx context.Context, listID uint, movieID uint, position int32) error
+	DeleteEntry(ctx context.Context, listID uint, movieID uint) error
+
+	StoreFollower(ctx context.Context, listID uint, userID uint) error
+	DeleteFollower(ctx context.Context, listID uint, userID uint) error
+	// RefreshFollowerCount recomputes the follower count of the list from its followers
+	RefreshFollowerCount(ctx context.Context, listID uint) error
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	CreditRoleDirector = "director"
+	CreditRoleWriter   = "writer"
+	CreditRoleActor    = "actor"
+)
+
+type Person struct {
+	ID        uint           `gorm:"primarykey" json:"-"`
+	Uuid      uuid.UUID      `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Name      string         `json:"name"`
+	BirthDate *time.Time     `json:"birth_date,omitempty"`
+	Bio       string         `json:"bio"`
+	Credits   []Credit       `json:"credits,omitempty"`
+}
+
+// Credit links a person to a movie in a role, like movie_genres with the role, character and billing order on the link
+type Credit struct {
+	ID            uint    `gorm:"primarykey" json:"-"`
+	MovieID       uint    `json:"-"`
+	PersonID      uint    `json:"-"`
+	Role          string  `json:"role"`
+	CharacterName string  `json:"character_name,omitempty"`
+	BillingOrder  int32   `json:"billing_order"`
+	Movie         *Movie  `json:"movie,omitempty"`
+	Person        *Person `json:"person,omitempty"`
+}
+
+type PersonService interface {
+	// FetchPagination lists people ordered by name, narrowed to names matching query when it is not empty
+	FetchPagination(ctx context.Context, query string, page int, perPage int) ([]Person, utils.Pagination, error)
+	// FindByID returns the person with the filmography, newest movies first
+	FindByID(ctx context.Context, uuid uuid.UUID) (Person, error)
+	Store(ctx context.Context, person *Person) (Person, error)
+	Update(ctx context.Context, person *Person) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID) error
+	// ReplaceCredits replaces every credit of the movie, each credit refers to its person through Person.Uuid
+	ReplaceCredits(ctx context.Context, movieUuid uuid.UUID, credits []Credit) ([]Credit, error)
+}
+
+type PersonRepository interface {
+	FetchPagination(ctx context.Context, query string, pagination *utils.Pagination) ([]Person, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (Person, error)
+	FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]Person, error)
+	Store(ctx context.Context, person *Person) (Person, error)
+	Update(ctx context.Context, person *Person) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID) error
+	ReplaceCredits(ctx context.Context, movieID uint, credits []Credit) error
+}
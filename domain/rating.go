@@ -3,7 +3,9 @@ package domain
 import (
 	"context"
 	"github.com/google/uuid"
+	"go-movie-api/utils"
 	"gorm.io/gorm"
+	"io"
 	"time"
 )
 
@@ -13,27 +15,98 @@ type Rating struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Version   uint           `json:"version" gorm:"default:1"`
 	UserID    uint           `json:"-"`
 	MovieID   uint           `json:"-"`
 	Rating    float32        `json:"rating"`
 	Comment   string         `json:"comment"`
 	Movie     *Movie         `json:"movie,omitempty"`
 	User      *User          `json:"user,omitempty"`
+
+	// ModerationStatus hides the review from public listings unless it is published
+	ModerationStatus string `json:"moderation_status" gorm:"default:published"`
+
+	// Vote counts are maintained from the helpfulness votes and are never written from a rating
+	HelpfulCount   int32 `json:"helpful_count" gorm:"->"`
+	UnhelpfulCount int32 `json:"unhelpful_count" gorm:"->"`
+	// HelpfulScore is the helpful count less the unhelpful count, generated by the database
+	HelpfulScore int32 `json:"-" gorm:"->"`
+}
+
+const (
+	RatingSortNewest  = "newest"
+	RatingSortHighest = "highest"
+	RatingSortLowest  = "lowest"
+	RatingSortHelpful = "helpful"
+)
+
+// RatingFilter narrows and orders a ratings listing
+type RatingFilter struct {
+	Sort        string
+	WithComment bool
 }
 
 type RatingService interface {
-	FindByID(ctx context.Context, uuid uuid.UUID) (Rating, error)
+	FetchByMovie(ctx context.Context, movieUuid uuid.UUID, filter RatingFilter, page int, perPage int) ([]Rating, utils.Pagination, error)
+	FetchByUser(ctx context.Context, userUuid uuid.UUID, filter RatingFilter, page int, perPage int) ([]Rating, utils.Pagination, error)
+	FetchByMovieCursor(ctx context.Context, movieUuid uuid.UUID, filter RatingFilter, pagination utils.CursorPagination) ([]Rating, utils.CursorPagination, error)
+	FetchByUserCursor(ctx context.Context, userUuid uuid.UUID, filter RatingFilter, pagination utils.CursorPagination) ([]Rating, utils.CursorPagination, error)
+	// Export streams the published ratings matching the filter to w in the export format, without holding them in memory
+	Export(ctx context.Context, filter RatingExportFilter, format string, w io.Writer) error
+	// FindByID hides a review that is not published from viewers other than its author and admins, viewer is nil
+	// for anonymous requests
+	FindByID(ctx context.Context, uuid uuid.UUID, viewer *User) (Rating, error)
 	Store(ctx context.Context, rating *Rating) (Rating, error)
-	Update(ctx context.Context, rating *Rating) error
-	SoftDelete(ctx context.Context, uuid uuid.UUID) error
-	Delete(ctx context.Context, uuid2 uuid.UUID) error
+	// Upsert creates or replaces the rating of rating.UserID for rating.Movie, created reports which one happened
+	Upsert(ctx context.Context, rating *Rating) (result Rating, created bool, err error)
+	// Update, SoftDelete and Delete fail with PreconditionFailedErr unless the rating is at version, or version is AnyVersion
+	Update(ctx context.Context, rating *Rating, version uint) error
+	// Patch locks the rating, lets apply change it and saves the fields apply may change, zero values included
+	Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(rating *Rating) error) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// Delete removes the rating for good, from the trash or not, with what hangs off it
+	Delete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// FetchTrashed lists the soft deleted ratings, the most recently deleted first
+	FetchTrashed(ctx context.Context, page int, perPage int) ([]Rating, utils.Pagination, error)
+	// Restore brings a soft deleted rating back
+	Restore(ctx context.Context, uuid uuid.UUID) error
+	// Vote records whether userID finds the rating helpful, replacing a previous vote, and returns the rating with its new counts.
+	// Votes on a review that is not published fail with NotFoundErr, as do their retractions.
+	Vote(ctx context.Context, uuid uuid.UUID, userID uint, helpful bool) (Rating, error)
+	RetractVote(ctx context.Context, uuid uuid.UUID, userID uint) (Rating, error)
+	// ScheduleReconcile recomputes the weighted score of every movie every interval until ctx is done, a rating only
+	// refreshes its own movie while it moves the mean rating the scores of the others are weighted against
+	ScheduleReconcile(ctx context.Context, interval time.Duration)
 }
 
 type RatingRepository interface {
+	FetchByMovie(ctx context.Context, movieID uint, filter RatingFilter, pagination *utils.Pagination) ([]Rating, error)
+	FetchByUser(ctx context.Context, userID uint, filter RatingFilter, pagination *utils.Pagination) ([]Rating, error)
+	FetchByMovieCursor(ctx context.Context, movieID uint, filter RatingFilter, pagination *utils.CursorPagination) ([]Rating, error)
+	FetchByUserCursor(ctx context.Context, userID uint, filter RatingFilter, pagination *utils.CursorPagination) ([]Rating, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (Rating, error)
 	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (Rating, error)
+	FindByUserAndMovie(ctx context.Context, userID uint, movieID uint) (Rating, error)
+	// Export reads the published ratings matching the filter from a cursor and calls fn with each
+	Export(ctx context.Context, filter RatingExportFilter, fn func(RatingExport) error) error
 	Store(ctx context.Context, rating *Rating) (Rating, error)
+	// Replace overwrites the rating, comment and moderation status, including an empty comment
+	Replace(ctx context.Context, rating *Rating) error
+	StoreBatch(ctx context.Context, ratings []Rating, batchSize int) error
 	Update(ctx context.Context, rating *Rating) error
 	SoftDelete(ctx context.Context, uuid uuid.UUID) error
 	Delete(ctx context.Context, uuid uuid.UUID) error
+	// FindByIDWithTrashedForUpdate finds and locks the rating whether it is soft deleted or not
+	FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (Rating, error)
+	// FetchTrashed lists the soft deleted ratings, only those deleted before deletedBefore unless it is zero
+	FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]Rating, error)
+	// Restore brings the rating back, unless its movie or user is in the trash too
+	Restore(ctx context.Context, uuid uuid.UUID) error
+	// FetchMovieIDsByUser returns the movies the user has a live rating of, whose aggregates change when the user goes
+	FetchMovieIDsByUser(ctx context.Context, userID uint) ([]uint, error)
+	// FetchByModerationStatus lists the ratings in the status, oldest first
+	FetchByModerationStatus(ctx context.Context, status string, pagination *utils.Pagination) ([]Rating, error)
+	UpdateModerationStatus(ctx context.Context, ratingID uint, status string) error
+	// RefreshVoteCounts recomputes the helpful and unhelpful counts of the rating from its votes
+	RefreshVoteCounts(ctx context.Context, ratingID uint) error
 }
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// RatingHistogram counts ratings per 0.5 step, keyed by the step formatted with one decimal, e.g. "3.5"
+type RatingHistogram map[string]int
+
+func (histogram *RatingHistogram) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*histogram = RatingHistogram{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RatingHistogram", value)
+	}
+
+	return json.Unmarshal(data, histogram)
+}
+
+func (histogram RatingHistogram) Value() (driver.Value, error) {
+	if histogram == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(histogram)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
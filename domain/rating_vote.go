@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RatingVote is the vote of a user on whether a rating's review is helpful, a user votes once per rating
+type RatingVote struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	RatingID  uint      `json:"-"`
+	UserID    uint      `json:"-"`
+	Helpful   bool      `json:"helpful"`
+}
+
+type RatingVoteRepository interface {
+	// Upsert stores the vote, replacing the previous vote of the user on the rating
+	Upsert(ctx context.Context, vote *RatingVote) error
+	Delete(ctx context.Context, ratingID uint, userID uint) error
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	ReleaseTypeTheatrical = "theatrical"
+	ReleaseTypeDigital    = "digital"
+	ReleaseTypePhysical   = "physical"
+)
+
+const (
+	// ReleaseStatusReleased movies have been released in the region
+	ReleaseStatusReleased = "released"
+	// ReleaseStatusUpcoming movies have a release scheduled in the region and none past yet
+	ReleaseStatusUpcoming = "upcoming"
+)
+
+// Release is the date a movie comes out in a country in one format, with its local certification such as PG-13
+type Release struct {
+	ID            uint      `gorm:"primarykey" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	MovieID       uint      `json:"-"`
+	Country       string    `json:"country"`
+	Type          string    `json:"type"`
+	ReleaseDate   time.Time `json:"release_date"`
+	Certification string    `json:"certification,omitempty"`
+	Movie         *Movie    `json:"movie,omitempty"`
+}
+
+// ReleaseCalendarFilter selects the releases between From and To, both inclusive, zero values of Country and Type match all
+type ReleaseCalendarFilter struct {
+	Country string
+	Type    string
+	From    time.Time
+	To      time.Time
+}
+
+type ReleaseService interface {
+	FetchByMovie(ctx context.Context, movieUuid uuid.UUID) ([]Release, error)
+	// ReplaceByMovie replaces every release of the movie, a country has at most one release of each type
+	ReplaceByMovie(ctx context.Context, movieUuid uuid.UUID, releases []Release) ([]Release, error)
+	// Calendar lists the releases of the filter in date order, with their movie
+	Calendar(ctx context.Context, filter ReleaseCalendarFilter) ([]Release, error)
+}
+
+type ReleaseRepository interface {
+	FetchByMovie(ctx context.Context, movieID uint) ([]Release, error)
+	ReplaceByMovie(ctx context.Context, movieID uint, releases []Release) error
+	FetchCalendar(ctx context.Context, filter ReleaseCalendarFilter) ([]Release, error)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"time"
+)
+
+// Revisions are kept for the subjects below, a movie revision includes its genre set
+const (
+	RevisionSubjectMovie = "movie"
+	RevisionSubjectGenre = "genre"
+)
+
+// RevisionActions tell which change a revision recorded
+const (
+	RevisionActionCreated  = "created"
+	RevisionActionUpdated  = "updated"
+	RevisionActionImported = "imported"
+	RevisionActionRestored = "restored"
+)
+
+// Revision is the state of a movie or genre after a change, versions count from 1 for every subject
+type Revision struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	MovieID   *uint     `json:"-"`
+	GenreID   *uint     `json:"-"`
+	Version   int       `json:"version"`
+	// UserID is the author, nil for changes made by commands or once the author is deleted
+	UserID       *uint            `json:"-"`
+	User         *User            `json:"author,omitempty"`
+	Action       string           `json:"action"`
+	RestoredFrom *int             `json:"restored_from,omitempty"`
+	Snapshot     RevisionSnapshot `json:"snapshot"`
+}
+
+// RevisionChange is a field that differs between two revisions
+type RevisionChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// RevisionSnapshot holds the fields of the subject as JSON values, e.g. the title, year and genres of a movie
+type RevisionSnapshot map[string]interface{}
+
+func (snapshot *RevisionSnapshot) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*snapshot = RevisionSnapshot{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RevisionSnapshot", value)
+	}
+
+	return json.Unmarshal(data, snapshot)
+}
+
+func (snapshot RevisionSnapshot) Value() (driver.Value, error) {
+	if snapshot == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Decode fills v, a struct with the json tags of the snapshot fields, from the snapshot
+func (snapshot RevisionSnapshot) Decode(v interface{}) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+type RevisionService interface {
+	// Record stores the current state of the subject as its next revision, inside the transaction of the change
+	Record(ctx context.Context, subject string, subjectID uint, action string) error
+	// Fetch lists the revisions of the subject, the newest first
+	Fetch(ctx context.Context, subject string, uuid uuid.UUID, page int, perPage int) ([]Revision, utils.Pagination, error)
+	FindByVersion(ctx context.Context, subject string, uuid uuid.UUID, version int) (Revision, error)
+	// Diff lists the fields that changed from one version of the subject to another
+	Diff(ctx context.Context, subject string, uuid uuid.UUID, from int, to int) ([]RevisionChange, error)
+	// Restore writes the fields of a version back to the subject and records that as a new revision
+	Restore(ctx context.Context, subject string, uuid uuid.UUID, version int) (Revision, error)
+}
+
+type RevisionRepository interface {
+	// Store snapshots the subject the revision points to and gives the revision the next version of the subject
+	Store(ctx context.Context, revision *Revision) error
+	Fetch(ctx context.Context, subject string, subjectID uint, pagination *utils.Pagination) ([]Revision, error)
+	FindByVersion(ctx context.Context, subject string, subjectID uint, version int) (Revision, error)
+}
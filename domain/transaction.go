@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs fn inside a database transaction. Repositories called with the ctx passed to fn
+// take part in the transaction, which is rolled back when fn returns an error.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// MovieTranslation is the title and synopsis of a movie in a locale
+type MovieTranslation struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	MovieID   uint      `json:"-"`
+	Locale    string    `json:"locale"`
+	Title     string    `json:"title"`
+	Synopsis  string    `json:"synopsis"`
+}
+
+// GenreTranslation is the name of a genre in a locale
+type GenreTranslation struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	GenreID   uint      `json:"-"`
+	Locale    string    `json:"locale"`
+	Name      string    `json:"name"`
+}
+
+// Localize replaces the title and synopsis with the first translation found along the locales fallback chain,
+// the genres of the movie are localized too. Without a matching translation the original text is kept.
+func (movie *Movie) Localize(locales []string) {
+	for i := range movie.Genres {
+		movie.Genres[i].Localize(locales)
+	}
+
+	for _, locale := range locales {
+		for _, translation := range movie.Translations {
+			if translation.Locale == locale {
+				movie.Title = translation.Title
+				movie.Synopsis = translation.Synopsis
+				movie.Locale = locale
+				return
+			}
+		}
+	}
+}
+
+// Localize replaces the name with the first translation found along the locales fallback chain
+func (genre *Genre) Localize(locales []string) {
+	for _, locale := range locales {
+		for _, translation := range genre.Translations {
+			if translation.Locale == locale {
+				genre.Name = translation.Name
+				genre.Locale = locale
+				return
+			}
+		}
+	}
+}
+
+// TranslationService manages the translations of movies and genres, a locale has a single translation per movie or genre
+type TranslationService interface {
+	FetchByMovie(ctx context.Context, movieUuid uuid.UUID) ([]MovieTranslation, error)
+	// PutMovie creates or replaces the translation of the movie in translation.Locale
+	PutMovie(ctx context.Context, movieUuid uuid.UUID, translation *MovieTranslation) (MovieTranslation, error)
+	DeleteMovie(ctx context.Context, movieUuid uuid.UUID, locale string) error
+	FetchByGenre(ctx context.Context, genreUuid uuid.UUID) ([]GenreTranslation, error)
+	// PutGenre creates or replaces the translation of the genre in translation.Locale
+	PutGenre(ctx context.Context, genreUuid uuid.UUID, translation *GenreTranslation) (GenreTranslation, error)
+	DeleteGenre(ctx context.Context, genreUuid uuid.UUID, locale string) error
+}
+
+type TranslationRepository interface {
+	FetchByMovie(ctx context.Context, movieID uint) ([]MovieTranslation, error)
+	UpsertMovie(ctx context.Context, translation *MovieTranslation) (MovieTranslation, error)
+	DeleteMovie(ctx context.Context, movieID uint, locale string) error
+	FetchByGenre(ctx context.Context, genreID uint) ([]GenreTranslation, error)
+	UpsertGenre(ctx context.Context, translation *GenreTranslation) (GenreTranslation, error)
+	DeleteGenre(ctx context.Context, genreID uint, locale string) error
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RetentionService empties the trash of movies, genres, ratings and users.
+//
+// Soft deleting a row leaves what hangs off it in place, so that a restored row comes back as it was, except
+// for the watchlist entries of a movie which go with it. Purging a row removes, with the row, the genre links
+// of a movie or genre, the ratings of a movie or user with their votes, reports and comments, and the
+// sessions and reports of a user. The comments of a user stay deleted in their threads without their
+// author, like moderation decisions outlive their moderator.
+type RetentionService interface {
+	// Purge deletes the rows soft deleted before deletedBefore for good and returns how many went, a row that fails
+	// to be deleted is logged and skipped
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+	// Schedule purges the rows soft deleted longer than retention ago every interval until ctx is done
+	Schedule(ctx context.Context, retention time.Duration, interval time.Duration)
+}
@@ -14,6 +14,7 @@ type User struct {
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+	Version           uint           `json:"version" gorm:"default:1"`
 	Username          string         `json:"username"`
 	Email             string         `json:"email"`
 	FullName          string         `json:"full_name"`
@@ -21,15 +22,25 @@ type User struct {
 	IsAdmin           bool           `json:"is_admin"`
 	IsEmailVerified   bool           `json:"is_email_verified"`
 	PasswordChangedAt time.Time      `json:"password_changed_at"`
+	BannedAt          *time.Time     `json:"banned_at,omitempty"`
 }
 
 type UserService interface {
 	FetchPagination(ctx context.Context, page int, perPage int) ([]User, utils.Pagination, error)
+	FetchCursor(ctx context.Context, pagination utils.CursorPagination) ([]User, utils.CursorPagination, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (User, error)
 	Store(ctx context.Context, user *User) (User, error)
-	Update(ctx context.Context, user *User) error
-	SoftDelete(ctx context.Context, uuid uuid.UUID) error
-	Delete(ctx context.Context, uuid2 uuid.UUID) error
+	// Update, SoftDelete and Delete fail with PreconditionFailedErr unless the user is at version, or version is AnyVersion
+	Update(ctx context.Context, user *User, version uint) error
+	// Patch locks the user, lets apply change it and saves the fields apply may change, zero values included
+	Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(user *User) error) error
+	SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// Delete removes the user for good, from the trash or not, with what hangs off it
+	Delete(ctx context.Context, uuid uuid.UUID, version uint) error
+	// FetchTrashed lists the soft deleted users, the most recently deleted first
+	FetchTrashed(ctx context.Context, page int, perPage int) ([]User, utils.Pagination, error)
+	// Restore brings a soft deleted user back
+	Restore(ctx context.Context, uuid uuid.UUID) error
 }
 
 type UserRepository interface {
@@ -38,9 +49,17 @@ type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (User, error)
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FetchPagination(ctx context.Context, pagination *utils.Pagination) ([]User, error)
+	FetchCursor(ctx context.Context, pagination *utils.CursorPagination) ([]User, error)
 	FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (User, error)
 	Store(ctx context.Context, user *User) (User, error)
 	Update(ctx context.Context, user *User) error
+	// Ban keeps the user from authenticating until the ban is lifted
+	Ban(ctx context.Context, userID uint) error
 	SoftDelete(ctx context.Context, uuid uuid.UUID) error
 	Delete(ctx context.Context, uuid uuid.UUID) error
+	// FindByIDWithTrashedForUpdate finds and locks the user whether it is soft deleted or not
+	FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (User, error)
+	// FetchTrashed lists the soft deleted users, only those deleted before deletedBefore unless it is zero
+	FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]User, error)
+	Restore(ctx context.Context, uuid uuid.UUID) error
 }
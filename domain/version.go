@@ -0,0 +1,8 @@
+package domain
+
+// AnyVersion is the version a write expects when it has no If-Match precondition, it matches every version.
+//
+// Movies, genres, ratings and users count their updates in a version, a write expecting another version than
+// the one of the locked row fails with PreconditionFailedErr. Services write the next version along with the
+// change, under the lock of the row.
+const AnyVersion uint = 0
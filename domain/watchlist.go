@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"time"
+)
+
+// WatchlistEntry is a movie a user saved for later, entries are ordered by Position starting at 1
+type WatchlistEntry struct {
+	ID        uint       `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	UserID    uint       `json:"-"`
+	MovieID   uint       `json:"-"`
+	Position  int32      `json:"position"`
+	WatchedAt *time.Time `json:"watched_at"`
+	Movie     *Movie     `json:"movie,omitempty"`
+}
+
+// WatchlistFilter narrows a watchlist, zero values are ignored
+type WatchlistFilter struct {
+	GenreID uuid.UUID
+	Watched *bool
+}
+
+type WatchlistService interface {
+	FetchPagination(ctx context.Context, userID uint, filter WatchlistFilter, page int, perPage int) ([]WatchlistEntry, utils.Pagination, error)
+	// Store appends the movie to the end of the watchlist
+	Store(ctx context.Context, userID uint, movieUuid uuid.UUID) (WatchlistEntry, error)
+	// MarkWatched sets or, with a nil watchedAt, clears the watched date of the movie
+	MarkWatched(ctx context.Context, userID uint, movieUuid uuid.UUID, watchedAt *time.Time) (WatchlistEntry, error)
+	// Reorder moves the entries into the order of movieUuids, which must list every movie of the watchlist once
+	Reorder(ctx context.Context, userID uint, movieUuids []uuid.UUID) error
+	Delete(ctx context.Context, userID uint, movieUuid uuid.UUID) error
+}
+
+type WatchlistRepository interface {
+	FetchPagination(ctx context.Context, userID uint, filter WatchlistFilter, pagination *utils.Pagination) ([]WatchlistEntry, error)
+	FindByUserAndMovie(ctx context.Context, userID uint, movieID uint) (WatchlistEntry, error)
+	// FetchMovieIDs maps the uuid of every movie on the watchlist to its id
+	FetchMovieIDs(ctx context.Context, userID uint) (map[uuid.UUID]uint, error)
+	// Lock locks the watchlist of the user, through the row of the user, until the transaction ends
+	Lock(ctx context.Context, userID uint) error
+	// NextPosition returns the position after the last entry, the watchlist must be locked to hand it out only once
+	NextPosition(ctx context.Context, userID uint) (int32, error)
+	Store(ctx context.Context, entry *WatchlistEntry) (WatchlistEntry, error)
+	UpdateWatchedAt(ctx context.Context, entry *WatchlistEntry) error
+	UpdatePosition(ctx context.Context, userID uint, movieID uint, position int32) error
+	Delete(ctx context.Context, userID uint, movieID uint) error
+	// DeleteByMovie removes the movie from every watchlist
+	DeleteByMovie(ctx context.Context, movieID uint) error
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/knadh/koanf/parsers/json"
@@ -13,6 +14,7 @@ import (
 	"go-movie-api/utils"
 	"gorm.io/gorm"
 	"log"
+	"os"
 )
 
 var config = koanf.New(".")
@@ -32,8 +34,6 @@ func init() {
 	if err := config.UnmarshalWithConf("env", &configs.Env, koanf.UnmarshalConf{Tag: "koanf"}); err != nil {
 		utils.Logger.Fatal(fmt.Sprintf("failed to read env.json file: %v", err))
 	}
-
-	log.Println("Starting service on port", configs.Env.App.Port)
 }
 
 func main() {
@@ -51,6 +51,23 @@ func main() {
 		utils.Logger.Fatal(fmt.Sprintf("gorm driver errror: %v", err))
 	}
 
+	// Hold the ratings to the configured scale in the database as well as in the requests
+	if err = database.EnforceRatingScale(context.Background(), gormDB, configs.Env.Rating.ScaleMin, configs.Env.Rating.ScaleMax); err != nil {
+		utils.Logger.Fatal(fmt.Sprintf("failed to enforce the rating scale: %v", err))
+	}
+
+	// Run a CLI command, e.g. `go-movie-api seed`, instead of the HTTP server
+	if len(os.Args) > 1 {
+		err = runCommand(gormDB, os.Args[1], os.Args[2:])
+		// Fatal exits without running deferred calls, so the connections are closed first
+		db.Close()
+		if err != nil {
+			utils.Logger.Fatal(err.Error())
+		}
+		return
+	}
+
+	log.Println("Starting service on port", configs.Env.App.Port)
 	app := Server{
 		DB:     db,
 		GormDB: gormDB,
@@ -5,6 +5,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"go-movie-api/domain"
 	"go-movie-api/token"
+	"go-movie-api/utils"
 	"go-movie-api/utils/helper"
 	"gorm.io/gorm"
 	"net/http"
@@ -88,9 +89,45 @@ func (middleware *authMiddleware) Handler(next echo.HandlerFunc) echo.HandlerFun
 			return token.InvalidTokenErr
 		}
 
+		if user.BannedAt != nil {
+			return echo.NewHTTPError(http.StatusForbidden, "the user is banned.")
+		}
+
 		ec.Set(AuthPayloadKey, payload)
 		ec.Set(AuthUserKey, &user)
+		ec.SetRequest(ec.Request().WithContext(utils.ContextWithUserID(ec.Request().Context(), user.ID)))
+
+		return next(ec)
+	}
+}
+
+// OptionalHandler authenticates the request only when an authorization header is sent,
+// anonymous requests reach next without an auth user
+func (middleware *authMiddleware) OptionalHandler(next echo.HandlerFunc) echo.HandlerFunc {
+	authenticated := middleware.Handler(next)
+
+	return func(ec echo.Context) error {
+		if ec.Request().Header.Get(authHeaderKey) == "" {
+			return next(ec)
+		}
+
+		return authenticated(ec)
+	}
+}
+
+// AdminHandler only lets admins reach next, it must run after Handler
+func (middleware *authMiddleware) AdminHandler(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		if !IsAdmin(ec) {
+			return helper.ForbiddenErr
+		}
 
 		return next(ec)
 	}
 }
+
+// IsAdmin reports whether the request was authenticated by an admin
+func IsAdmin(ec echo.Context) bool {
+	authUser, ok := ec.Get(AuthUserKey).(*domain.User)
+	return ok && authUser.IsAdmin
+}
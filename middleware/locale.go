@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-movie-api/utils"
+)
+
+// Locale negotiates the Accept-Language header into the fallback chain used to translate the response
+func Locale(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		ec.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+
+		locales := utils.ParseLocales(ec.Request().Header.Get("Accept-Language"))
+		if len(locales) > 0 {
+			ec.SetRequest(ec.Request().WithContext(utils.ContextWithLocales(ec.Request().Context(), locales)))
+		}
+
+		return next(ec)
+	}
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_movies_search_vector;
+
+ALTER TABLE movies
+    DROP COLUMN IF EXISTS search_vector;
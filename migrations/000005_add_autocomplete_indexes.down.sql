@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_genres_name_trgm;
+
+DROP INDEX IF EXISTS idx_movies_title_trgm;
@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING GIN (lower(title) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_genres_name_trgm ON genres USING GIN (lower(name) gin_trgm_ops);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_movies_rating_average;
+
+DROP INDEX IF EXISTS idx_movies_rating_weighted;
+
+DROP INDEX IF EXISTS idx_ratings_movie_id;
+
+ALTER TABLE movies
+    DROP COLUMN IF EXISTS rating_histogram,
+    DROP COLUMN IF EXISTS rating_weighted,
+    DROP COLUMN IF EXISTS rating_average,
+    DROP COLUMN IF EXISTS rating_count;
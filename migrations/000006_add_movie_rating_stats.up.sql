@@ -0,0 +1,37 @@
+ALTER TABLE movies
+    ADD COLUMN IF NOT EXISTS rating_count     INTEGER       NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_average   NUMERIC(6, 3) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_weighted  NUMERIC(6, 3) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_histogram JSONB         NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_ratings_movie_id ON ratings (movie_id);
+
+CREATE INDEX IF NOT EXISTS idx_movies_rating_weighted ON movies (rating_weighted);
+
+CREATE INDEX IF NOT EXISTS idx_movies_rating_average ON movies (rating_average);
+
+-- Backfill count, mean and histogram, then the weighted score with the default minimum of 10 votes; run
+-- `go-movie-api reconcile-ratings` afterwards when rating.weighted_minimum_votes is set to another value
+WITH buckets AS (SELECT movie_id, round(rating * 2) / 2 AS bucket, COUNT(*) AS total, SUM(rating) AS score
+                 FROM ratings
+                 WHERE deleted_at IS NULL
+                 GROUP BY movie_id, round(rating * 2) / 2),
+     stats AS (SELECT movie_id,
+                      SUM(total)                                              AS total,
+                      SUM(score) / SUM(total)                                 AS average,
+                      jsonb_object_agg(to_char(bucket, 'FM990.0'), total)     AS histogram
+               FROM buckets
+               GROUP BY movie_id)
+UPDATE movies
+SET rating_count     = stats.total,
+    rating_average   = stats.average,
+    rating_histogram = stats.histogram
+FROM stats
+WHERE movies.id = stats.movie_id;
+
+UPDATE movies
+SET rating_weighted = (movies.rating_count * movies.rating_average + 10 * overall.average) / (movies.rating_count + 10)
+FROM (SELECT COALESCE(SUM(rating_count * rating_average) / NULLIF(SUM(rating_count), 0), 0) AS average
+      FROM movies
+      WHERE deleted_at IS NULL) AS overall
+WHERE movies.rating_count > 0;
@@ -0,0 +1,6 @@
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_rating_check,
+    ALTER COLUMN rating TYPE NUMERIC(3, 2),
+    ADD CONSTRAINT ratings_rating_check CHECK ( rating > 0 );
+
+DROP INDEX IF EXISTS ux_ratings_user_id_movie_id;
@@ -0,0 +1,17 @@
+-- Keep only the most recent rating of every user for a movie
+UPDATE ratings
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id IN (SELECT id
+             FROM (SELECT id,
+                          row_number() OVER (PARTITION BY user_id, movie_id ORDER BY updated_at DESC, id DESC) AS position
+                   FROM ratings
+                   WHERE deleted_at IS NULL) AS ranked
+             WHERE position > 1);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ratings_user_id_movie_id ON ratings (user_id, movie_id) WHERE deleted_at IS NULL;
+
+-- The configured rating scale (rating.scale_min .. rating.scale_max) must lie within this range
+ALTER TABLE ratings
+    ALTER COLUMN rating TYPE NUMERIC(4, 2),
+    DROP CONSTRAINT IF EXISTS ratings_rating_check,
+    ADD CONSTRAINT ratings_rating_check CHECK ( rating > 0 AND rating <= 10 );
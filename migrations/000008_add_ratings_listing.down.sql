@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_ratings_user_id_created_at;
+
+DROP INDEX IF EXISTS idx_ratings_movie_id_created_at;
+
+ALTER TABLE ratings
+    DROP COLUMN IF EXISTS unhelpful_count,
+    DROP COLUMN IF EXISTS helpful_count;
@@ -0,0 +1,7 @@
+ALTER TABLE ratings
+    ADD COLUMN IF NOT EXISTS helpful_count   INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS unhelpful_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_ratings_movie_id_created_at ON ratings (movie_id, created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ratings_user_id_created_at ON ratings (user_id, created_at DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS rating_votes
+(
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rating_id  INTEGER   NOT NULL REFERENCES ratings (id) ON DELETE CASCADE,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    helpful    BOOLEAN   NOT NULL,
+    UNIQUE (rating_id, user_id)
+);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS moderation_decisions;
+
+DROP TABLE IF EXISTS rating_reports;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS banned_at;
+
+DROP INDEX IF EXISTS idx_ratings_moderation_status;
+
+ALTER TABLE ratings
+    DROP COLUMN IF EXISTS moderation_status;
@@ -0,0 +1,30 @@
+ALTER TABLE ratings
+    ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'published';
+
+CREATE INDEX IF NOT EXISTS idx_ratings_moderation_status ON ratings (moderation_status) WHERE moderation_status <> 'published';
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS rating_reports
+(
+    id          SERIAL PRIMARY KEY,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    rating_id   INTEGER   NOT NULL REFERENCES ratings (id) ON DELETE CASCADE,
+    user_id     INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reason      TEXT      NOT NULL,
+    UNIQUE (rating_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS moderation_decisions
+(
+    id           SERIAL PRIMARY KEY,
+    created_at   TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rating_id    INTEGER     NOT NULL REFERENCES ratings (id) ON DELETE CASCADE,
+    moderator_id INTEGER     NOT NULL REFERENCES users (id),
+    action       VARCHAR(20) NOT NULL,
+    note         TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_decisions_rating_id ON moderation_decisions (rating_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS comments
+(
+    id          SERIAL PRIMARY KEY,
+    uuid        UUID               DEFAULT gen_random_uuid() UNIQUE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at   TIMESTAMP,
+    deleted_at  TIMESTAMP,
+    rating_id   INTEGER   NOT NULL REFERENCES ratings (id) ON DELETE CASCADE,
+    user_id     INTEGER   NOT NULL REFERENCES users (id),
+    parent_id   INTEGER REFERENCES comments (id) ON DELETE CASCADE,
+    depth       INTEGER   NOT NULL DEFAULT 0,
+    body        TEXT      NOT NULL,
+    reply_count INTEGER   NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_rating_id_created_at ON comments (rating_id, created_at) WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id_created_at ON comments (parent_id, created_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS watchlist_entries
+(
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id   INTEGER   NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    position   INTEGER   NOT NULL,
+    watched_at TIMESTAMP,
+    UNIQUE (user_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_user_id_position ON watchlist_entries (user_id, position);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS movie_list_followers;
+
+DROP TABLE IF EXISTS movie_list_entries;
+
+DROP TABLE IF EXISTS movie_lists;
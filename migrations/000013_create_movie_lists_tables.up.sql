@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS movie_lists
+(
+    id             SERIAL PRIMARY KEY,
+    uuid           UUID                  DEFAULT gen_random_uuid() UNIQUE,
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at     TIMESTAMP,
+    user_id        INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title          VARCHAR(255) NOT NULL,
+    description    TEXT,
+    visibility     VARCHAR(20)  NOT NULL DEFAULT 'private',
+    slug           VARCHAR(255) NOT NULL UNIQUE,
+    follower_count INTEGER      NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_lists_user_id ON movie_lists (user_id);
+
+CREATE TABLE IF NOT EXISTS movie_list_entries
+(
+    id            SERIAL PRIMARY KEY,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    movie_list_id INTEGER   NOT NULL REFERENCES movie_lists (id) ON DELETE CASCADE,
+    movie_id      INTEGER   NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    position      INTEGER   NOT NULL,
+    note          TEXT,
+    UNIQUE (movie_list_id, movie_id)
+);
+
+CREATE TABLE IF NOT EXISTS movie_list_followers
+(
+    movie_list_id INTEGER   NOT NULL REFERENCES movie_lists (id) ON DELETE CASCADE,
+    user_id       INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (movie_list_id, user_id)
+);
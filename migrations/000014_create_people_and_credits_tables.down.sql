@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS credits;
+
+DROP TABLE IF EXISTS people;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS people
+(
+    id         SERIAL PRIMARY KEY,
+    uuid       UUID                  DEFAULT gen_random_uuid() UNIQUE,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    name       VARCHAR(255) NOT NULL,
+    birth_date DATE,
+    bio        TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_name_trgm ON people USING GIN (lower(name) gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS credits
+(
+    id             SERIAL PRIMARY KEY,
+    movie_id       INTEGER     NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    person_id      INTEGER     NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    role           VARCHAR(20) NOT NULL,
+    character_name VARCHAR(255),
+    billing_order  INTEGER     NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_credits_movie_id ON credits (movie_id, billing_order);
+
+CREATE INDEX IF NOT EXISTS idx_credits_person_id ON credits (person_id);
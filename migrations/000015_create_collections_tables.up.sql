@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS collections
+(
+    id          SERIAL PRIMARY KEY,
+    uuid        UUID                  DEFAULT gen_random_uuid() UNIQUE,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS collection_movies
+(
+    collection_id INTEGER NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+    movie_id      INTEGER NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    position      INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_movies_movie_id ON collection_movies (movie_id);
@@ -0,0 +1,3 @@
+ALTER TABLE movies
+    DROP COLUMN IF EXISTS poster_key,
+    DROP COLUMN IF EXISTS backdrop_key;
@@ -0,0 +1,3 @@
+ALTER TABLE movies
+    ADD COLUMN IF NOT EXISTS poster_key   VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS backdrop_key VARCHAR(255) NOT NULL DEFAULT '';
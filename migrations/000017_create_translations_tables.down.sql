@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS genre_translations;
+
+DROP TABLE IF EXISTS movie_translations;
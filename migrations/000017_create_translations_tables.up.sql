@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS movie_translations
+(
+    id            SERIAL PRIMARY KEY,
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    movie_id      INTEGER      NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    locale        VARCHAR(35)  NOT NULL,
+    title         VARCHAR(255) NOT NULL,
+    synopsis      TEXT,
+    search_vector tsvector GENERATED ALWAYS AS (
+                      setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+                      setweight(to_tsvector('simple', coalesce(synopsis, '')), 'B')
+                  ) STORED,
+    UNIQUE (movie_id, locale)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_translations_search_vector ON movie_translations USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS genre_translations
+(
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    genre_id   INTEGER      NOT NULL REFERENCES genres (id) ON DELETE CASCADE,
+    locale     VARCHAR(35)  NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    UNIQUE (genre_id, locale)
+);
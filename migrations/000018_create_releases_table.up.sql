@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS releases
+(
+    id            SERIAL PRIMARY KEY,
+    created_at    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    movie_id      INTEGER     NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    country       CHAR(2)     NOT NULL,
+    type          VARCHAR(20) NOT NULL,
+    release_date  DATE        NOT NULL,
+    certification VARCHAR(20),
+    UNIQUE (movie_id, country, type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_releases_country_release_date ON releases (country, release_date);
+
+CREATE INDEX IF NOT EXISTS idx_releases_release_date ON releases (release_date);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS field_provenances;
+
+DROP TABLE IF EXISTS external_ids;
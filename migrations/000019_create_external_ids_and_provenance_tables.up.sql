@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS external_ids
+(
+    id          SERIAL PRIMARY KEY,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    movie_id    INTEGER      NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    source      VARCHAR(20)  NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    UNIQUE (source, external_id),
+    UNIQUE (movie_id, source)
+);
+
+CREATE TABLE IF NOT EXISTS field_provenances
+(
+    movie_id   INTEGER     NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    field      VARCHAR(50) NOT NULL,
+    source     VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (movie_id, field)
+);
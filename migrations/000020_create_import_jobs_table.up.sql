@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS import_jobs
+(
+    id             SERIAL PRIMARY KEY,
+    uuid           UUID                 DEFAULT gen_random_uuid() UNIQUE,
+    created_at     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id        INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    kind           VARCHAR(20) NOT NULL,
+    format         VARCHAR(20) NOT NULL,
+    dry_run        BOOLEAN     NOT NULL DEFAULT FALSE,
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows     INTEGER     NOT NULL DEFAULT 0,
+    processed_rows INTEGER     NOT NULL DEFAULT 0,
+    succeeded_rows INTEGER     NOT NULL DEFAULT 0,
+    failed_rows    INTEGER     NOT NULL DEFAULT 0,
+    error          TEXT        NOT NULL DEFAULT '',
+    started_at     TIMESTAMP,
+    finished_at    TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS import_row_errors
+(
+    id            SERIAL PRIMARY KEY,
+    import_job_id INTEGER NOT NULL REFERENCES import_jobs (id) ON DELETE CASCADE,
+    row_number    INTEGER NOT NULL,
+    message       TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_row_errors_import_job_id ON import_row_errors (import_job_id, row_number);
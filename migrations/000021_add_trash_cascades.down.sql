@@ -0,0 +1,31 @@
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_ratings_deleted_at;
+DROP INDEX IF EXISTS idx_genres_deleted_at;
+DROP INDEX IF EXISTS idx_movies_deleted_at;
+
+DELETE FROM moderation_decisions WHERE moderator_id IS NULL;
+
+ALTER TABLE moderation_decisions
+    DROP CONSTRAINT IF EXISTS moderation_decisions_moderator_id_fkey,
+    ADD CONSTRAINT moderation_decisions_moderator_id_fkey FOREIGN KEY (moderator_id) REFERENCES users (id),
+    ALTER COLUMN moderator_id SET NOT NULL;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE sessions
+    DROP CONSTRAINT IF EXISTS sessions_user_id_fkey,
+    ADD CONSTRAINT sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_movie_id_fkey,
+    DROP CONSTRAINT IF EXISTS ratings_user_id_fkey,
+    ADD CONSTRAINT ratings_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES movies (id),
+    ADD CONSTRAINT ratings_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE movie_genres
+    DROP CONSTRAINT IF EXISTS movie_genres_movie_id_fkey,
+    DROP CONSTRAINT IF EXISTS movie_genres_genre_id_fkey,
+    ADD CONSTRAINT movie_genres_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES movies (id),
+    ADD CONSTRAINT movie_genres_genre_id_fkey FOREIGN KEY (genre_id) REFERENCES genres (id);
@@ -0,0 +1,31 @@
+-- Purging a movie, genre, rating or user from the trash takes what hangs off it along
+ALTER TABLE movie_genres
+    DROP CONSTRAINT IF EXISTS movie_genres_movie_id_fkey,
+    DROP CONSTRAINT IF EXISTS movie_genres_genre_id_fkey,
+    ADD CONSTRAINT movie_genres_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE,
+    ADD CONSTRAINT movie_genres_genre_id_fkey FOREIGN KEY (genre_id) REFERENCES genres (id) ON DELETE CASCADE;
+
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_movie_id_fkey,
+    DROP CONSTRAINT IF EXISTS ratings_user_id_fkey,
+    ADD CONSTRAINT ratings_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE,
+    ADD CONSTRAINT ratings_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE sessions
+    DROP CONSTRAINT IF EXISTS sessions_user_id_fkey,
+    ADD CONSTRAINT sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+-- Decisions stay in the moderation history of the rating after their moderator is purged
+ALTER TABLE moderation_decisions
+    ALTER COLUMN moderator_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS moderation_decisions_moderator_id_fkey,
+    ADD CONSTRAINT moderation_decisions_moderator_id_fkey FOREIGN KEY (moderator_id) REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_movies_deleted_at ON movies (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_genres_deleted_at ON genres (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ratings_deleted_at ON ratings (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
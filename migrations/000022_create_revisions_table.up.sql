@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS revisions
+(
+    id            SERIAL PRIMARY KEY,
+    created_at    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    movie_id      INTEGER REFERENCES movies (id) ON DELETE CASCADE,
+    genre_id      INTEGER REFERENCES genres (id) ON DELETE CASCADE,
+    version       INTEGER     NOT NULL,
+    user_id       INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    action        VARCHAR(20) NOT NULL,
+    restored_from INTEGER,
+    snapshot      JSONB       NOT NULL,
+    CHECK ( (movie_id IS NULL) <> (genre_id IS NULL) )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_revisions_movie_id_version ON revisions (movie_id, version) WHERE movie_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_revisions_genre_id_version ON revisions (genre_id, version) WHERE genre_id IS NOT NULL;
+
+-- The current state of every movie and genre becomes its first revision, the snapshots must match the
+-- ones the revision repository takes
+INSERT INTO revisions (created_at, movie_id, version, action, snapshot)
+SELECT movies.updated_at,
+       movies.id,
+       1,
+       'created',
+       jsonb_build_object(
+               'title', movies.title,
+               'year', movies.year,
+               'duration', movies.duration,
+               'synopsis', COALESCE(movies.synopsis, ''),
+               'genres', COALESCE((SELECT jsonb_agg(jsonb_build_object('id', genres.uuid, 'name', genres.name)
+                                                    ORDER BY genres.name, genres.uuid)
+                                   FROM movie_genres
+                                            JOIN genres ON genres.id = movie_genres.genre_id AND genres.deleted_at IS NULL
+                                   WHERE movie_genres.movie_id = movies.id), '[]'::jsonb))
+FROM movies
+WHERE NOT EXISTS (SELECT 1 FROM revisions WHERE revisions.movie_id = movies.id);
+
+INSERT INTO revisions (created_at, genre_id, version, action, snapshot)
+SELECT genres.updated_at, genres.id, 1, 'created', jsonb_build_object('name', genres.name)
+FROM genres
+WHERE NOT EXISTS (SELECT 1 FROM revisions WHERE revisions.genre_id = genres.id);
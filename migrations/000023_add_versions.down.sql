@@ -0,0 +1,11 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE ratings
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE genres
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE movies
+    DROP COLUMN IF EXISTS version;
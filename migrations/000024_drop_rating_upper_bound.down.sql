@@ -0,0 +1,3 @@
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_rating_check,
+    ADD CONSTRAINT ratings_rating_check CHECK ( rating > 0 AND rating <= 10 );
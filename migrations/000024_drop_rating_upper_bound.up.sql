@@ -0,0 +1,5 @@
+-- The rating scale is configured (rating.scale_min .. rating.scale_max) and checked by the API, the table only
+-- rejects ratings no scale has
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_rating_check,
+    ADD CONSTRAINT ratings_rating_check CHECK ( rating > 0 );
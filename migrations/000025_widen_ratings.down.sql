@@ -0,0 +1,4 @@
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_rating_check,
+    ALTER COLUMN rating TYPE NUMERIC(4, 2),
+    ADD CONSTRAINT ratings_rating_check CHECK ( rating > 0 );
@@ -0,0 +1,6 @@
+-- Ratings take up to 9999.99 and 0, the API replaces this check with one of the configured rating scale
+-- (rating.scale_min .. rating.scale_max) at startup, see database.EnforceRatingScale
+ALTER TABLE ratings
+    DROP CONSTRAINT IF EXISTS ratings_rating_check,
+    ALTER COLUMN rating TYPE NUMERIC(6, 2),
+    ADD CONSTRAINT ratings_rating_check CHECK ( rating >= 0 );
@@ -0,0 +1,5 @@
+-- Comments stay in their thread after their author is purged, so that the replies of other users keep their place
+ALTER TABLE comments
+    ALTER COLUMN user_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
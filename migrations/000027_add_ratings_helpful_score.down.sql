@@ -0,0 +1,2 @@
+ALTER TABLE ratings
+    DROP COLUMN IF EXISTS helpful_score;
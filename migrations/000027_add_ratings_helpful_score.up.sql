@@ -0,0 +1,3 @@
+-- The helpful sort orders by a column, so that cursor pages can compare against it
+ALTER TABLE ratings
+    ADD COLUMN IF NOT EXISTS helpful_score INTEGER GENERATED ALWAYS AS (helpful_count - unhelpful_count) STORED;
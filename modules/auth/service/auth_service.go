@@ -46,6 +46,10 @@ func (service *authService) Authenticate(ctx context.Context, user *domain.User)
 		return domain.User{}, err
 	}
 
+	if authUser.BannedAt != nil {
+		return domain.User{}, echo.NewHTTPError(http.StatusForbidden, "the user is banned.")
+	}
+
 	return authUser, nil
 }
 
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+type AutocompleteController struct {
+	domain.AutocompleteService
+}
+
+func NewAutocompleteController(router *echo.Echo, autocompleteService domain.AutocompleteService) {
+	controller := &AutocompleteController{
+		AutocompleteService: autocompleteService,
+	}
+
+	router.GET("/autocomplete", controller.Index)
+}
+
+func (controller *AutocompleteController) Index(ec echo.Context) error {
+	query := strings.TrimSpace(ec.QueryParam("q"))
+	if query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "the search query is required.")
+	}
+
+	types := []string{domain.SuggestionTypeMovie, domain.SuggestionTypeGenre}
+	if param := ec.QueryParam("types"); param != "" {
+		types = nil
+		seen := make(map[string]bool)
+		for _, suggestionType := range strings.Split(param, ",") {
+			suggestionType = strings.TrimSpace(suggestionType)
+			if suggestionType != domain.SuggestionTypeMovie && suggestionType != domain.SuggestionTypeGenre {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("the type %q is not valid.", suggestionType))
+			}
+			if !seen[suggestionType] {
+				seen[suggestionType] = true
+				types = append(types, suggestionType)
+			}
+		}
+	}
+
+	limit, err := strconv.Atoi(ec.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	data, err := controller.AutocompleteService.Suggest(ec.Request().Context(), query, types, limit)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Suggestion, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Data: data,
+	})
+}
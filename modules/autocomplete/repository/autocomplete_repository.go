@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// similarityThreshold is the lowest pg_trgm word similarity accepted as a typo tolerant match
+const similarityThreshold = "0.3"
+
+type suggestionSource struct {
+	table  string
+	column string
+}
+
+// sources maps every suggestion type to the table and column searched for it
+var sources = map[string]suggestionSource{
+	domain.SuggestionTypeMovie: {table: "movies", column: "title"},
+	domain.SuggestionTypeGenre: {table: "genres", column: "name"},
+}
+
+type autocompleteRepository struct {
+	db *gorm.DB
+}
+
+func NewAutocompleteRepository(gormDB *gorm.DB) domain.AutocompleteRepository {
+	return &autocompleteRepository{db: gormDB}
+}
+
+// Suggest returns rows whose text starts with the query or is similar to it, prefix matches score 1
+func (repo *autocompleteRepository) Suggest(ctx context.Context, query string, suggestionType string, limit int) ([]domain.Suggestion, error) {
+	source, ok := sources[suggestionType]
+	if !ok {
+		return nil, fmt.Errorf("unknown suggestion type %q", suggestionType)
+	}
+
+	query = strings.ToLower(query)
+	prefix := escapeLike(query) + "%"
+	column := fmt.Sprintf("lower(%s.%s)", source.table, source.column)
+
+	var suggestions []domain.Suggestion
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the <% operator reads its threshold from this setting, scoped to the transaction
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", similarityThreshold).Error; err != nil {
+			return err
+		}
+
+		return tx.Table(source.table).
+			Select(
+				fmt.Sprintf("? AS type, uuid, %s AS label, "+
+					"GREATEST(word_similarity(?, %s), CASE WHEN %s LIKE ? THEN 1 ELSE 0 END) AS score, "+
+					"similarity(?, %s) AS similarity",
+					source.column, column, column, column),
+				suggestionType, query, prefix, query,
+			).
+			Where("deleted_at IS NULL").
+			Where(fmt.Sprintf("%s LIKE ? OR ? <%% %s", column, column), prefix, query).
+			Order("score desc, similarity desc, label asc").
+			Limit(limit).
+			Scan(&suggestions).Error
+	})
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// escapeLike escapes the LIKE wildcards so that user input is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-movie-api/domain"
+	"go-movie-api/utils/cache"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheSize is the number of recent prefixes whose suggestions are kept in memory
+const cacheSize = 1000
+
+type autocompleteService struct {
+	autocompleteRepo domain.AutocompleteRepository
+	cache            *cache.LRU[string, []domain.Suggestion]
+	timeout          time.Duration
+
+	// generation counts the invalidations, suggestions read before one are not cached after it
+	mutex      sync.Mutex
+	generation uint64
+}
+
+func NewAutocompleteService(autocompleteRepo domain.AutocompleteRepository, timeout time.Duration) domain.AutocompleteService {
+	return &autocompleteService{
+		autocompleteRepo: autocompleteRepo,
+		cache:            cache.NewLRU[string, []domain.Suggestion](cacheSize),
+		timeout:          timeout,
+	}
+}
+
+func (service *autocompleteService) Suggest(ctx context.Context, query string, types []string, limit int) ([]domain.Suggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	key := fmt.Sprintf("%s|%d|%s", strings.Join(types, ","), limit, query)
+	if suggestions, ok := service.cache.Get(key); ok {
+		return append([]domain.Suggestion(nil), suggestions...), nil
+	}
+
+	service.mutex.Lock()
+	generation := service.generation
+	service.mutex.Unlock()
+
+	var suggestions []domain.Suggestion
+	for _, suggestionType := range types {
+		result, err := service.autocompleteRepo.Suggest(ctx, query, suggestionType, limit)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, result...)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	service.mutex.Lock()
+	if service.generation == generation {
+		service.cache.Add(key, append([]domain.Suggestion(nil), suggestions...))
+	}
+	service.mutex.Unlock()
+
+	return suggestions, nil
+}
+
+func (service *autocompleteService) Invalidate() {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	service.generation++
+	service.cache.Purge()
+}
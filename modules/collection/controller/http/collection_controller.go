@@ -0,0 +1,157 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+)
+
+type CollectionController struct {
+	domain.CollectionService
+}
+
+func NewCollectionController(router *echo.Echo, collectionService domain.CollectionService) {
+	controller := &CollectionController{
+		CollectionService: collectionService,
+	}
+
+	admin := []echo.MiddlewareFunc{middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler}
+
+	group := router.Group("/collections")
+	group.GET("", controller.Index)
+	group.GET("/:uuid", controller.Show)
+	group.POST("", controller.Store, admin...)
+	group.PUT("/:uuid", controller.Update, admin...)
+	group.DELETE("/:uuid", controller.Destroy, admin...)
+	group.PUT("/:uuid/movies", controller.ReplaceMovies, admin...)
+}
+
+func (controller *CollectionController) Index(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.CollectionService.FetchPagination(ec.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Collection, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
+func (controller *CollectionController) Show(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.CollectionService.FindByID(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *CollectionController) Store(ec echo.Context) error {
+	var request storeRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	data, err := controller.CollectionService.Store(ec.Request().Context(), &domain.Collection{
+		Name:        request.Name,
+		Description: request.Description,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+func (controller *CollectionController) Update(ec echo.Context) error {
+	var request updateRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	err = controller.CollectionService.Update(ec.Request().Context(), &domain.Collection{
+		Uuid:        id,
+		Name:        request.Name,
+		Description: request.Description,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+func (controller *CollectionController) Destroy(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	err = controller.CollectionService.SoftDelete(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+// ReplaceMovies sets the movies of the collection, ordered as given
+func (controller *CollectionController) ReplaceMovies(ec echo.Context) error {
+	var request replaceMoviesRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.CollectionService.ReplaceMovies(ec.Request().Context(), id, request.MovieIDs)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
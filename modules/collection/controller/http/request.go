@@ -0,0 +1,17 @@
+package http
+
+import "github.com/google/uuid"
+
+type storeRequest struct {
+	Name        string `json:"name" form:"name" validate:"required,max=255"`
+	Description string `json:"description" form:"description" validate:"omitempty"`
+}
+
+type updateRequest struct {
+	Name        string `json:"name" form:"name" validate:"omitempty,max=255"`
+	Description string `json:"description" form:"description" validate:"omitempty"`
+}
+
+type replaceMoviesRequest struct {
+	MovieIDs []uuid.UUID `json:"movie_ids" validate:"dive,required"`
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type collectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(gormDB *gorm.DB) domain.CollectionRepository {
+	return &collectionRepository{db: gormDB}
+}
+
+func (repo *collectionRepository) FetchPagination(ctx context.Context, pagination *utils.Pagination) ([]domain.Collection, error) {
+	var collections []domain.Collection
+
+	result := repo.db.WithContext(ctx).
+		Scopes(utils.Paginate(collections, pagination, repo.db.WithContext(ctx))).
+		Order("name ASC").
+		Order("id ASC").
+		Find(&collections)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return collections, nil
+}
+
+func (repo *collectionRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Collection, error) {
+	var collection domain.Collection
+
+	result := database.Conn(ctx, repo.db).
+		Preload("Entries", func(db *gorm.DB) *gorm.DB {
+			return db.Where("collection_movies.movie_id IN (?)",
+				db.Session(&gorm.Session{NewDB: true}).Model(&domain.Movie{}).Select("id")).
+				Order("collection_movies.position ASC")
+		}).
+		Preload("Entries.Movie").
+		Where("uuid = ?", uuid.String()).
+		First(&collection)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Collection{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Collection{}, result.Error
+	}
+
+	return collection, nil
+}
+
+func (repo *collectionRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Collection, error) {
+	var collection domain.Collection
+
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = ?", uuid.String()).
+		First(&collection)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Collection{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Collection{}, result.Error
+	}
+
+	return collection, nil
+}
+
+func (repo *collectionRepository) Store(ctx context.Context, collection *domain.Collection) (domain.Collection, error) {
+	result := repo.db.WithContext(ctx).Clauses(clause.Returning{}).Omit("uuid", "Entries").Create(collection)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return domain.Collection{}, result.Error
+	}
+
+	return *collection, nil
+}
+
+func (repo *collectionRepository) Update(ctx context.Context, collection *domain.Collection) error {
+	result := repo.db.WithContext(ctx).
+		Model(collection).
+		Where("uuid = ?", collection.Uuid.String()).
+		Omit("Entries").
+		Updates(collection)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *collectionRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).Delete(&domain.Collection{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *collectionRepository) ReplaceMovies(ctx context.Context, collectionID uint, entries []domain.CollectionMovie) error {
+	db := database.Conn(ctx, repo.db)
+
+	if result := db.Where("collection_id = ?", collectionID).Delete(&domain.CollectionMovie{}); result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if result := db.Omit("Movie").Create(&entries); result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
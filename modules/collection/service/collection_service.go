@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"net/http"
+	"time"
+)
+
+type collectionService struct {
+	collectionRepo domain.CollectionRepository
+	movieRepo      domain.MovieRepository
+	transactor     domain.Transactor
+	timeout        time.Duration
+}
+
+func NewCollectionService(collectionRepo domain.CollectionRepository, movieRepo domain.MovieRepository, transactor domain.Transactor, timeout time.Duration) domain.CollectionService {
+	return &collectionService{
+		collectionRepo: collectionRepo,
+		movieRepo:      movieRepo,
+		transactor:     transactor,
+		timeout:        timeout,
+	}
+}
+
+func (service *collectionService) FetchPagination(ctx context.Context, page int, perPage int) ([]domain.Collection, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	collections, err := service.collectionRepo.FetchPagination(ctx, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return collections, pagination, nil
+}
+
+func (service *collectionService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Collection, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	collection, err := service.collectionRepo.FindByID(ctx, uuid)
+	if err != nil {
+		return domain.Collection{}, err
+	}
+
+	aggregateRatings(&collection)
+	return collection, nil
+}
+
+func (service *collectionService) Store(ctx context.Context, collection *domain.Collection) (domain.Collection, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.collectionRepo.Store(ctx, collection)
+}
+
+func (service *collectionService) Update(ctx context.Context, collection *domain.Collection) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.collectionRepo.Update(ctx, collection)
+}
+
+func (service *collectionService) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.collectionRepo.SoftDelete(ctx, uuid)
+}
+
+func (service *collectionService) ReplaceMovies(ctx context.Context, collectionUuid uuid.UUID, movieUuids []uuid.UUID) (domain.Collection, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	var collection domain.Collection
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		locked, err := service.collectionRepo.FindByIDForUpdate(ctx, collectionUuid)
+		if err != nil {
+			return err
+		}
+
+		movies, err := service.movieRepo.FindByIDs(ctx, movieUuids)
+		if err != nil {
+			return err
+		}
+
+		movieIDs := make(map[uuid.UUID]uint, len(movies))
+		for _, movie := range movies {
+			movieIDs[movie.Uuid] = movie.ID
+		}
+
+		entries := make([]domain.CollectionMovie, 0, len(movieUuids))
+		seen := make(map[uuid.UUID]bool, len(movieUuids))
+		for i, movieUuid := range movieUuids {
+			movieID, ok := movieIDs[movieUuid]
+			if !ok || seen[movieUuid] {
+				return echo.NewHTTPError(http.StatusBadRequest, "The movie(s) is not valid.")
+			}
+			seen[movieUuid] = true
+
+			entries = append(entries, domain.CollectionMovie{
+				CollectionID: locked.ID,
+				MovieID:      movieID,
+				Position:     int32(i + 1),
+			})
+		}
+
+		if err := service.collectionRepo.ReplaceMovies(ctx, locked.ID, entries); err != nil {
+			return err
+		}
+
+		collection, err = service.collectionRepo.FindByID(ctx, collectionUuid)
+		return err
+	})
+	if err != nil {
+		return domain.Collection{}, err
+	}
+
+	aggregateRatings(&collection)
+	return collection, nil
+}
+
+// aggregateRatings sums the rating counts of the movies and averages their ratings weighted by each count
+func aggregateRatings(collection *domain.Collection) {
+	var count int32
+	var total float64
+	for _, entry := range collection.Entries {
+		if entry.Movie == nil {
+			continue
+		}
+		count += entry.Movie.RatingCount
+		total += float64(entry.Movie.RatingAverage) * float64(entry.Movie.RatingCount)
+	}
+
+	collection.RatingCount = count
+	if count > 0 {
+		collection.RatingAverage = float32(total / float64(count))
+	}
+}
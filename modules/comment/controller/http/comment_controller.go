@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+)
+
+type CommentController struct {
+	domain.CommentService
+}
+
+func NewCommentController(router *echo.Echo, commentService domain.CommentService) {
+	controller := &CommentController{
+		CommentService: commentService,
+	}
+
+	router.GET("/ratings/:uuid/comments", controller.IndexByRating)
+	router.POST("/ratings/:uuid/comments", controller.Store, middleware.AuthMiddleware.Handler)
+
+	group := router.Group("/comments")
+	group.GET("/:uuid", controller.Show)
+	group.GET("/:uuid/replies", controller.IndexReplies)
+	group.POST("/:uuid/replies", controller.Reply, middleware.AuthMiddleware.Handler)
+	group.PUT("/:uuid", controller.Update, middleware.AuthMiddleware.Handler)
+	group.DELETE("/:uuid", controller.Destroy, middleware.AuthMiddleware.Handler)
+}
+
+func (controller *CommentController) IndexByRating(ec echo.Context) error {
+	return controller.index(ec, controller.CommentService.FetchByRating)
+}
+
+func (controller *CommentController) IndexReplies(ec echo.Context) error {
+	return controller.index(ec, controller.CommentService.FetchReplies)
+}
+
+// index lists one page of the thread below the review or comment identified by the uuid path parameter
+func (controller *CommentController) index(ec echo.Context, fetch func(ctx context.Context, uuid uuid.UUID, page int, perPage int) ([]domain.Comment, utils.Pagination, error)) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 20
+	}
+
+	data, pagination, err := fetch(ec.Request().Context(), id, page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Comment, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
+func (controller *CommentController) Show(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.CommentService.FindByID(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *CommentController) Store(ec echo.Context) error {
+	return controller.store(ec, func(id uuid.UUID, comment *domain.Comment) {
+		comment.Rating = &domain.Rating{Uuid: id}
+	})
+}
+
+func (controller *CommentController) Reply(ec echo.Context) error {
+	return controller.store(ec, func(id uuid.UUID, comment *domain.Comment) {
+		comment.Parent = &domain.Comment{Uuid: id}
+	})
+}
+
+// store creates a comment, attach points it at the review or parent comment identified by the uuid path parameter
+func (controller *CommentController) store(ec echo.Context, attach func(id uuid.UUID, comment *domain.Comment)) error {
+	var request storeRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	comment := &domain.Comment{
+		Body:   request.Body,
+		UserID: authUser.ID,
+	}
+	attach(id, comment)
+
+	data, err := controller.CommentService.Store(ec.Request().Context(), comment)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+func (controller *CommentController) Update(ec echo.Context) error {
+	var request updateRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.CommentService.Update(ec.Request().Context(), &domain.Comment{
+		Uuid:   id,
+		Body:   request.Body,
+		UserID: authUser.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+func (controller *CommentController) Destroy(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.CommentService.SoftDelete(ec.Request().Context(), id, authUser)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
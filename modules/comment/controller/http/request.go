@@ -0,0 +1,9 @@
+package http
+
+type storeRequest struct {
+	Body string `json:"body" form:"body" validate:"required,max=5000"`
+}
+
+type updateRequest struct {
+	Body string `json:"body" form:"body" validate:"required,max=5000"`
+}
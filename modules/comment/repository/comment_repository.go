@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(gormDB *gorm.DB) domain.CommentRepository {
+	return &commentRepository{db: gormDB}
+}
+
+func (repo *commentRepository) FetchByRating(ctx context.Context, ratingID uint, pagination *utils.Pagination) ([]domain.Comment, error) {
+	var comments []domain.Comment
+
+	thread := database.Conn(ctx, repo.db).Where("rating_id = ? AND parent_id IS NULL", ratingID)
+	result := database.Conn(ctx, repo.db).
+		Where("rating_id = ? AND parent_id IS NULL", ratingID).
+		Scopes(utils.Paginate(comments, pagination, thread)).
+		Preload("User").
+		Order("created_at ASC").
+		Order("id ASC").
+		Find(&comments)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return comments, nil
+}
+
+func (repo *commentRepository) FetchReplies(ctx context.Context, parentID uint, pagination *utils.Pagination) ([]domain.Comment, error) {
+	var comments []domain.Comment
+
+	thread := database.Conn(ctx, repo.db).Where("parent_id = ?", parentID)
+	result := database.Conn(ctx, repo.db).
+		Where("parent_id = ?", parentID).
+		Scopes(utils.Paginate(comments, pagination, thread)).
+		Preload("User").
+		Order("created_at ASC").
+		Order("id ASC").
+		Find(&comments)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return comments, nil
+}
+
+func (repo *commentRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Comment, error) {
+	var comment domain.Comment
+
+	result := database.Conn(ctx, repo.db).
+		Preload("User").
+		Where("uuid = ?", uuid.String()).
+		First(&comment)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Comment{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Comment{}, result.Error
+	}
+
+	return comment, nil
+}
+
+func (repo *commentRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Comment, error) {
+	var comment domain.Comment
+
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Rating").
+		Where("uuid = ?", uuid.String()).
+		First(&comment)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Comment{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Comment{}, result.Error
+	}
+
+	return comment, nil
+}
+
+func (repo *commentRepository) Store(ctx context.Context, comment *domain.Comment) (domain.Comment, error) {
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.Returning{}).
+		Omit("uuid", "User", "Rating", "Parent").
+		Create(comment)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return domain.Comment{}, result.Error
+	}
+
+	return *comment, nil
+}
+
+func (repo *commentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	result := database.Conn(ctx, repo.db).
+		Model(comment).
+		Where("uuid = ?", comment.Uuid.String()).
+		Select("body", "edited_at", "updated_at").
+		Updates(comment)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+// SoftDelete only marks the comment as deleted, the row stays so that its replies keep their thread
+func (repo *commentRepository) SoftDelete(ctx context.Context, id uint) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.Comment{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now())
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *commentRepository) SoftDeleteByUser(ctx context.Context, userID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.Comment{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"body":       domain.DeletedCommentBody,
+			"deleted_at": gorm.Expr("COALESCE(deleted_at, ?)", time.Now()),
+		})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *commentRepository) IncrementReplyCount(ctx context.Context, id uint) error {
+	result := database.Conn(ctx, repo.db).Exec("UPDATE comments SET reply_count = reply_count + 1 WHERE id = ?", id)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
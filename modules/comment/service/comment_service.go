@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"net/http"
+	"time"
+)
+
+type commentService struct {
+	commentRepo domain.CommentRepository
+	ratingRepo  domain.RatingRepository
+	transactor  domain.Transactor
+	timeout     time.Duration
+}
+
+func NewCommentService(commentRepo domain.CommentRepository, ratingRepo domain.RatingRepository, transactor domain.Transactor, timeout time.Duration) domain.CommentService {
+	return &commentService{
+		commentRepo: commentRepo,
+		ratingRepo:  ratingRepo,
+		transactor:  transactor,
+		timeout:     timeout,
+	}
+}
+
+func (service *commentService) FetchByRating(ctx context.Context, ratingUuid uuid.UUID, page int, perPage int) ([]domain.Comment, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	rating, err := service.ratingRepo.FindByID(ctx, ratingUuid)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	comments, err := service.commentRepo.FetchByRating(ctx, rating.ID, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return redact(comments), pagination, nil
+}
+
+func (service *commentService) FetchReplies(ctx context.Context, uuid uuid.UUID, page int, perPage int) ([]domain.Comment, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	parent, err := service.commentRepo.FindByID(ctx, uuid)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	comments, err := service.commentRepo.FetchReplies(ctx, parent.ID, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return redact(comments), pagination, nil
+}
+
+func (service *commentService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	comment, err := service.commentRepo.FindByID(ctx, uuid)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return redact([]domain.Comment{comment})[0], nil
+}
+
+func (service *commentService) Store(ctx context.Context, comment *domain.Comment) (domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	var result domain.Comment
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if comment.Parent != nil {
+			// the parent row lock keeps concurrent replies from losing a reply count increment
+			parent, err := service.commentRepo.FindByIDForUpdate(ctx, comment.Parent.Uuid)
+			if err != nil {
+				if err == errors.NotFoundErr {
+					return echo.NewHTTPError(http.StatusBadRequest, "The parent comment is not valid.")
+				}
+				return err
+			}
+
+			if parent.DeletedAt != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "A deleted comment cannot be replied to.")
+			}
+
+			if parent.Rating == nil || parent.Rating.ModerationStatus != domain.ModerationStatusPublished {
+				return echo.NewHTTPError(http.StatusBadRequest, "Only published reviews can be commented on.")
+			}
+
+			if maxDepth := configs.Env.Comment.MaxDepth; parent.Depth+1 > maxDepth {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Replies cannot be nested deeper than %d levels.", maxDepth))
+			}
+
+			comment.RatingID = parent.RatingID
+			comment.ParentID = &parent.ID
+			comment.Depth = parent.Depth + 1
+
+			if err = service.commentRepo.IncrementReplyCount(ctx, parent.ID); err != nil {
+				return err
+			}
+		} else {
+			// the rating row lock keeps a moderation decision from hiding the review while it is commented on
+			rating, err := service.ratingRepo.FindByIDForUpdate(ctx, comment.Rating.Uuid)
+			if err != nil {
+				if err == errors.NotFoundErr {
+					return echo.NewHTTPError(http.StatusBadRequest, "The rating is not valid.")
+				}
+				return err
+			}
+
+			if rating.ModerationStatus != domain.ModerationStatusPublished {
+				return echo.NewHTTPError(http.StatusBadRequest, "Only published reviews can be commented on.")
+			}
+
+			comment.RatingID = rating.ID
+		}
+
+		var err error
+		result, err = service.commentRepo.Store(ctx, comment)
+		return err
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return result, nil
+}
+
+func (service *commentService) Update(ctx context.Context, comment *domain.Comment) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.commentRepo.FindByIDForUpdate(ctx, comment.Uuid)
+		if err != nil {
+			return err
+		}
+
+		if existing.DeletedAt != nil {
+			return errors.NotFoundErr
+		}
+
+		if existing.UserID != comment.UserID {
+			return errors.ForbiddenErr
+		}
+
+		editedAt := time.Now()
+		comment.EditedAt = &editedAt
+
+		return service.commentRepo.Update(ctx, comment)
+	})
+}
+
+func (service *commentService) SoftDelete(ctx context.Context, uuid uuid.UUID, user *domain.User) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.commentRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+
+		if existing.DeletedAt != nil {
+			return errors.NotFoundErr
+		}
+
+		if existing.UserID != user.ID && !user.IsAdmin {
+			return errors.ForbiddenErr
+		}
+
+		return service.commentRepo.SoftDelete(ctx, existing.ID)
+	})
+}
+
+// redact hides the body and author of deleted comments, they are still listed to keep the thread intact
+func redact(comments []domain.Comment) []domain.Comment {
+	for i := range comments {
+		if comments[i].DeletedAt != nil {
+			comments[i].Body = domain.DeletedCommentBody
+			comments[i].User = nil
+			comments[i].EditedAt = nil
+		}
+	}
+
+	return comments
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/export"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+type snapshotService struct {
+	movieService  domain.MovieService
+	ratingService domain.RatingService
+	blobStore     domain.BlobStore
+	format        string
+	prefix        string
+	timeout       time.Duration
+}
+
+// NewSnapshotService writes snapshots in the configured format, timeout bounds each upload to the blob store
+func NewSnapshotService(movieService domain.MovieService, ratingService domain.RatingService, blobStore domain.BlobStore, timeout time.Duration) domain.SnapshotService {
+	format := configs.Env.Export.Snapshot.Format
+	if _, ok := export.ContentTypes[format]; !ok {
+		format = export.FormatParquet
+	}
+
+	return &snapshotService{
+		movieService:  movieService,
+		ratingService: ratingService,
+		blobStore:     blobStore,
+		format:        format,
+		prefix:        configs.Env.Export.Snapshot.Prefix,
+		timeout:       timeout,
+	}
+}
+
+func (service *snapshotService) Snapshot(ctx context.Context) ([]string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	// the timestamp keeps snapshots in order when listed, the suffix keeps two snapshots of the same second apart
+	dir := path.Join(service.prefix, fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102T150405Z"), hex.EncodeToString(suffix)))
+
+	exports := []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{"movies", func(w io.Writer) error {
+			return service.movieService.Export(ctx, domain.MovieFilter{}, service.format, w)
+		}},
+		{"ratings", func(w io.Writer) error {
+			return service.ratingService.Export(ctx, domain.RatingExportFilter{}, service.format, w)
+		}},
+	}
+
+	keys := make([]string, 0, len(exports))
+	for _, item := range exports {
+		key := path.Join(dir, fmt.Sprintf("%s.%s", item.name, service.format))
+		if err := service.put(ctx, key, item.write); err != nil {
+			return keys, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// put spools the export to a temporary file first, the blob store needs its size before the upload starts
+func (service *snapshotService) put(ctx context.Context, key string, write func(w io.Writer) error) error {
+	file, err := os.CreateTemp("", "export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.blobStore.Put(ctx, key, file, size, export.ContentTypes[service.format])
+}
+
+func (service *snapshotService) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			keys, err := service.Snapshot(ctx)
+			if err != nil {
+				utils.Logger.Error(fmt.Sprintf("failed to take export snapshot: %s", err))
+				continue
+			}
+			utils.Logger.Info(fmt.Sprintf("export snapshot written to %v", keys))
+		}
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"go-movie-api/domain"
 	"go-movie-api/middleware"
+	"go-movie-api/utils"
+	"go-movie-api/utils/etag"
+	"go-movie-api/utils/helper"
+	"go-movie-api/utils/patch"
 	"go-movie-api/utils/response"
 	"net/http"
 	"strconv"
@@ -24,10 +28,18 @@ func NewGenreController(router *echo.Echo, genreService domain.GenreService) {
 	group.GET("", controller.Index)
 	group.POST("", controller.Store, middleware.AuthMiddleware.Handler)
 	group.PUT("/:uuid", controller.Update, middleware.AuthMiddleware.Handler)
+	group.PATCH("/:uuid", controller.Patch, middleware.AuthMiddleware.Handler)
 	group.DELETE("/:uuid", controller.Destroy, middleware.AuthMiddleware.Handler)
+	group.POST("/:uuid/restore", controller.Restore, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+
+	router.GET("/admin/trash/genres", controller.Trash, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
 }
 
 func (controller *GenreController) Index(ec echo.Context) error {
+	if utils.CursorRequested(ec) {
+		return controller.indexCursor(ec)
+	}
+
 	page, err := strconv.Atoi(ec.QueryParam("page"))
 	if err != nil || page <= 0 {
 		page = 1
@@ -54,6 +66,28 @@ func (controller *GenreController) Index(ec echo.Context) error {
 	})
 }
 
+func (controller *GenreController) indexCursor(ec echo.Context) error {
+	cursorPagination, err := utils.NewCursorPagination(ec)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	data, cursorPagination, err := controller.GenreService.FetchCursor(ctx, cursorPagination)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Genre, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: cursorPagination,
+		Data: data,
+	})
+}
+
 func (controller *GenreController) Show(ec echo.Context) error {
 	id, err := uuid.Parse(ec.Param("uuid"))
 	if err != nil {
@@ -65,7 +99,7 @@ func (controller *GenreController) Show(ec echo.Context) error {
 		return err
 	}
 
-	return ec.JSON(http.StatusOK, data)
+	return etag.JSON(ec, data.Version, data)
 }
 
 func (controller *GenreController) Store(ec echo.Context) error {
@@ -103,9 +137,55 @@ func (controller *GenreController) Update(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
 	err = controller.GenreService.Update(ec.Request().Context(), &domain.Genre{
 		Uuid: id,
 		Name: request.Name,
+	}, version)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+// Patch changes the genre with a JSON Merge Patch or a JSON Patch of the fields of patchRequest
+func (controller *GenreController) Patch(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	document, err := patch.FromRequest(ec)
+	if err != nil {
+		return err
+	}
+
+	err = controller.GenreService.Patch(ec.Request().Context(), id, version, func(genre *domain.Genre) error {
+		request := patchRequest{
+			Name: genre.Name,
+		}
+
+		if err := document.ApplyTo(&request); err != nil {
+			return err
+		}
+
+		if err := ec.Validate(request); err != nil {
+			return err
+		}
+
+		genre.Name = request.Name
+
+		return nil
 	})
 	if err != nil {
 		return err
@@ -120,10 +200,64 @@ func (controller *GenreController) Destroy(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
-	err = controller.GenreService.SoftDelete(ec.Request().Context(), id)
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	// hard=true deletes the genre for good, from the trash or not, which only admins may do
+	if hard, _ := strconv.ParseBool(ec.QueryParam("hard")); hard {
+		if !middleware.IsAdmin(ec) {
+			return helper.ForbiddenErr
+		}
+		err = controller.GenreService.Delete(ec.Request().Context(), id, version)
+	} else {
+		err = controller.GenreService.SoftDelete(ec.Request().Context(), id, version)
+	}
 	if err != nil {
 		return err
 	}
 
 	return ec.JSON(http.StatusOK, response.DeleteSuccess)
 }
+
+// Trash lists the soft deleted genres, the most recently deleted first
+func (controller *GenreController) Trash(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.GenreService.FetchTrashed(ec.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	trashed := make([]trashedGenre, 0, len(data))
+	for _, genre := range data {
+		trashed = append(trashed, trashedGenre{Genre: genre, DeletedAt: genre.DeletedAt.Time})
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: trashed,
+	})
+}
+
+func (controller *GenreController) Restore(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	if err = controller.GenreService.Restore(ec.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.RestoreSuccess)
+}
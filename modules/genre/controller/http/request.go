@@ -7,3 +7,8 @@ type storeRequest struct {
 type updateRequest struct {
 	Name string `json:"name" form:"name" validate:"omitempty"`
 }
+
+// patchRequest holds the fields of a genre a PATCH request can change, the patch applies to its JSON
+type patchRequest struct {
+	Name string `json:"name" validate:"required"`
+}
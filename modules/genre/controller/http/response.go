@@ -0,0 +1,12 @@
+package http
+
+import (
+	"go-movie-api/domain"
+	"time"
+)
+
+// trashedGenre is a genre in the trash, with the time it went there
+type trashedGenre struct {
+	domain.Genre
+	DeletedAt time.Time `json:"deleted_at"`
+}
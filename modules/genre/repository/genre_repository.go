@@ -3,11 +3,14 @@ package repository
 import (
 	"context"
 	"github.com/google/uuid"
+	"go-movie-api/database"
 	"go-movie-api/domain"
 	"go-movie-api/utils"
 	"go-movie-api/utils/helper"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strings"
+	"time"
 )
 
 type genreRepository struct {
@@ -24,6 +27,7 @@ func (repo *genreRepository) FetchPagination(ctx context.Context, pagination *ut
 	result := repo.db.WithContext(ctx).
 		Scopes(utils.Paginate(genres, pagination, repo.db)).
 		Order("id asc").
+		Scopes(preloadTranslations(ctx)).
 		Find(&genres)
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
@@ -33,11 +37,26 @@ func (repo *genreRepository) FetchPagination(ctx context.Context, pagination *ut
 	return genres, nil
 }
 
+func (repo *genreRepository) FetchCursor(ctx context.Context, pagination *utils.CursorPagination) ([]domain.Genre, error) {
+	var genres []domain.Genre
+
+	result := repo.db.WithContext(ctx).
+		Scopes(utils.CursorPaginate(genres, "genres", nil, pagination, repo.db), preloadTranslations(ctx)).
+		Find(&genres)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return utils.CursorPage(genres, pagination, repo.db)
+}
+
 func (repo *genreRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Genre, error) {
 	var genre domain.Genre
 
 	result := repo.db.WithContext(ctx).
 		Preload("Movies").
+		Scopes(preloadTranslations(ctx)).
 		Where("uuid = ?", uuid.String()).
 		First(&genre)
 	if result.Error != nil {
@@ -60,10 +79,29 @@ func (repo *genreRepository) FindByIDs(ctx context.Context, uuids []uuid.UUID) (
 	return genres, nil
 }
 
+func (repo *genreRepository) FindByNames(ctx context.Context, names []string) ([]domain.Genre, error) {
+	var genres []domain.Genre
+
+	lowered := make([]string, 0, len(names))
+	for _, name := range names {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+
+	result := database.Conn(ctx, repo.db).
+		Where("LOWER(name) IN ?", lowered).
+		Find(&genres)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return genres, nil
+}
+
 func (repo *genreRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Genre, error) {
 	var genre domain.Genre
 
-	result := repo.db.WithContext(ctx).
+	result := database.Conn(ctx, repo.db).
 		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("uuid = ?", uuid.String()).
 		First(&genre)
@@ -79,7 +117,7 @@ func (repo *genreRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UU
 }
 
 func (repo *genreRepository) Store(ctx context.Context, genre *domain.Genre) (domain.Genre, error) {
-	result := repo.db.WithContext(ctx).Clauses(clause.Returning{}).Omit("uuid").Create(&genre)
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Omit("uuid").Create(&genre)
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return domain.Genre{}, result.Error
@@ -89,7 +127,7 @@ func (repo *genreRepository) Store(ctx context.Context, genre *domain.Genre) (do
 }
 
 func (repo *genreRepository) Update(ctx context.Context, genre *domain.Genre) error {
-	result := repo.db.WithContext(ctx).Model(genre).Where("uuid = ?", genre.Uuid.String()).Updates(genre)
+	result := database.Conn(ctx, repo.db).Model(genre).Where("uuid = ?", genre.Uuid.String()).Updates(genre)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -98,7 +136,7 @@ func (repo *genreRepository) Update(ctx context.Context, genre *domain.Genre) er
 }
 
 func (repo *genreRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).Delete(&domain.Genre{})
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", uuid.String()).Delete(&domain.Genre{})
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
@@ -108,11 +146,79 @@ func (repo *genreRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) err
 }
 
 func (repo *genreRepository) Delete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.Genre{})
+	result := database.Conn(ctx, repo.db).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.Genre{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *genreRepository) FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Genre, error) {
+	var genre domain.Genre
+
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = ?", uuid.String()).
+		First(&genre)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Genre{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Genre{}, result.Error
+	}
+
+	return genre, nil
+}
+
+func (repo *genreRepository) FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]domain.Genre, error) {
+	var genres []domain.Genre
+
+	filtered := database.Conn(ctx, repo.db).Scopes(database.Trashed(deletedBefore))
+	result := database.Conn(ctx, repo.db).
+		Scopes(database.Trashed(deletedBefore), utils.Paginate(genres, pagination, filtered)).
+		Order("deleted_at DESC, id DESC").
+		Find(&genres)
 	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return genres, nil
+}
+
+func (repo *genreRepository) Restore(ctx context.Context, uuid uuid.UUID) error {
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Model(&domain.Genre{}).
+		Where("uuid = ? AND deleted_at IS NOT NULL", uuid.String()).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
 	}
 
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
 	return nil
 }
+
+// preloadTranslations loads the translations of the genres in the locales of the request
+func preloadTranslations(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		locales := utils.Locales(ctx)
+		if len(locales) == 0 {
+			return db
+		}
+
+		return db.Preload("Translations", "locale IN ?", locales)
+	}
+}
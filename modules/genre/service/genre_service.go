@@ -11,14 +11,20 @@ import (
 )
 
 type genreService struct {
-	genreRepo domain.GenreRepository
-	timeout   time.Duration
+	genreRepo           domain.GenreRepository
+	autocompleteService domain.AutocompleteService
+	revisionService     domain.RevisionService
+	transactor          domain.Transactor
+	timeout             time.Duration
 }
 
-func NewGenreService(genreRepo domain.GenreRepository, timeout time.Duration) domain.GenreService {
+func NewGenreService(genreRepo domain.GenreRepository, autocompleteService domain.AutocompleteService, revisionService domain.RevisionService, transactor domain.Transactor, timeout time.Duration) domain.GenreService {
 	return &genreService{
-		genreRepo: genreRepo,
-		timeout:   timeout,
+		genreRepo:           genreRepo,
+		autocompleteService: autocompleteService,
+		revisionService:     revisionService,
+		transactor:          transactor,
+		timeout:             timeout,
 	}
 }
 
@@ -35,6 +41,26 @@ func (service *genreService) FetchPagination(ctx context.Context, page int, perP
 		return nil, utils.Pagination{}, err
 	}
 
+	for i := range genres {
+		genres[i].Localize(utils.Locales(ctx))
+	}
+
+	return genres, pagination, nil
+}
+
+func (service *genreService) FetchCursor(ctx context.Context, pagination utils.CursorPagination) ([]domain.Genre, utils.CursorPagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	genres, err := service.genreRepo.FetchCursor(ctx, &pagination)
+	if err != nil {
+		return nil, utils.CursorPagination{}, err
+	}
+
+	for i := range genres {
+		genres[i].Localize(utils.Locales(ctx))
+	}
+
 	return genres, pagination, nil
 }
 
@@ -49,6 +75,7 @@ func (service *genreService) FindByID(ctx context.Context, uuid uuid.UUID) (doma
 		}
 		return domain.Genre{}, err
 	}
+	genre.Localize(utils.Locales(ctx))
 
 	return genre, nil
 }
@@ -57,64 +84,165 @@ func (service *genreService) Store(ctx context.Context, genre *domain.Genre) (do
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	result, err := service.genreRepo.Store(ctx, genre)
+	var result domain.Genre
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if result, err = service.genreRepo.Store(ctx, genre); err != nil {
+			return err
+		}
+
+		return service.revisionService.Record(ctx, domain.RevisionSubjectGenre, result.ID, domain.RevisionActionCreated)
+	})
 	if err != nil {
 		return domain.Genre{}, err
 	}
+	service.autocompleteService.Invalidate()
 
 	return result, nil
 }
 
-func (service *genreService) Update(ctx context.Context, genre *domain.Genre) error {
+func (service *genreService) Update(ctx context.Context, genre *domain.Genre, version uint) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.genreRepo.FindByIDForUpdate(ctx, genre.Uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.genreRepo.FindByIDForUpdate(ctx, genre.Uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errors.PreconditionFailedErr
+		}
+		genre.Version = existing.Version + 1
+
+		if err = service.genreRepo.Update(ctx, genre); err != nil {
+			return err
 		}
-		return err
-	}
 
-	if err := service.genreRepo.Update(ctx, genre); err != nil {
+		return service.revisionService.Record(ctx, domain.RevisionSubjectGenre, existing.ID, domain.RevisionActionUpdated)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
 
 	return nil
 }
 
-func (service *genreService) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+func (service *genreService) Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(genre *domain.Genre) error) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.genreRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.genreRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errors.PreconditionFailedErr
 		}
+
+		patched := existing
+		if err = apply(&patched); err != nil {
+			return err
+		}
+
+		err = service.genreRepo.Update(ctx, &domain.Genre{Uuid: existing.Uuid, Name: patched.Name, Version: existing.Version + 1})
+		if err != nil {
+			return err
+		}
+
+		return service.revisionService.Record(ctx, domain.RevisionSubjectGenre, existing.ID, domain.RevisionActionUpdated)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
+
+	return nil
+}
+
+func (service *genreService) SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
 
-	if err := service.genreRepo.SoftDelete(ctx, uuid); err != nil {
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		genre, err := service.genreRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && genre.Version != version {
+			return errors.PreconditionFailedErr
+		}
+
+		return service.genreRepo.SoftDelete(ctx, uuid)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
 
 	return nil
 }
 
-func (service *genreService) Delete(ctx context.Context, uuid uuid.UUID) error {
+func (service *genreService) Delete(ctx context.Context, uuid uuid.UUID, version uint) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.genreRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		genre, err := service.genreRepo.FindByIDWithTrashedForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if version != domain.AnyVersion && genre.Version != version {
+			return errors.PreconditionFailedErr
 		}
+
+		// the links to its movies and its translations go with it through the foreign keys
+		return service.genreRepo.Delete(ctx, uuid)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
+
+	return nil
+}
+
+func (service *genreService) FetchTrashed(ctx context.Context, page int, perPage int) ([]domain.Genre, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	genres, err := service.genreRepo.FetchTrashed(ctx, time.Time{}, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return genres, pagination, nil
+}
+
+// Restore brings the genre back on the movies it was linked to, which the soft delete left in place
+func (service *genreService) Restore(ctx context.Context, uuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
 
-	if err := service.genreRepo.Delete(ctx, uuid); err != nil {
+	if err := service.genreRepo.Restore(ctx, uuid); err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
 
 	return nil
 }
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+)
+
+// multipartOverhead leaves room for the multipart framing around the image in the request body limit
+const multipartOverhead = 64 * 1024
+
+type ImageController struct {
+	domain.ImageService
+}
+
+func NewImageController(router *echo.Echo, imageService domain.ImageService) {
+	controller := &ImageController{
+		ImageService: imageService,
+	}
+
+	bodyLimit := echoMiddleware.BodyLimit(fmt.Sprintf("%dB", configs.Env.Image.MaxUploadSize+multipartOverhead))
+
+	for _, kind := range []string{domain.ImageKindPoster, domain.ImageKindBackdrop} {
+		router.PUT("/movies/:uuid/"+kind, controller.upload(kind), middleware.AuthMiddleware.Handler, bodyLimit)
+		router.DELETE("/movies/:uuid/"+kind, controller.destroy(kind), middleware.AuthMiddleware.Handler)
+	}
+}
+
+// upload stores the multipart "image" file as the poster or backdrop of the movie
+func (controller *ImageController) upload(kind string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		id, err := uuid.Parse(ec.Param("uuid"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+		}
+
+		header, err := ec.FormFile("image")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "the image is required.")
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		data, err := controller.ImageService.Upload(ec.Request().Context(), id, kind, file)
+		if err != nil {
+			return err
+		}
+
+		return ec.JSON(http.StatusOK, data)
+	}
+}
+
+func (controller *ImageController) destroy(kind string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		id, err := uuid.Parse(ec.Param("uuid"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+		}
+
+		if err = controller.ImageService.Delete(ec.Request().Context(), id, kind); err != nil {
+			return err
+		}
+
+		return ec.JSON(http.StatusOK, response.DeleteSuccess)
+	}
+}
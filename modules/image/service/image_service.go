@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"time"
+)
+
+// thumbnailQuality is the JPEG quality of the generated thumbnails
+const thumbnailQuality = 85
+
+// allowedImageTypes are the sniffed content types an upload may have, the ones the standard library can decode
+var allowedImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+type imageService struct {
+	movieRepo  domain.MovieRepository
+	blobStore  domain.BlobStore
+	transactor domain.Transactor
+	timeout    time.Duration
+	urlExpiry  time.Duration
+}
+
+func NewImageService(movieRepo domain.MovieRepository, blobStore domain.BlobStore, transactor domain.Transactor, timeout time.Duration) domain.ImageService {
+	urlExpiry, _ := time.ParseDuration(configs.Env.Storage.SignedURLExpiration)
+
+	return &imageService{
+		movieRepo:  movieRepo,
+		blobStore:  blobStore,
+		transactor: transactor,
+		timeout:    timeout,
+		urlExpiry:  urlExpiry,
+	}
+}
+
+func (service *imageService) Upload(ctx context.Context, movieUuid uuid.UUID, kind string, body io.Reader) (domain.ImageURLs, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	if _, ok := domain.ImageSizes[kind]; !ok {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "The image kind is not valid.")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, configs.Env.Image.MaxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > configs.Env.Image.MaxUploadSize {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "The image is too large.")
+	}
+
+	// trust the content, not the file name or the declared type
+	contentType := mimetype.Detect(data)
+	if !mimetype.EqualsAny(contentType.String(), allowedImageTypes...) {
+		return nil, echo.NewHTTPError(http.StatusUnsupportedMediaType, "The image must be a JPEG, PNG or GIF.")
+	}
+
+	// check the dimensions before decoding, a small file can still expand into a huge bitmap
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "The image is not valid.")
+	}
+	if config.Width*config.Height > configs.Env.Image.MaxPixels {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "The image dimensions are too large.")
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "The image is not valid.")
+	}
+
+	// every upload gets a new prefix, so cached URLs of the previous image are never served the new one
+	key := fmt.Sprintf("%ss/%s/%s", kind, movieUuid, uuid.New())
+	if err = service.store(ctx, kind, key, data, contentType.String(), decoded); err != nil {
+		service.deleteBlobs(ctx, kind, key)
+		return nil, err
+	}
+
+	var previous string
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		movie, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+		if err != nil {
+			return err
+		}
+		previous = imageKey(movie, kind)
+
+		return service.movieRepo.UpdateImageKey(ctx, movie.ID, kind, key)
+	})
+	if err != nil {
+		service.deleteBlobs(ctx, kind, key)
+		return nil, err
+	}
+
+	if previous != "" {
+		service.deleteBlobs(ctx, kind, previous)
+	}
+
+	return service.urls(kind, key), nil
+}
+
+func (service *imageService) Delete(ctx context.Context, movieUuid uuid.UUID, kind string) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	if _, ok := domain.ImageSizes[kind]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "The image kind is not valid.")
+	}
+
+	var previous string
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		movie, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+		if err != nil {
+			return err
+		}
+
+		previous = imageKey(movie, kind)
+		if previous == "" {
+			return errors.NotFoundErr
+		}
+
+		return service.movieRepo.UpdateImageKey(ctx, movie.ID, kind, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	service.deleteBlobs(ctx, kind, previous)
+	return nil
+}
+
+func (service *imageService) Purge(ctx context.Context, movie domain.Movie) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	for kind := range domain.ImageSizes {
+		if key := imageKey(movie, kind); key != "" {
+			service.deleteBlobs(ctx, kind, key)
+		}
+	}
+}
+
+func (service *imageService) Resolve(movie *domain.Movie) {
+	if movie.PosterKey != "" {
+		movie.Poster = service.urls(domain.ImageKindPoster, movie.PosterKey)
+	}
+	if movie.BackdropKey != "" {
+		movie.Backdrop = service.urls(domain.ImageKindBackdrop, movie.BackdropKey)
+	}
+}
+
+// store puts the original upload and a JPEG thumbnail of every size of the kind under the key prefix
+func (service *imageService) store(ctx context.Context, kind string, key string, data []byte, contentType string, decoded image.Image) error {
+	err := service.blobStore.Put(ctx, blobKey(key, domain.ImageSizeOriginal), bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return err
+	}
+
+	flat := flatten(decoded)
+	for _, size := range domain.ImageSizes[kind] {
+		var buffer bytes.Buffer
+		if err = jpeg.Encode(&buffer, resize(flat, size.Width), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			return err
+		}
+
+		err = service.blobStore.Put(ctx, blobKey(key, size.Name), &buffer, int64(buffer.Len()), "image/jpeg")
+		if err != nil {
+			utils.Logger.Error(err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteBlobs removes the original and the thumbnails under the key prefix, failures only leave orphaned blobs behind so they are logged
+func (service *imageService) deleteBlobs(ctx context.Context, kind string, key string) {
+	names := []string{domain.ImageSizeOriginal}
+	for _, size := range domain.ImageSizes[kind] {
+		names = append(names, size.Name)
+	}
+
+	for _, name := range names {
+		if err := service.blobStore.Delete(ctx, blobKey(key, name)); err != nil {
+			utils.Logger.Error(err.Error())
+		}
+	}
+}
+
+// urls addresses every size of the image, with signed URLs when the store is private
+func (service *imageService) urls(kind string, key string) domain.ImageURLs {
+	urls := domain.ImageURLs{domain.ImageSizeOriginal: service.url(blobKey(key, domain.ImageSizeOriginal))}
+	for _, size := range domain.ImageSizes[kind] {
+		urls[size.Name] = service.url(blobKey(key, size.Name))
+	}
+
+	return urls
+}
+
+func (service *imageService) url(key string) string {
+	if !configs.Env.Storage.Private {
+		return service.blobStore.URL(key)
+	}
+
+	signed, err := service.blobStore.SignedURL(key, service.urlExpiry)
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return ""
+	}
+
+	return signed
+}
+
+func imageKey(movie domain.Movie, kind string) string {
+	if kind == domain.ImageKindBackdrop {
+		return movie.BackdropKey
+	}
+
+	return movie.PosterKey
+}
+
+// blobKey names the blob of one size of the image, thumbnails are always JPEG
+func blobKey(key string, size string) string {
+	if size == domain.ImageSizeOriginal {
+		return key + "/" + size
+	}
+
+	return key + "/" + size + ".jpg"
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// flatten copies img into an RGBA image over a white background, JPEG has no alpha channel to keep transparency in
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+	return flat
+}
+
+// resize scales src down to width keeping the aspect ratio, each target pixel averages the source pixels it covers.
+// Images narrower than width are returned unchanged, thumbnails are never upscaled.
+func resize(src *image.RGBA, width int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if width >= srcWidth {
+		return src
+	}
+
+	height := srcHeight * width / srcWidth
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, srcHeight)
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, srcWidth)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += uint32(pixel[0])
+					g += uint32(pixel[1])
+					b += uint32(pixel[2])
+					a += uint32(pixel[3])
+					n++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// span is the range of source pixels covered by target pixel i of a target with size pixels
+func span(i int, size int, srcSize int) (int, int) {
+	from := i * srcSize / size
+	to := (i + 1) * srcSize / size
+	if to <= from {
+		to = from + 1
+	}
+
+	return from, to
+}
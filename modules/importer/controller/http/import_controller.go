@@ -0,0 +1,136 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+)
+
+type ImportController struct {
+	domain.ImportService
+}
+
+func NewImportController(router *echo.Echo, importService domain.ImportService) {
+	controller := &ImportController{
+		ImportService: importService,
+	}
+
+	admin := []echo.MiddlewareFunc{middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler}
+
+	router.POST("/imports", controller.Import, admin...)
+	router.GET("/movies/external/:source/:id", controller.ShowByExternalID)
+	router.GET("/movies/:uuid/external-ids", controller.IndexExternalIDs)
+	router.POST("/movies/:uuid/external-ids", controller.StoreExternalID, admin...)
+	router.DELETE("/movies/:uuid/external-ids/:source", controller.DestroyExternalID, admin...)
+	router.GET("/movies/:uuid/provenance", controller.IndexProvenance)
+}
+
+// Import fetches a movie from an external catalog and merges it into the catalog here
+func (controller *ImportController) Import(ec echo.Context) error {
+	var request importRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	data, err := controller.ImportService.Import(ec.Request().Context(), request.Source, request.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	if data.Created {
+		return ec.JSON(http.StatusCreated, data)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *ImportController) ShowByExternalID(ec echo.Context) error {
+	data, err := controller.ImportService.FindByExternalID(ec.Request().Context(), ec.Param("source"), ec.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *ImportController) IndexExternalIDs(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.ImportService.FetchExternalIDs(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.ExternalID, 0)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *ImportController) StoreExternalID(ec echo.Context) error {
+	var request externalIDRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.ImportService.StoreExternalID(ec.Request().Context(), id, &domain.ExternalID{
+		Source:     request.Source,
+		ExternalID: request.ExternalID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+func (controller *ImportController) DestroyExternalID(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	if err = controller.ImportService.DeleteExternalID(ec.Request().Context(), id, ec.Param("source")); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+func (controller *ImportController) IndexProvenance(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.ImportService.FetchProvenance(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.FieldProvenance, 0)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
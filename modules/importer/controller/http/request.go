@@ -0,0 +1,11 @@
+package http
+
+type importRequest struct {
+	Source     string `json:"source" validate:"required,oneof=imdb tmdb wikidata custom"`
+	ExternalID string `json:"external_id" validate:"required,max=255"`
+}
+
+type externalIDRequest struct {
+	Source     string `json:"source" validate:"required,oneof=imdb tmdb wikidata custom"`
+	ExternalID string `json:"external_id" validate:"required,max=255"`
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+type externalIDRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalIDRepository(gormDB *gorm.DB) domain.ExternalIDRepository {
+	return &externalIDRepository{db: gormDB}
+}
+
+func (repo *externalIDRepository) FindMovieUuid(ctx context.Context, source string, externalID string) (uuid.UUID, error) {
+	var movies []struct {
+		Uuid      uuid.UUID
+		DeletedAt *time.Time
+	}
+
+	// trashed movies keep their ids, an import must not take them for unseen ones and create a duplicate
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.ExternalID{}).
+		Joins("JOIN movies ON movies.id = external_ids.movie_id").
+		Where("external_ids.source = ? AND external_ids.external_id = ?", source, externalID).
+		Limit(1).
+		Select("movies.uuid, movies.deleted_at").
+		Scan(&movies)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return uuid.Nil, result.Error
+	}
+
+	if len(movies) == 0 {
+		return uuid.Nil, helper.NotFoundErr
+	}
+
+	if movies[0].DeletedAt != nil {
+		return uuid.Nil, helper.NewLinkedError(helper.ConflictErr, fmt.Sprintf("/movies/%s/restore", movies[0].Uuid))
+	}
+
+	return movies[0].Uuid, nil
+}
+
+func (repo *externalIDRepository) Lock(ctx context.Context, externalIDs []domain.ExternalID) error {
+	keys := make([]string, 0, len(externalIDs))
+	for _, externalID := range externalIDs {
+		keys = append(keys, externalID.Source+":"+externalID.ExternalID)
+	}
+	// sorted, so that two transactions locking overlapping ids cannot wait on each other
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result := database.Conn(ctx, repo.db).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key)
+		if result.Error != nil {
+			utils.Logger.Error(result.Error.Error())
+			return result.Error
+		}
+	}
+
+	return nil
+}
+
+func (repo *externalIDRepository) FetchByMovie(ctx context.Context, movieID uint) ([]domain.ExternalID, error) {
+	var externalIDs []domain.ExternalID
+
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ?", movieID).
+		Order("source ASC").
+		Find(&externalIDs)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return externalIDs, nil
+}
+
+func (repo *externalIDRepository) Store(ctx context.Context, externalID *domain.ExternalID) (domain.ExternalID, error) {
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Create(externalID)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return domain.ExternalID{}, helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.ExternalID{}, result.Error
+	}
+
+	return *externalID, nil
+}
+
+func (repo *externalIDRepository) StoreMissing(ctx context.Context, externalIDs []domain.ExternalID) error {
+	if len(externalIDs) == 0 {
+		return nil
+	}
+
+	result := database.Conn(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&externalIDs)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *externalIDRepository) Delete(ctx context.Context, movieID uint, source string) error {
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ? AND source = ?", movieID, source).
+		Delete(&domain.ExternalID{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
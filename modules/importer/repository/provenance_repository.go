@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type provenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewProvenanceRepository(gormDB *gorm.DB) domain.ProvenanceRepository {
+	return &provenanceRepository{db: gormDB}
+}
+
+func (repo *provenanceRepository) FetchByMovie(ctx context.Context, movieID uint) ([]domain.FieldProvenance, error) {
+	var provenances []domain.FieldProvenance
+
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ?", movieID).
+		Order("field ASC").
+		Find(&provenances)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return provenances, nil
+}
+
+func (repo *provenanceRepository) Record(ctx context.Context, movieID uint, fields []string, source string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	provenances := make([]domain.FieldProvenance, 0, len(fields))
+	for _, field := range fields {
+		provenances = append(provenances, domain.FieldProvenance{
+			MovieID: movieID,
+			Field:   field,
+			Source:  source,
+		})
+	}
+
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "movie_id"}, {Name: "field"}},
+			DoUpdates: clause.AssignmentColumns([]string{"source", "updated_at"}),
+		}).
+		Create(&provenances)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type importService struct {
+	importers           map[string]domain.Importer
+	priority            map[string]int
+	externalIDRepo      domain.ExternalIDRepository
+	provenanceRepo      domain.ProvenanceRepository
+	movieRepo           domain.MovieRepository
+	movieService        domain.MovieService
+	revisionService     domain.RevisionService
+	autocompleteService domain.AutocompleteService
+	transactor          domain.Transactor
+	timeout             time.Duration
+}
+
+func NewImportService(importers []domain.Importer, externalIDRepo domain.ExternalIDRepository, provenanceRepo domain.ProvenanceRepository, movieRepo domain.MovieRepository, movieService domain.MovieService, revisionService domain.RevisionService, autocompleteService domain.AutocompleteService, transactor domain.Transactor, timeout time.Duration) domain.ImportService {
+	bySource := make(map[string]domain.Importer, len(importers))
+	for _, importer := range importers {
+		bySource[importer.Source()] = importer
+	}
+
+	priority := make(map[string]int, len(configs.Env.Import.Priority))
+	for i, source := range configs.Env.Import.Priority {
+		priority[source] = i
+	}
+
+	return &importService{
+		importers:           bySource,
+		priority:            priority,
+		externalIDRepo:      externalIDRepo,
+		provenanceRepo:      provenanceRepo,
+		movieRepo:           movieRepo,
+		movieService:        movieService,
+		revisionService:     revisionService,
+		autocompleteService: autocompleteService,
+		transactor:          transactor,
+		timeout:             timeout,
+	}
+}
+
+func (service *importService) Import(ctx context.Context, source string, externalID string) (domain.ImportResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	importer, ok := service.importers[source]
+	if !ok {
+		return domain.ImportResult{}, echo.NewHTTPError(http.StatusBadRequest, "The source is not available.")
+	}
+
+	// the catalog is read before the transaction so a slow upstream does not hold any lock
+	imported, err := importer.Fetch(ctx, externalID)
+	if err != nil {
+		if err == errors.NotFoundErr {
+			return domain.ImportResult{}, err
+		}
+		utils.Logger.Error(err.Error())
+		return domain.ImportResult{}, echo.NewHTTPError(http.StatusBadGateway, "The source could not be read.")
+	}
+	if strings.TrimSpace(imported.Title) == "" {
+		return domain.ImportResult{}, echo.NewHTTPError(http.StatusBadGateway, "The source returned a movie without a title.")
+	}
+
+	linked := make([]domain.ExternalID, 0, len(imported.ExternalIDs))
+	for _, other := range domain.ExternalSources {
+		if id := imported.ExternalIDs[other]; id != "" && other != imported.Source {
+			linked = append(linked, domain.ExternalID{Source: other, ExternalID: id})
+		}
+	}
+
+	var result domain.ImportResult
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		// imports of the same movie match or create it one after the other
+		own := domain.ExternalID{Source: imported.Source, ExternalID: imported.ExternalID}
+		if err := service.externalIDRepo.Lock(ctx, append([]domain.ExternalID{own}, linked...)); err != nil {
+			return err
+		}
+
+		movieUuid, matchedByOwn, err := service.match(ctx, imported)
+		if err != nil && err != errors.NotFoundErr {
+			return err
+		}
+
+		var movieID uint
+		if err == errors.NotFoundErr {
+			movie, err := service.movieRepo.Store(ctx, &domain.Movie{
+				Title:    imported.Title,
+				Year:     imported.Year,
+				Duration: imported.Duration,
+				Synopsis: imported.Synopsis,
+			})
+			if err != nil {
+				return err
+			}
+
+			movieID, movieUuid = movie.ID, movie.Uuid
+			result.Created = true
+			result.Fields = movie.ImportableFields()
+		} else {
+			movie, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+			if err != nil {
+				return err
+			}
+
+			provenances, err := service.provenanceRepo.FetchByMovie(ctx, movie.ID)
+			if err != nil {
+				return err
+			}
+
+			fields := service.merge(movie, imported, provenances)
+			if len(fields) > 0 {
+				fields["version"] = movie.Version + 1
+				if err = service.movieRepo.UpdateFields(ctx, movie.ID, fields); err != nil {
+					return err
+				}
+			}
+
+			movieID = movie.ID
+			for _, field := range domain.ImportableMovieFields {
+				if _, ok := fields[field]; ok {
+					result.Fields = append(result.Fields, field)
+				}
+			}
+		}
+
+		if !matchedByOwn {
+			own.MovieID = movieID
+			if _, err = service.externalIDRepo.Store(ctx, &own); err != nil {
+				return err
+			}
+		}
+
+		// a linked id another movie already has stays with it
+		externalIDs := make([]domain.ExternalID, 0, len(linked))
+		for _, externalID := range linked {
+			externalID.MovieID = movieID
+			externalIDs = append(externalIDs, externalID)
+		}
+		if err = service.externalIDRepo.StoreMissing(ctx, externalIDs); err != nil {
+			return err
+		}
+
+		if err = service.provenanceRepo.Record(ctx, movieID, result.Fields, imported.Source); err != nil {
+			return err
+		}
+
+		// a merge that kept every field as it was leaves the history alone
+		if len(result.Fields) > 0 {
+			if err = service.revisionService.Record(ctx, domain.RevisionSubjectMovie, movieID, domain.RevisionActionImported); err != nil {
+				return err
+			}
+		}
+
+		result.Movie.Uuid = movieUuid
+		return nil
+	})
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	if len(result.Fields) > 0 {
+		service.autocompleteService.Invalidate()
+	}
+	if result.Fields == nil {
+		result.Fields = []string{}
+	}
+
+	result.Movie, err = service.movieService.FindByID(ctx, result.Movie.Uuid)
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	return result, nil
+}
+
+// match finds the movie the imported one is, first by its own id and then by the ids it links to in other sources,
+// matchedByOwn tells whether its own id found it
+func (service *importService) match(ctx context.Context, imported domain.ImportedMovie) (movieUuid uuid.UUID, matchedByOwn bool, err error) {
+	movieUuid, err = service.externalIDRepo.FindMovieUuid(ctx, imported.Source, imported.ExternalID)
+	if err != errors.NotFoundErr {
+		return movieUuid, err == nil, err
+	}
+
+	for _, source := range domain.ExternalSources {
+		id := imported.ExternalIDs[source]
+		if id == "" || source == imported.Source {
+			continue
+		}
+
+		movieUuid, err = service.externalIDRepo.FindMovieUuid(ctx, source, id)
+		if err != errors.NotFoundErr {
+			return movieUuid, false, err
+		}
+	}
+
+	return uuid.Nil, false, errors.NotFoundErr
+}
+
+// merge returns the columns the imported movie may write. A field is written when it is empty, or when it was last
+// written by a source the import priority does not rank above the imported one. Manual edits are never overwritten,
+// and neither are values without provenance, as those were entered before imports kept track of it.
+func (service *importService) merge(movie domain.Movie, imported domain.ImportedMovie, provenances []domain.FieldProvenance) map[string]interface{} {
+	sources := make(map[string]string, len(provenances))
+	for _, provenance := range provenances {
+		sources[provenance.Field] = provenance.Source
+	}
+
+	current := map[string]interface{}{
+		domain.MovieFieldTitle:    movie.Title,
+		domain.MovieFieldYear:     movie.Year,
+		domain.MovieFieldDuration: movie.Duration,
+		domain.MovieFieldSynopsis: movie.Synopsis,
+	}
+	incoming := map[string]interface{}{
+		domain.MovieFieldTitle:    imported.Title,
+		domain.MovieFieldYear:     imported.Year,
+		domain.MovieFieldDuration: imported.Duration,
+		domain.MovieFieldSynopsis: imported.Synopsis,
+	}
+
+	fields := make(map[string]interface{})
+	for _, field := range domain.ImportableMovieFields {
+		if isZero(incoming[field]) {
+			continue
+		}
+
+		source, known := sources[field]
+		switch {
+		case isZero(current[field]):
+		case !known || source == domain.FieldSourceManual:
+			continue
+		case service.rank(imported.Source) > service.rank(source):
+			continue
+		}
+
+		fields[field] = incoming[field]
+	}
+
+	return fields
+}
+
+// rank is the position of the source in the import priority, sources that are not listed come last
+func (service *importService) rank(source string) int {
+	if rank, ok := service.priority[source]; ok {
+		return rank
+	}
+
+	return len(service.priority)
+}
+
+func (service *importService) FindByExternalID(ctx context.Context, source string, externalID string) (domain.Movie, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieUuid, err := service.externalIDRepo.FindMovieUuid(ctx, source, externalID)
+	if err != nil {
+		return domain.Movie{}, err
+	}
+
+	return service.movieService.FindByID(ctx, movieUuid)
+}
+
+func (service *importService) FetchExternalIDs(ctx context.Context, movieUuid uuid.UUID) ([]domain.ExternalID, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.movieID(ctx, movieUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.externalIDRepo.FetchByMovie(ctx, movieID)
+}
+
+func (service *importService) StoreExternalID(ctx context.Context, movieUuid uuid.UUID, externalID *domain.ExternalID) (domain.ExternalID, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.movieID(ctx, movieUuid)
+	if err != nil {
+		return domain.ExternalID{}, err
+	}
+	externalID.MovieID = movieID
+
+	return service.externalIDRepo.Store(ctx, externalID)
+}
+
+func (service *importService) DeleteExternalID(ctx context.Context, movieUuid uuid.UUID, source string) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.movieID(ctx, movieUuid)
+	if err != nil {
+		return err
+	}
+
+	return service.externalIDRepo.Delete(ctx, movieID, source)
+}
+
+func (service *importService) FetchProvenance(ctx context.Context, movieUuid uuid.UUID) ([]domain.FieldProvenance, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.movieID(ctx, movieUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.provenanceRepo.FetchByMovie(ctx, movieID)
+}
+
+func (service *importService) movieID(ctx context.Context, movieUuid uuid.UUID) (uint, error) {
+	movies, err := service.movieRepo.FindByIDs(ctx, []uuid.UUID{movieUuid})
+	if err != nil {
+		return 0, err
+	}
+	if len(movies) == 0 {
+		return 0, errors.NotFoundErr
+	}
+
+	return movies[0].ID, nil
+}
+
+func isZero(value interface{}) bool {
+	switch value := value.(type) {
+	case string:
+		return value == ""
+	case int32:
+		return value == 0
+	}
+
+	return value == nil
+}
@@ -0,0 +1,60 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"go-movie-api/domain"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CustomImporter reads movies from an in-house catalog serving GET {base_url}/movies/{id} in the customMovie format
+type CustomImporter struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewCustomImporter(client *http.Client, baseURL string) *CustomImporter {
+	return &CustomImporter{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+type customMovie struct {
+	Title       string            `json:"title"`
+	Year        int32             `json:"year"`
+	Duration    int32             `json:"duration"`
+	Synopsis    string            `json:"synopsis"`
+	ExternalIDs map[string]string `json:"external_ids"`
+}
+
+func (importer *CustomImporter) Source() string {
+	return domain.ExternalSourceCustom
+}
+
+func (importer *CustomImporter) Fetch(ctx context.Context, externalID string) (domain.ImportedMovie, error) {
+	var movie customMovie
+	err := getJSON(ctx, importer.client, fmt.Sprintf("%s/movies/%s", importer.baseURL, url.PathEscape(externalID)), &movie)
+	if err != nil {
+		return domain.ImportedMovie{}, err
+	}
+
+	externalIDs := make(map[string]string, len(movie.ExternalIDs))
+	for source, id := range movie.ExternalIDs {
+		if source != domain.ExternalSourceCustom && id != "" {
+			externalIDs[source] = id
+		}
+	}
+
+	return domain.ImportedMovie{
+		Source:      domain.ExternalSourceCustom,
+		ExternalID:  externalID,
+		Title:       movie.Title,
+		Year:        movie.Year,
+		Duration:    movie.Duration,
+		Synopsis:    movie.Synopsis,
+		ExternalIDs: externalIDs,
+	}, nil
+}
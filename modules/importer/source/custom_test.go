@@ -0,0 +1,60 @@
+package source
+
+import (
+	"context"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"net/http"
+	"testing"
+)
+
+func TestCustomImporterFetch(t *testing.T) {
+	server := newCatalog(t, "/movies/mx 1", http.StatusOK, `{
+		"title": "The Matrix",
+		"year": 1999,
+		"duration": 136,
+		"synopsis": "Set in the 22nd century.",
+		"external_ids": {"imdb": "tt0133093", "custom": "other", "tmdb": ""}
+	}`)
+
+	movie, err := NewCustomImporter(server.Client(), server.URL+"/").Fetch(context.Background(), "mx 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "movie", movie, domain.ImportedMovie{
+		Source:      domain.ExternalSourceCustom,
+		ExternalID:  "mx 1",
+		Title:       "The Matrix",
+		Year:        1999,
+		Duration:    136,
+		Synopsis:    "Set in the 22nd century.",
+		ExternalIDs: map[string]string{domain.ExternalSourceIMDb: "tt0133093"},
+	})
+}
+
+func TestCustomImporterFetchNotFound(t *testing.T) {
+	server := newCatalog(t, "/movies/1", http.StatusOK, `{}`)
+	importer := NewCustomImporter(server.Client(), server.URL)
+
+	for _, id := range []string{"2", "1/extra"} {
+		if _, err := importer.Fetch(context.Background(), id); err != helper.NotFoundErr {
+			t.Errorf("Fetch(%q) error = %v, want NotFoundErr", id, err)
+		}
+	}
+}
+
+func TestCustomImporterFetchMalformed(t *testing.T) {
+	for name, body := range map[string]string{
+		"not json":              `<html>`,
+		"year mistyped":         `{"year": "1999"}`,
+		"external ids mistyped": `{"external_ids": ["tt0133093"]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newCatalog(t, "/movies/1", http.StatusOK, body)
+			if _, err := NewCustomImporter(server.Client(), server.URL).Fetch(context.Background(), "1"); err == nil {
+				t.Error("Fetch returned no error")
+			}
+		})
+	}
+}
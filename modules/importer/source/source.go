@@ -0,0 +1,80 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxResponseSize bounds the body read from a catalog
+const maxResponseSize = 4 << 20
+
+// NewImporters creates an importer for every source configured in configs.Env.Import, sources without settings are left out
+func NewImporters() []domain.Importer {
+	config := configs.Env.Import
+
+	timeout, err := time.ParseDuration(config.Timeout)
+	if err != nil {
+		timeout = 10 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	var importers []domain.Importer
+	if config.TMDb.BaseURL != "" && config.TMDb.APIKey != "" {
+		importers = append(importers, NewTMDbImporter(client, config.TMDb.BaseURL, config.TMDb.APIKey))
+	}
+	if config.Wikidata.BaseURL != "" {
+		importers = append(importers, NewWikidataImporter(client, config.Wikidata.BaseURL))
+	}
+	if config.Custom.BaseURL != "" {
+		importers = append(importers, NewCustomImporter(client, config.Custom.BaseURL))
+	}
+
+	return importers
+}
+
+// getJSON decodes the JSON body of a GET of url into target, a 404 is NotFoundErr
+func getJSON(ctx context.Context, client *http.Client, url string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return helper.NotFoundErr
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(target)
+}
+
+// parseYear reads the year of a date starting with YYYY, such as "1999-03-31" or "+1999-03-31T00:00:00Z"
+func parseYear(date string) int32 {
+	date = strings.TrimPrefix(date, "+")
+	if len(date) < 4 {
+		return 0
+	}
+
+	var year int32
+	if _, err := fmt.Sscanf(date[:4], "%d", &year); err != nil {
+		return 0
+	}
+
+	return year
+}
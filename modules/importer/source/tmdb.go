@@ -0,0 +1,77 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TMDbImporter reads movies from The Movie Database API v3
+type TMDbImporter struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewTMDbImporter(client *http.Client, baseURL string, apiKey string) *TMDbImporter {
+	return &TMDbImporter{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
+
+type tmdbMovie struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Overview    string `json:"overview"`
+	Runtime     int32  `json:"runtime"`
+	ReleaseDate string `json:"release_date"`
+	ExternalIDs struct {
+		IMDbID     string `json:"imdb_id"`
+		WikidataID string `json:"wikidata_id"`
+	} `json:"external_ids"`
+}
+
+func (importer *TMDbImporter) Source() string {
+	return domain.ExternalSourceTMDb
+}
+
+func (importer *TMDbImporter) Fetch(ctx context.Context, externalID string) (domain.ImportedMovie, error) {
+	if _, err := strconv.ParseUint(externalID, 10, 64); err != nil {
+		return domain.ImportedMovie{}, helper.NotFoundErr
+	}
+
+	query := url.Values{}
+	query.Set("api_key", importer.apiKey)
+	query.Set("append_to_response", "external_ids")
+
+	var movie tmdbMovie
+	err := getJSON(ctx, importer.client, fmt.Sprintf("%s/movie/%s?%s", importer.baseURL, externalID, query.Encode()), &movie)
+	if err != nil {
+		return domain.ImportedMovie{}, err
+	}
+
+	imported := domain.ImportedMovie{
+		Source:      domain.ExternalSourceTMDb,
+		ExternalID:  externalID,
+		Title:       movie.Title,
+		Year:        parseYear(movie.ReleaseDate),
+		Duration:    movie.Runtime,
+		Synopsis:    movie.Overview,
+		ExternalIDs: map[string]string{},
+	}
+	if movie.ExternalIDs.IMDbID != "" {
+		imported.ExternalIDs[domain.ExternalSourceIMDb] = movie.ExternalIDs.IMDbID
+	}
+	if movie.ExternalIDs.WikidataID != "" {
+		imported.ExternalIDs[domain.ExternalSourceWikidata] = movie.ExternalIDs.WikidataID
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,83 @@
+package source
+
+import (
+	"context"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTMDbImporterFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/movie/603" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("api_key") != "key" || r.URL.Query().Get("append_to_response") != "external_ids" {
+			t.Errorf("query = %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{
+			"id": 603,
+			"title": "The Matrix",
+			"overview": "Set in the 22nd century.",
+			"runtime": 136,
+			"release_date": "1999-03-30",
+			"external_ids": {"imdb_id": "tt0133093", "wikidata_id": "Q83495"}
+		}`))
+	}))
+	defer server.Close()
+
+	movie, err := NewTMDbImporter(server.Client(), server.URL+"/", "key").Fetch(context.Background(), "603")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "movie", movie, domain.ImportedMovie{
+		Source:     domain.ExternalSourceTMDb,
+		ExternalID: "603",
+		Title:      "The Matrix",
+		Year:       1999,
+		Duration:   136,
+		Synopsis:   "Set in the 22nd century.",
+		ExternalIDs: map[string]string{
+			domain.ExternalSourceIMDb:     "tt0133093",
+			domain.ExternalSourceWikidata: "Q83495",
+		},
+	})
+}
+
+func TestTMDbImporterFetchNotFound(t *testing.T) {
+	server := newCatalog(t, "/movie/603", http.StatusOK, `{}`)
+	importer := NewTMDbImporter(server.Client(), server.URL, "key")
+
+	for _, id := range []string{"604", "abc", "603/../604"} {
+		if _, err := importer.Fetch(context.Background(), id); err != helper.NotFoundErr {
+			t.Errorf("Fetch(%q) error = %v, want NotFoundErr", id, err)
+		}
+	}
+}
+
+func TestTMDbImporterFetchMalformed(t *testing.T) {
+	for name, body := range map[string]string{
+		"not json":   `<html>`,
+		"wrong type": `{"title": 1}`,
+		"truncated":  `{"title": "The Matrix"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newCatalog(t, "/movie/603", http.StatusOK, body)
+			if _, err := NewTMDbImporter(server.Client(), server.URL, "key").Fetch(context.Background(), "603"); err == nil {
+				t.Error("Fetch returned no error")
+			}
+		})
+	}
+}
+
+func TestTMDbImporterFetchUpstreamError(t *testing.T) {
+	server := newCatalog(t, "/movie/603", http.StatusInternalServerError, `{}`)
+	_, err := NewTMDbImporter(server.Client(), server.URL, "key").Fetch(context.Background(), "603")
+	if err == nil || err == helper.NotFoundErr {
+		t.Errorf("Fetch error = %v, want the upstream status", err)
+	}
+}
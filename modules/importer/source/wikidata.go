@@ -0,0 +1,135 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Wikidata properties read from a film item
+const (
+	wikidataPublicationDate = "P577"
+	wikidataDuration        = "P2047"
+	wikidataIMDbID          = "P345"
+	wikidataTMDbID          = "P4947"
+	// wikidataMinute is the unit item of durations given in minutes
+	wikidataMinute        = "http://www.wikidata.org/entity/Q7727"
+	wikidataLabelLanguage = "en"
+)
+
+var wikidataItemPattern = regexp.MustCompile(`^Q[1-9][0-9]*$`)
+
+// WikidataImporter reads film items from the Wikidata entity data endpoint
+type WikidataImporter struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewWikidataImporter(client *http.Client, baseURL string) *WikidataImporter {
+	return &WikidataImporter{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+type wikidataEntity struct {
+	Labels       map[string]wikidataText    `json:"labels"`
+	Descriptions map[string]wikidataText    `json:"descriptions"`
+	Claims       map[string][]wikidataClaim `json:"claims"`
+}
+
+type wikidataText struct {
+	Value string `json:"value"`
+}
+
+type wikidataClaim struct {
+	Rank     string `json:"rank"`
+	MainSnak struct {
+		DataValue struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"datavalue"`
+	} `json:"mainsnak"`
+}
+
+func (importer *WikidataImporter) Source() string {
+	return domain.ExternalSourceWikidata
+}
+
+func (importer *WikidataImporter) Fetch(ctx context.Context, externalID string) (domain.ImportedMovie, error) {
+	if !wikidataItemPattern.MatchString(externalID) {
+		return domain.ImportedMovie{}, helper.NotFoundErr
+	}
+
+	var data struct {
+		Entities map[string]wikidataEntity `json:"entities"`
+	}
+	err := getJSON(ctx, importer.client, fmt.Sprintf("%s/wiki/Special:EntityData/%s.json", importer.baseURL, externalID), &data)
+	if err != nil {
+		return domain.ImportedMovie{}, err
+	}
+
+	entity, ok := data.Entities[externalID]
+	if !ok {
+		return domain.ImportedMovie{}, helper.NotFoundErr
+	}
+
+	imported := domain.ImportedMovie{
+		Source:      domain.ExternalSourceWikidata,
+		ExternalID:  externalID,
+		Title:       entity.Labels[wikidataLabelLanguage].Value,
+		Synopsis:    entity.Descriptions[wikidataLabelLanguage].Value,
+		ExternalIDs: map[string]string{},
+	}
+
+	var date struct {
+		Time string `json:"time"`
+	}
+	if entity.value(wikidataPublicationDate, &date) {
+		imported.Year = parseYear(date.Time)
+	}
+
+	var duration struct {
+		Amount string `json:"amount"`
+		Unit   string `json:"unit"`
+	}
+	if entity.value(wikidataDuration, &duration) && duration.Unit == wikidataMinute {
+		if minutes, err := strconv.ParseFloat(duration.Amount, 64); err == nil {
+			imported.Duration = int32(math.Round(minutes))
+		}
+	}
+
+	var id string
+	if entity.value(wikidataIMDbID, &id) {
+		imported.ExternalIDs[domain.ExternalSourceIMDb] = id
+	}
+	if entity.value(wikidataTMDbID, &id) {
+		imported.ExternalIDs[domain.ExternalSourceTMDb] = id
+	}
+
+	return imported, nil
+}
+
+// value decodes the value of the preferred claim of the property, or of its first normal one
+func (entity wikidataEntity) value(property string, target interface{}) bool {
+	claims := entity.Claims[property]
+	if len(claims) == 0 {
+		return false
+	}
+
+	claim := claims[0]
+	for _, candidate := range claims {
+		if candidate.Rank == "preferred" {
+			claim = candidate
+			break
+		}
+	}
+
+	return json.Unmarshal(claim.MainSnak.DataValue.Value, target) == nil
+}
@@ -0,0 +1,109 @@
+package source
+
+import (
+	"context"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"net/http"
+	"testing"
+)
+
+const wikidataMatrix = `{
+	"entities": {
+		"Q83495": {
+			"labels": {"en": {"value": "The Matrix"}, "fr": {"value": "Matrix"}},
+			"descriptions": {"en": {"value": "1999 film by the Wachowskis"}},
+			"claims": {
+				"P577": [
+					{"rank": "normal", "mainsnak": {"datavalue": {"value": {"time": "+1999-06-23T00:00:00Z"}}}},
+					{"rank": "preferred", "mainsnak": {"datavalue": {"value": {"time": "+1999-03-31T00:00:00Z"}}}}
+				],
+				"P2047": [
+					{"rank": "normal", "mainsnak": {"datavalue": {"value": {"amount": "+136.4", "unit": "http://www.wikidata.org/entity/Q7727"}}}}
+				],
+				"P345": [{"rank": "normal", "mainsnak": {"datavalue": {"value": "tt0133093"}}}],
+				"P4947": [{"rank": "normal", "mainsnak": {"datavalue": {"value": "603"}}}]
+			}
+		}
+	}
+}`
+
+func TestWikidataImporterFetch(t *testing.T) {
+	server := newCatalog(t, "/wiki/Special:EntityData/Q83495.json", http.StatusOK, wikidataMatrix)
+
+	movie, err := NewWikidataImporter(server.Client(), server.URL).Fetch(context.Background(), "Q83495")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "movie", movie, domain.ImportedMovie{
+		Source:     domain.ExternalSourceWikidata,
+		ExternalID: "Q83495",
+		Title:      "The Matrix",
+		Year:       1999,
+		Duration:   136,
+		Synopsis:   "1999 film by the Wachowskis",
+		ExternalIDs: map[string]string{
+			domain.ExternalSourceIMDb: "tt0133093",
+			domain.ExternalSourceTMDb: "603",
+		},
+	})
+}
+
+func TestWikidataImporterFetchDurationInOtherUnit(t *testing.T) {
+	server := newCatalog(t, "/wiki/Special:EntityData/Q1.json", http.StatusOK, `{"entities": {"Q1": {"claims": {
+		"P2047": [{"rank": "normal", "mainsnak": {"datavalue": {"value": {"amount": "+2", "unit": "http://www.wikidata.org/entity/Q25235"}}}}]
+	}}}}`)
+
+	movie, err := NewWikidataImporter(server.Client(), server.URL).Fetch(context.Background(), "Q1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "duration", movie.Duration, int32(0))
+}
+
+func TestWikidataImporterFetchNotFound(t *testing.T) {
+	// an item that redirects is served under the id it was merged into
+	server := newCatalog(t, "/wiki/Special:EntityData/Q83495.json", http.StatusOK, `{"entities": {"Q1": {}}}`)
+	importer := NewWikidataImporter(server.Client(), server.URL)
+
+	for _, id := range []string{"Q83495", "Q2", "P345", "Q0", "q83495"} {
+		if _, err := importer.Fetch(context.Background(), id); err != helper.NotFoundErr {
+			t.Errorf("Fetch(%q) error = %v, want NotFoundErr", id, err)
+		}
+	}
+}
+
+func TestWikidataImporterFetchMalformed(t *testing.T) {
+	for name, body := range map[string]string{
+		"not json":        `<html>`,
+		"entities array":  `{"entities": []}`,
+		"labels mistyped": `{"entities": {"Q1": {"labels": {"en": "The Matrix"}}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newCatalog(t, "/wiki/Special:EntityData/Q1.json", http.StatusOK, body)
+			if _, err := NewWikidataImporter(server.Client(), server.URL).Fetch(context.Background(), "Q1"); err == nil {
+				t.Error("Fetch returned no error")
+			}
+		})
+	}
+}
+
+func TestWikidataImporterFetchIgnoresMalformedClaims(t *testing.T) {
+	server := newCatalog(t, "/wiki/Special:EntityData/Q1.json", http.StatusOK, `{"entities": {"Q1": {
+		"labels": {"en": {"value": "The Matrix"}},
+		"claims": {
+			"P577": [{"rank": "normal", "mainsnak": {"datavalue": {"value": 1999}}}],
+			"P345": [{"rank": "normal", "mainsnak": {"datavalue": {"value": {"id": "tt0133093"}}}}]
+		}
+	}}}`)
+
+	movie, err := NewWikidataImporter(server.Client(), server.URL).Fetch(context.Background(), "Q1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "year", movie.Year, int32(0))
+	assertEqual(t, "external ids", movie.ExternalIDs, map[string]string{})
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// multipartOverhead leaves room for the multipart framing and form fields around the file in the request body limit
+const multipartOverhead = 64 * 1024
+
+type ImportJobController struct {
+	domain.ImportJobService
+}
+
+func NewImportJobController(router *echo.Echo, importJobService domain.ImportJobService) {
+	controller := &ImportJobController{
+		ImportJobService: importJobService,
+	}
+
+	admin := []echo.MiddlewareFunc{middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler}
+	bodyLimit := echoMiddleware.BodyLimit(fmt.Sprintf("%dB", configs.Env.Import.Bulk.MaxFileSize+multipartOverhead))
+
+	router.POST("/imports/bulk", controller.Store, append(admin, bodyLimit)...)
+	router.GET("/imports/:uuid", controller.Show, admin...)
+	router.GET("/imports/:uuid/errors", controller.IndexErrors, admin...)
+}
+
+// Store starts a job importing the multipart "file", the job runs in the background and is polled with Show
+func (controller *ImportJobController) Store(ec echo.Context) error {
+	var request storeRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	header, err := ec.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "the file is required.")
+	}
+	if header.Size > configs.Env.Import.Bulk.MaxFileSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "the file is too large.")
+	}
+
+	format := request.Format
+	if format == "" {
+		format = domain.ImportFormatOf(header.Filename)
+	}
+	if format == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "the format is not known, use a .csv, .json or .ndjson file or give the format.")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+	job, err := controller.ImportJobService.Start(ec.Request().Context(), &domain.ImportJob{
+		UserID: &authUser.ID,
+		Kind:   request.Kind,
+		Format: format,
+		DryRun: request.DryRun,
+	}, data)
+	if err != nil {
+		return err
+	}
+
+	ec.Response().Header().Set(echo.HeaderLocation, "/imports/"+job.Uuid.String())
+	return ec.JSON(http.StatusAccepted, job)
+}
+
+func (controller *ImportJobController) Show(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.ImportJobService.FindByID(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+// IndexErrors lists why rows of the job failed, in file order
+func (controller *ImportJobController) IndexErrors(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.ImportJobService.FetchErrors(ec.Request().Context(), id, page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.ImportRowError, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
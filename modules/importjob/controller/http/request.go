@@ -0,0 +1,7 @@
+package http
+
+type storeRequest struct {
+	Kind   string `form:"kind" validate:"required,oneof=movies genres ratings"`
+	Format string `form:"format" validate:"omitempty,oneof=csv json ndjson"`
+	DryRun bool   `form:"dry_run" validate:"omitempty"`
+}
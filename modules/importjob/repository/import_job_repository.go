@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(gormDB *gorm.DB) domain.ImportJobRepository {
+	return &importJobRepository{db: gormDB}
+}
+
+func (repo *importJobRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.ImportJob, error) {
+	var job domain.ImportJob
+
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", uuid.String()).First(&job)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.ImportJob{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.ImportJob{}, result.Error
+	}
+
+	return job, nil
+}
+
+func (repo *importJobRepository) Store(ctx context.Context, job *domain.ImportJob) (domain.ImportJob, error) {
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Omit("uuid").Create(job)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return domain.ImportJob{}, result.Error
+	}
+
+	return *job, nil
+}
+
+func (repo *importJobRepository) UpdateProgress(ctx context.Context, job *domain.ImportJob) error {
+	result := database.Conn(ctx, repo.db).
+		Model(job).
+		Select("status", "total_rows", "processed_rows", "succeeded_rows", "failed_rows", "error", "started_at", "finished_at").
+		Updates(job)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *importJobRepository) FailUnfinished(ctx context.Context, message string) (int64, error) {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.ImportJob{}).
+		Where("status IN ?", []string{domain.ImportJobStatusPending, domain.ImportJobStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      domain.ImportJobStatusFailed,
+			"error":       message,
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *importJobRepository) StoreErrors(ctx context.Context, rowErrors []domain.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+
+	result := database.Conn(ctx, repo.db).CreateInBatches(rowErrors, 500)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *importJobRepository) FetchErrors(ctx context.Context, jobID uint, pagination *utils.Pagination) ([]domain.ImportRowError, error) {
+	var rowErrors []domain.ImportRowError
+
+	filtered := database.Conn(ctx, repo.db).Where("import_job_id = ?", jobID)
+	result := database.Conn(ctx, repo.db).
+		Where("import_job_id = ?", jobID).
+		Scopes(utils.Paginate(rowErrors, pagination, filtered)).
+		Order("row_number ASC").
+		Order("id ASC").
+		Find(&rowErrors)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return rowErrors, nil
+}
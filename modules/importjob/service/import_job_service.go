@@ -0,0 +1,621 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// defaultBatchSize is used when import.bulk.batch_size is not configured
+const defaultBatchSize = 500
+
+// movieRow follows the rules of the movie storeRequest, genres are given by name or id
+type movieRow struct {
+	Title    string   `json:"title" validate:"required"`
+	Duration int32    `json:"duration" validate:"required"`
+	Year     int32    `json:"year" validate:"required"`
+	Synopsis string   `json:"synopsis" validate:"required"`
+	Genres   []string `json:"genres" validate:"required,min=1"`
+}
+
+type genreRow struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// ratingRow rates a movie, given by id, for a user, given by id, username or email
+type ratingRow struct {
+	Movie   string   `json:"movie" validate:"required,uuid"`
+	User    string   `json:"user" validate:"required"`
+	Rating  *float32 `json:"rating" validate:"required,rating_scale"`
+	Comment string   `json:"comment"`
+}
+
+// importState carries what a job has seen across its batches, so a file cannot repeat a genre or a rating
+type importState struct {
+	// rows maps the key of a genre or rating to the row that first had it
+	rows map[string]int32
+	// users caches user references to ids, 0 for references that match no user
+	users map[string]uint
+}
+
+type importJobService struct {
+	jobRepo             domain.ImportJobRepository
+	genreRepo           domain.GenreRepository
+	movieRepo           domain.MovieRepository
+	userRepo            domain.UserRepository
+	ratingRepo          domain.RatingRepository
+	provenanceRepo      domain.ProvenanceRepository
+	revisionService     domain.RevisionService
+	autocompleteService domain.AutocompleteService
+	moderation          domain.ModerationService
+	transactor          domain.Transactor
+	validate            *validator.Validate
+	timeout             time.Duration
+}
+
+func NewImportJobService(jobRepo domain.ImportJobRepository, genreRepo domain.GenreRepository, movieRepo domain.MovieRepository, userRepo domain.UserRepository, ratingRepo domain.RatingRepository, provenanceRepo domain.ProvenanceRepository, revisionService domain.RevisionService, autocompleteService domain.AutocompleteService, moderation domain.ModerationService, transactor domain.Transactor, validate *validator.Validate, timeout time.Duration) domain.ImportJobService {
+	return &importJobService{
+		jobRepo:             jobRepo,
+		genreRepo:           genreRepo,
+		movieRepo:           movieRepo,
+		userRepo:            userRepo,
+		ratingRepo:          ratingRepo,
+		provenanceRepo:      provenanceRepo,
+		revisionService:     revisionService,
+		autocompleteService: autocompleteService,
+		moderation:          moderation,
+		transactor:          transactor,
+		validate:            validate,
+		timeout:             timeout,
+	}
+}
+
+func (service *importJobService) Start(ctx context.Context, job *domain.ImportJob, file []byte) (domain.ImportJob, error) {
+	records, stored, err := service.store(ctx, job, file)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	// the job outlives the request that uploaded the file
+	go service.process(context.Background(), stored, records)
+
+	return stored, nil
+}
+
+func (service *importJobService) Run(ctx context.Context, job *domain.ImportJob, file []byte) (domain.ImportJob, error) {
+	records, stored, err := service.store(ctx, job, file)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	return service.process(ctx, stored, records), nil
+}
+
+func (service *importJobService) FailInterrupted(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	failed, err := service.jobRepo.FailUnfinished(ctx, "the job was interrupted by a restart")
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		utils.Logger.Warn(fmt.Sprintf("failed %d interrupted import jobs", failed))
+	}
+
+	return nil
+}
+
+func (service *importJobService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.jobRepo.FindByID(ctx, uuid)
+}
+
+func (service *importJobService) FetchErrors(ctx context.Context, uuid uuid.UUID, page int, perPage int) ([]domain.ImportRowError, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	job, err := service.jobRepo.FindByID(ctx, uuid)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	rowErrors, err := service.jobRepo.FetchErrors(ctx, job.ID, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return rowErrors, pagination, nil
+}
+
+// store parses the file and stores the pending job, a file that cannot be parsed is rejected before any job exists
+func (service *importJobService) store(ctx context.Context, job *domain.ImportJob, file []byte) ([]record, domain.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	records, err := parseRecords(job.Format, file)
+	if err != nil {
+		return nil, domain.ImportJob{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The file is not valid %s: %s", strings.ToUpper(job.Format), err))
+	}
+
+	job.Status = domain.ImportJobStatusPending
+	job.TotalRows = int32(len(records))
+	stored, err := service.jobRepo.Store(ctx, job)
+	if err != nil {
+		return nil, domain.ImportJob{}, err
+	}
+
+	return records, stored, nil
+}
+
+// process imports the records batch by batch, recording progress and row errors after each batch. A panic fails
+// the job instead of leaving it running, and of taking the server down when the job runs in the background.
+func (service *importJobService) process(ctx context.Context, job domain.ImportJob, records []record) (result domain.ImportJob) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			utils.Logger.Error(fmt.Sprintf("import job %s panicked: %v\n%s", job.Uuid, recovered, debug.Stack()))
+
+			finishedAt := time.Now()
+			job.Status = domain.ImportJobStatusFailed
+			job.Error = "the job stopped on an internal error"
+			job.FinishedAt = &finishedAt
+			service.saveProgress(ctx, &job, nil)
+			result = job
+		}
+	}()
+
+	batchSize := configs.Env.Import.Bulk.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	// the rows are written on behalf of the admin who uploaded the file
+	if job.UserID != nil {
+		ctx = utils.ContextWithUserID(ctx, *job.UserID)
+	}
+
+	startedAt := time.Now()
+	job.Status = domain.ImportJobStatusRunning
+	job.StartedAt = &startedAt
+	service.saveProgress(ctx, &job, nil)
+
+	state := importState{rows: map[string]int32{}, users: map[string]uint{}}
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		succeeded, rowErrors, err := service.importBatch(ctx, &job, &state, records[start:end])
+		if err != nil {
+			job.Status = domain.ImportJobStatusFailed
+			job.Error = err.Error()
+			break
+		}
+
+		job.ProcessedRows += int32(end - start)
+		job.SucceededRows += succeeded
+		job.FailedRows += int32(len(rowErrors))
+		service.saveProgress(ctx, &job, rowErrors)
+	}
+
+	if job.Status == domain.ImportJobStatusRunning {
+		job.Status = domain.ImportJobStatusCompleted
+	}
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	service.saveProgress(ctx, &job, nil)
+
+	if !job.DryRun && job.SucceededRows > 0 && job.Kind != domain.ImportKindRatings {
+		service.autocompleteService.Invalidate()
+	}
+
+	return job
+}
+
+// saveProgress writes the job and its new row errors, failures are logged by the repository and do not stop the job
+func (service *importJobService) saveProgress(ctx context.Context, job *domain.ImportJob, rowErrors []domain.ImportRowError) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	for i := range rowErrors {
+		rowErrors[i].ImportJobID = job.ID
+	}
+	_ = service.jobRepo.StoreErrors(ctx, rowErrors)
+	_ = service.jobRepo.UpdateProgress(ctx, job)
+}
+
+// importBatch validates the records and inserts the valid ones in one transaction, it returns how many rows
+// succeeded and why the others failed. An error stops the job, no row of the batch is inserted then.
+func (service *importJobService) importBatch(ctx context.Context, job *domain.ImportJob, state *importState, records []record) (int32, []domain.ImportRowError, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	switch job.Kind {
+	case domain.ImportKindMovies:
+		return service.importMovies(ctx, job.DryRun, records)
+	case domain.ImportKindGenres:
+		return service.importGenres(ctx, job.DryRun, state, records)
+	case domain.ImportKindRatings:
+		return service.importRatings(ctx, job.DryRun, state, records)
+	}
+
+	return 0, nil, fmt.Errorf("unknown kind %q", job.Kind)
+}
+
+func (service *importJobService) importMovies(ctx context.Context, dryRun bool, records []record) (int32, []domain.ImportRowError, error) {
+	var rowErrors []domain.ImportRowError
+	var rows []movieRow
+	var numbers []int32
+	for _, record := range records {
+		var row movieRow
+		if message := service.decode(record, &row); message != "" {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: record.number, Message: message})
+			continue
+		}
+		rows = append(rows, row)
+		numbers = append(numbers, record.number)
+	}
+
+	var references []string
+	for _, row := range rows {
+		references = append(references, row.Genres...)
+	}
+	genres, err := service.resolveGenres(ctx, references)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var movies []domain.Movie
+	for i, row := range rows {
+		movie := domain.Movie{
+			Title:    row.Title,
+			Duration: row.Duration,
+			Year:     row.Year,
+			Synopsis: row.Synopsis,
+		}
+
+		seen := make(map[uint]bool, len(row.Genres))
+		var message string
+		for _, reference := range row.Genres {
+			matches := genres[genreKey(reference)]
+			if len(matches) == 0 {
+				message = fmt.Sprintf("the genre %q does not exist", reference)
+				break
+			}
+			if len(matches) > 1 {
+				message = fmt.Sprintf("the genre name %q matches several genres, use its id", reference)
+				break
+			}
+			if !seen[matches[0].ID] {
+				seen[matches[0].ID] = true
+				movie.Genres = append(movie.Genres, matches[0])
+			}
+		}
+		if message != "" {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: message})
+			continue
+		}
+
+		movies = append(movies, movie)
+	}
+
+	if dryRun || len(movies) == 0 {
+		return int32(len(movies)), rowErrors, nil
+	}
+
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		for i := range movies {
+			movie, err := service.movieRepo.Store(ctx, &movies[i])
+			if err != nil {
+				return err
+			}
+
+			// the file is curated by an admin, catalog imports keep its values like manual edits
+			if err = service.provenanceRepo.Record(ctx, movie.ID, movie.ImportableFields(), domain.FieldSourceManual); err != nil {
+				return err
+			}
+
+			if err = service.revisionService.Record(ctx, domain.RevisionSubjectMovie, movie.ID, domain.RevisionActionImported); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return int32(len(movies)), rowErrors, nil
+}
+
+// resolveGenres maps the genre references, ids or names, to the genres they match, names ignore case
+func (service *importJobService) resolveGenres(ctx context.Context, references []string) (map[string][]domain.Genre, error) {
+	var ids []uuid.UUID
+	var names []string
+	for _, reference := range references {
+		if id, err := uuid.Parse(reference); err == nil {
+			ids = append(ids, id)
+		} else {
+			names = append(names, reference)
+		}
+	}
+
+	genres := make(map[string][]domain.Genre)
+	if len(ids) > 0 {
+		found, err := service.genreRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, genre := range found {
+			genres[genre.Uuid.String()] = []domain.Genre{genre}
+		}
+	}
+	if len(names) > 0 {
+		found, err := service.genreRepo.FindByNames(ctx, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, genre := range found {
+			key := genreKey(genre.Name)
+			genres[key] = append(genres[key], genre)
+		}
+	}
+
+	return genres, nil
+}
+
+func (service *importJobService) importGenres(ctx context.Context, dryRun bool, state *importState, records []record) (int32, []domain.ImportRowError, error) {
+	var rowErrors []domain.ImportRowError
+	var rows []genreRow
+	var numbers []int32
+	for _, record := range records {
+		var row genreRow
+		if message := service.decode(record, &row); message != "" {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: record.number, Message: message})
+			continue
+		}
+		row.Name = strings.TrimSpace(row.Name)
+		rows = append(rows, row)
+		numbers = append(numbers, record.number)
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+	existing := make(map[string]bool)
+	if len(names) > 0 {
+		found, err := service.genreRepo.FindByNames(ctx, names)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, genre := range found {
+			existing[genreKey(genre.Name)] = true
+		}
+	}
+
+	var genres []domain.Genre
+	for i, row := range rows {
+		key := genreKey(row.Name)
+		if existing[key] {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: "the genre already exists"})
+			continue
+		}
+		if first, ok := state.rows["genre:"+key]; ok {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: fmt.Sprintf("the genre repeats row %d", first)})
+			continue
+		}
+		state.rows["genre:"+key] = numbers[i]
+
+		genres = append(genres, domain.Genre{Name: row.Name})
+	}
+
+	if dryRun || len(genres) == 0 {
+		return int32(len(genres)), rowErrors, nil
+	}
+
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		for i := range genres {
+			genre, err := service.genreRepo.Store(ctx, &genres[i])
+			if err != nil {
+				return err
+			}
+
+			if err = service.revisionService.Record(ctx, domain.RevisionSubjectGenre, genre.ID, domain.RevisionActionImported); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return int32(len(genres)), rowErrors, nil
+}
+
+// importRatings inserts ratings as published reviews, or pending review when moderation flags their comment
+func (service *importJobService) importRatings(ctx context.Context, dryRun bool, state *importState, records []record) (int32, []domain.ImportRowError, error) {
+	var rowErrors []domain.ImportRowError
+	var rows []ratingRow
+	var numbers []int32
+	var movieUuids []uuid.UUID
+	for _, record := range records {
+		var row ratingRow
+		if message := service.decode(record, &row); message != "" {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: record.number, Message: message})
+			continue
+		}
+		rows = append(rows, row)
+		numbers = append(numbers, record.number)
+		movieUuids = append(movieUuids, uuid.MustParse(row.Movie))
+	}
+
+	movies := make(map[uuid.UUID]uint)
+	if len(movieUuids) > 0 {
+		found, err := service.movieRepo.FindByIDs(ctx, movieUuids)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, movie := range found {
+			movies[movie.Uuid] = movie.ID
+		}
+	}
+
+	var ratings []domain.Rating
+	var movieIDs []uint
+	for i, row := range rows {
+		movieID := movies[uuid.MustParse(row.Movie)]
+		if movieID == 0 {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: "the movie does not exist"})
+			continue
+		}
+
+		userID, err := service.resolveUser(ctx, state, row.User)
+		if err != nil {
+			return 0, nil, err
+		}
+		if userID == 0 {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: "the user does not exist"})
+			continue
+		}
+
+		key := fmt.Sprintf("rating:%d:%d", userID, movieID)
+		if first, ok := state.rows[key]; ok {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: fmt.Sprintf("the user already rates the movie in row %d", first)})
+			continue
+		}
+		_, err = service.ratingRepo.FindByUserAndMovie(ctx, userID, movieID)
+		if err == nil {
+			rowErrors = append(rowErrors, domain.ImportRowError{RowNumber: numbers[i], Message: "the user already rated the movie"})
+			continue
+		}
+		if err != helper.NotFoundErr {
+			return 0, nil, err
+		}
+		state.rows[key] = numbers[i]
+
+		status := domain.ModerationStatusPublished
+		if row.Comment != "" && service.moderation.Screen(row.Comment) {
+			status = domain.ModerationStatusPendingReview
+		}
+		ratings = append(ratings, domain.Rating{
+			UserID:           userID,
+			MovieID:          movieID,
+			Rating:           *row.Rating,
+			Comment:          row.Comment,
+			ModerationStatus: status,
+		})
+		movieIDs = append(movieIDs, movieID)
+	}
+
+	if dryRun || len(ratings) == 0 {
+		return int32(len(ratings)), rowErrors, nil
+	}
+
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := service.ratingRepo.StoreBatch(ctx, ratings, len(ratings)); err != nil {
+			return err
+		}
+
+		return service.movieRepo.RefreshRatingStats(ctx, movieIDs, configs.Env.Rating.WeightedMinimumVotes)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return int32(len(ratings)), rowErrors, nil
+}
+
+// resolveUser finds the user by id, username or email and returns 0 when none matches
+func (service *importJobService) resolveUser(ctx context.Context, state *importState, reference string) (uint, error) {
+	if userID, ok := state.users[reference]; ok {
+		return userID, nil
+	}
+
+	var user domain.User
+	var err error
+	if id, parseErr := uuid.Parse(reference); parseErr == nil {
+		user, err = service.userRepo.FindByID(ctx, id)
+	} else {
+		user, err = service.userRepo.FindByUsernameOrEmail(ctx, reference, reference)
+	}
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, err
+	}
+
+	state.users[reference] = user.ID
+	return user.ID, nil
+}
+
+// decode fills the row from the record and validates it, returning why the row is not valid
+func (service *importJobService) decode(record record, row interface{}) string {
+	if err := record.decode(row); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &typeError) {
+			return fmt.Sprintf("%s cannot be a %s", typeError.Field, typeError.Value)
+		}
+		return err.Error()
+	}
+
+	if err := service.validate.Struct(row); err != nil {
+		return validationMessage(row, err)
+	}
+
+	return ""
+}
+
+// validationMessage names the failed fields by their column names rather than the Go field names
+func validationMessage(row interface{}, err error) string {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err.Error()
+	}
+
+	rowType := reflect.TypeOf(row).Elem()
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		name := fieldError.StructField()
+		if field, ok := rowType.FieldByName(name); ok {
+			name = strings.Split(field.Tag.Get("json"), ",")[0]
+		}
+
+		switch {
+		case fieldError.Tag() == "required":
+			messages = append(messages, fmt.Sprintf("%s is required", name))
+		case fieldError.Param() != "":
+			messages = append(messages, fmt.Sprintf("%s does not satisfy %s=%s", name, fieldError.Tag(), fieldError.Param()))
+		default:
+			messages = append(messages, fmt.Sprintf("%s does not satisfy %s", name, fieldError.Tag()))
+		}
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+func genreKey(reference string) string {
+	return strings.ToLower(strings.TrimSpace(reference))
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-movie-api/domain"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// listSeparator splits the values of a list column in CSV files, e.g. "Drama|Crime"
+const listSeparator = "|"
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// record is one row of an import file, decode fills a row struct from it
+type record struct {
+	number int32
+	decode func(target interface{}) error
+}
+
+// parseRecords splits the file into records, a file that cannot be read as the format fails as a whole
+func parseRecords(format string, file []byte) ([]record, error) {
+	file = bytes.TrimPrefix(file, utf8BOM)
+
+	switch format {
+	case domain.ImportFormatCSV:
+		return parseCSV(file)
+	case domain.ImportFormatJSON:
+		return parseJSON(file)
+	case domain.ImportFormatNDJSON:
+		return parseNDJSON(file)
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func parseCSV(file []byte) ([]record, error) {
+	reader := csv.NewReader(bytes.NewReader(file))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var records []record
+	for number := int32(1); ; number++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record{
+			number: number,
+			decode: func(target interface{}) error {
+				return decodeCSV(header, fields, target)
+			},
+		})
+	}
+}
+
+func parseJSON(file []byte) ([]record, error) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(file, &rows); err != nil {
+		return nil, err
+	}
+
+	records := make([]record, 0, len(rows))
+	for i, row := range rows {
+		records = append(records, jsonRecord(int32(i+1), row))
+	}
+
+	return records, nil
+}
+
+func parseNDJSON(file []byte) ([]record, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(file)+1)
+
+	var records []record
+	var number int32
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		number++
+		records = append(records, jsonRecord(number, append([]byte(nil), line...)))
+	}
+
+	return records, scanner.Err()
+}
+
+func jsonRecord(number int32, row []byte) record {
+	return record{
+		number: number,
+		decode: func(target interface{}) error {
+			return json.Unmarshal(row, target)
+		},
+	}
+}
+
+// decodeCSV fills the fields of target from the columns named like their json tags. Numbers are parsed and list
+// columns are split on listSeparator, empty columns leave the field at its zero value.
+func decodeCSV(header []string, fields []string, target interface{}) error {
+	if len(fields) != len(header) {
+		return fmt.Errorf("the row has %d columns, the header has %d", len(fields), len(header))
+	}
+
+	columns := make(map[string]string, len(header))
+	for i, name := range header {
+		columns[name] = strings.TrimSpace(fields[i])
+	}
+
+	value := reflect.ValueOf(target).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		column := columns[name]
+		if column == "" {
+			continue
+		}
+
+		field := value.Field(i)
+		// an optional field is a pointer, set when its column is not empty
+		if field.Kind() == reflect.Pointer {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(column)
+		case reflect.Int32:
+			number, err := strconv.ParseInt(column, 10, 32)
+			if err != nil {
+				return fmt.Errorf("%s is not a whole number", name)
+			}
+			field.SetInt(number)
+		case reflect.Float32:
+			number, err := strconv.ParseFloat(column, 32)
+			if err != nil {
+				return fmt.Errorf("%s is not a number", name)
+			}
+			field.SetFloat(number)
+		case reflect.Slice:
+			var items []string
+			for _, item := range strings.Split(column, listSeparator) {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		}
+	}
+
+	return nil
+}
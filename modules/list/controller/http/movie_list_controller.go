@@ -0,0 +1,286 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+)
+
+type MovieListController struct {
+	domain.MovieListService
+}
+
+func NewMovieListController(router *echo.Echo, movieListService domain.MovieListService) {
+	controller := &MovieListController{
+		MovieListService: movieListService,
+	}
+
+	group := router.Group("/lists")
+	group.GET("/:slug", controller.ShowBySlug, middleware.AuthMiddleware.OptionalHandler)
+	group.POST("/:slug/follow", controller.Follow, middleware.AuthMiddleware.Handler)
+	group.DELETE("/:slug/follow", controller.Unfollow, middleware.AuthMiddleware.Handler)
+
+	ownGroup := router.Group("/me/lists", middleware.AuthMiddleware.Handler)
+	ownGroup.GET("", controller.Index)
+	ownGroup.POST("", controller.Store)
+	ownGroup.GET("/:uuid", controller.Show)
+	ownGroup.PUT("/:uuid", controller.Update)
+	ownGroup.DELETE("/:uuid", controller.Destroy)
+	ownGroup.POST("/:uuid/entries", controller.StoreEntry)
+	ownGroup.PUT("/:uuid/entries/:movie_uuid", controller.UpdateEntry)
+	ownGroup.DELETE("/:uuid/entries/:movie_uuid", controller.DestroyEntry)
+	ownGroup.PUT("/:uuid/order", controller.Reorder)
+}
+
+func (controller *MovieListController) ShowBySlug(ec echo.Context) error {
+	viewer, _ := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.MovieListService.FindBySlug(ec.Request().Context(), ec.Param("slug"), viewer)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *MovieListController) Follow(ec echo.Context) error {
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err := controller.MovieListService.Follow(ec.Request().Context(), ec.Param("slug"), authUser)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.Success{Message: "The list has been followed."})
+}
+
+func (controller *MovieListController) Unfollow(ec echo.Context) error {
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err := controller.MovieListService.Unfollow(ec.Request().Context(), ec.Param("slug"), authUser)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.Success{Message: "The list has been unfollowed."})
+}
+
+func (controller *MovieListController) Index(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, pagination, err := controller.MovieListService.FetchByOwner(ec.Request().Context(), authUser.ID, page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.MovieList, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
+func (controller *MovieListController) Show(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.MovieListService.FindByID(ec.Request().Context(), id, authUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *MovieListController) Store(ec echo.Context) error {
+	var request storeRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.MovieListService.Store(ec.Request().Context(), &domain.MovieList{
+		UserID:      authUser.ID,
+		Title:       request.Title,
+		Description: request.Description,
+		Visibility:  request.Visibility,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+func (controller *MovieListController) Update(ec echo.Context) error {
+	var request updateRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.MovieListService.Update(ec.Request().Context(), &domain.MovieList{
+		Uuid:        id,
+		Title:       request.Title,
+		Description: request.Description,
+		Visibility:  request.Visibility,
+	}, authUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+func (controller *MovieListController) Destroy(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.MovieListService.Delete(ec.Request().Context(), id, authUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+func (controller *MovieListController) StoreEntry(ec echo.Context) error {
+	var request storeEntryRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.MovieListService.AddEntry(ec.Request().Context(), id, authUser.ID, request.MovieUuid, request.Note)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+func (controller *MovieListController) UpdateEntry(ec echo.Context) error {
+	var request updateEntryRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	movieID, err := uuid.Parse(ec.Param("movie_uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the movie id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.MovieListService.UpdateEntry(ec.Request().Context(), id, authUser.ID, movieID, request.Note)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+func (controller *MovieListController) DestroyEntry(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	movieID, err := uuid.Parse(ec.Param("movie_uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the movie id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.MovieListService.RemoveEntry(ec.Request().Context(), id, authUser.ID, movieID)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+func (controller *MovieListController) Reorder(ec echo.Context) error {
+	var request reorderRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.MovieListService.ReorderEntries(ec.Request().Context(), id, authUser.ID, request.MovieUuids)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
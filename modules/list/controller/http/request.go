@@ -0,0 +1,28 @@
+package http
+
+import "github.com/google/uuid"
+
+type storeRequest struct {
+	Title       string `json:"title" form:"title" validate:"required,max=255"`
+	Description string `json:"description" form:"description" validate:"omitempty"`
+	Visibility  string `json:"visibility" form:"visibility" validate:"required,oneof=private unlisted public"`
+}
+
+type updateRequest struct {
+	Title       string `json:"title" form:"title" validate:"omitempty,max=255"`
+	Description string `json:"description" form:"description" validate:"omitempty"`
+	Visibility  string `json:"visibility" form:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type storeEntryRequest struct {
+	MovieUuid uuid.UUID `json:"movie_id" form:"movie_id" validate:"required"`
+	Note      string    `json:"note" form:"note" validate:"omitempty,max=2000"`
+}
+
+type updateEntryRequest struct {
+	Note string `json:"note" form:"note" validate:"omitempty,max=2000"`
+}
+
+type reorderRequest struct {
+	MovieUuids []uuid.UUID `json:"movie_ids" form:"movie_ids" validate:"required"`
+}
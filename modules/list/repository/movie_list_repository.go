@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type movieListRepository struct {
+	db *gorm.DB
+}
+
+func NewMovieListRepository(gormDB *gorm.DB) domain.MovieListRepository {
+	return &movieListRepository{db: gormDB}
+}
+
+func (repo *movieListRepository) FetchByUser(ctx context.Context, userID uint, pagination *utils.Pagination) ([]domain.MovieList, error) {
+	var lists []domain.MovieList
+
+	owned := database.Conn(ctx, repo.db).Where("user_id = ?", userID)
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ?", userID).
+		Scopes(utils.Paginate(lists, pagination, owned)).
+		Order("updated_at DESC").
+		Order("id DESC").
+		Find(&lists)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return lists, nil
+}
+
+func (repo *movieListRepository) FindBySlug(ctx context.Context, slug string) (domain.MovieList, error) {
+	return repo.find(ctx, "slug = ?", slug)
+}
+
+func (repo *movieListRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.MovieList, error) {
+	return repo.find(ctx, "uuid = ?", uuid.String())
+}
+
+// find loads a list with its owner and its entries in order, skipping the entries of removed movies
+func (repo *movieListRepository) find(ctx context.Context, query string, value interface{}) (domain.MovieList, error) {
+	var list domain.MovieList
+
+	result := database.Conn(ctx, repo.db).
+		Preload("User").
+		Preload("Entries", func(db *gorm.DB) *gorm.DB {
+			return db.Where("movie_list_entries.movie_id IN (?)",
+				db.Session(&gorm.Session{NewDB: true}).Model(&domain.Movie{}).Select("id")).
+				Order("movie_list_entries.position ASC").
+				Order("movie_list_entries.id ASC")
+		}).
+		Preload("Entries.Movie").
+		Where(query, value).
+		First(&list)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.MovieList{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.MovieList{}, result.Error
+	}
+
+	return list, nil
+}
+
+func (repo *movieListRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.MovieList, error) {
+	var list domain.MovieList
+
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = ?", uuid.String()).
+		First(&list)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.MovieList{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.MovieList{}, result.Error
+	}
+
+	return list, nil
+}
+
+func (repo *movieListRepository) Store(ctx context.Context, list *domain.MovieList) (domain.MovieList, error) {
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.Returning{}).
+		Omit("uuid", "User", "Entries").
+		Create(list)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return domain.MovieList{}, helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.MovieList{}, result.Error
+	}
+
+	return *list, nil
+}
+
+func (repo *movieListRepository) Update(ctx context.Context, list *domain.MovieList) error {
+	result := database.Conn(ctx, repo.db).
+		Model(list).
+		Where("uuid = ?", list.Uuid.String()).
+		Select("title", "description", "visibility", "updated_at").
+		Updates(list)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *movieListRepository) SoftDelete(ctx context.Context, id uint) error {
+	result := database.Conn(ctx, repo.db).Where("id = ?", id).Delete(&domain.MovieList{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *movieListRepository) FetchEntryMovieIDs(ctx context.Context, listID uint) (map[uuid.UUID]uint, error) {
+	var rows []struct {
+		ID   uint
+		Uuid uuid.UUID
+	}
+
+	result := database.Conn(ctx, repo.db).
+		Table("movie_list_entries").
+		Select("movies.id, movies.uuid").
+		Joins("JOIN movies ON movies.id = movie_list_entries.movie_id AND movies.deleted_at IS NULL").
+		Where("movie_list_entries.movie_list_id = ?", listID).
+		Scan(&rows)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	ids := make(map[uuid.UUID]uint, len(rows))
+	for _, row := range rows {
+		ids[row.Uuid] = row.ID
+	}
+
+	return ids, nil
+}
+
+func (repo *movieListRepository) NextPosition(ctx context.Context, listID uint) (int32, error) {
+	var position int32
+
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.MovieListEntry{}).
+		Select("COALESCE(MAX(position), 0) + 1").
+		Where("movie_list_id = ?", listID).
+		Scan(&position)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return 0, result.Error
+	}
+
+	return position, nil
+}
+
+func (repo *movieListRepository) StoreEntry(ctx context.Context, entry *domain.MovieListEntry) (domain.MovieListEntry, error) {
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Omit("Movie").Create(entry)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return domain.MovieListEntry{}, helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.MovieListEntry{}, result.Error
+	}
+
+	return *entry, nil
+}
+
+func (repo *movieListRepository) UpdateEntryNote(ctx context.Context, listID uint, movieID uint, note string) error {
+	return repo.updateEntry(ctx, listID, movieID, "note", note)
+}
+
+func (repo *movieListRepository) UpdateEntryPosition(ctx context.Context, listID uint, movieID uint, position int32) error {
+	return repo.updateEntry(ctx, listID, movieID, "position", position)
+}
+
+func (repo *movieListRepository) updateEntry(ctx context.Context, listID uint, movieID uint, column string, value interface{}) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.MovieListEntry{}).
+		Where("movie_list_id = ? AND movie_id = ?", listID, movieID).
+		Update(column, value)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *movieListRepository) DeleteEntry(ctx context.Context, listID uint, movieID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Where("movie_list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&domain.MovieListEntry{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *movieListRepository) StoreFollower(ctx context.Context, listID uint, userID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Exec("INSERT INTO movie_list_followers (movie_list_id, user_id) VALUES (?, ?)", listID, userID)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *movieListRepository) DeleteFollower(ctx context.Context, listID uint, userID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Exec("DELETE FROM movie_list_followers WHERE movie_list_id = ? AND user_id = ?", listID, userID)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *movieListRepository) RefreshFollowerCount(ctx context.Context, listID uint) error {
+	result := database.Conn(ctx, repo.db).Exec(`
+		UPDATE movie_lists
+		SET follower_count = (SELECT COUNT(*) FROM movie_list_followers WHERE movie_list_followers.movie_list_id = movie_lists.id)
+		WHERE movie_lists.id = ?`, listID)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	slugMaxLength  = 60
+	slugSuffixSize = 6
+	slugAlphabet   = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// slugAttempts bounds the retries when a generated slug is already taken
+	slugAttempts = 3
+)
+
+type movieListService struct {
+	listRepo   domain.MovieListRepository
+	movieRepo  domain.MovieRepository
+	transactor domain.Transactor
+	timeout    time.Duration
+}
+
+func NewMovieListService(listRepo domain.MovieListRepository, movieRepo domain.MovieRepository, transactor domain.Transactor, timeout time.Duration) domain.MovieListService {
+	return &movieListService{
+		listRepo:   listRepo,
+		movieRepo:  movieRepo,
+		transactor: transactor,
+		timeout:    timeout,
+	}
+}
+
+func (service *movieListService) FetchByOwner(ctx context.Context, userID uint, page int, perPage int) ([]domain.MovieList, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	lists, err := service.listRepo.FetchByUser(ctx, userID, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return lists, pagination, nil
+}
+
+func (service *movieListService) FindBySlug(ctx context.Context, slug string, viewer *domain.User) (domain.MovieList, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	list, err := service.listRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return domain.MovieList{}, err
+	}
+
+	if !visible(list, viewer) {
+		return domain.MovieList{}, errors.NotFoundErr
+	}
+
+	return list, nil
+}
+
+func (service *movieListService) FindByID(ctx context.Context, uuid uuid.UUID, userID uint) (domain.MovieList, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	list, err := service.listRepo.FindByID(ctx, uuid)
+	if err != nil {
+		return domain.MovieList{}, err
+	}
+
+	if list.UserID != userID {
+		return domain.MovieList{}, errors.ForbiddenErr
+	}
+
+	return list, nil
+}
+
+func (service *movieListService) Store(ctx context.Context, list *domain.MovieList) (domain.MovieList, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	var err error
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		if list.Slug, err = newSlug(list.Title); err != nil {
+			return domain.MovieList{}, err
+		}
+
+		var result domain.MovieList
+		result, err = service.listRepo.Store(ctx, list)
+		if err != errors.ConflictErr {
+			return result, err
+		}
+	}
+
+	return domain.MovieList{}, err
+}
+
+func (service *movieListService) Update(ctx context.Context, list *domain.MovieList, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.findOwned(ctx, list.Uuid, userID)
+		if err != nil {
+			return err
+		}
+
+		// the slug stays the same so that shared links keep working, a title or visibility left out is kept as
+		// well while the description is replaced, an empty one clears it
+		list.ID = existing.ID
+		list.UserID = existing.UserID
+		list.Slug = existing.Slug
+		if list.Title == "" {
+			list.Title = existing.Title
+		}
+		if list.Visibility == "" {
+			list.Visibility = existing.Visibility
+		}
+
+		return service.listRepo.Update(ctx, list)
+	})
+}
+
+func (service *movieListService) Delete(ctx context.Context, uuid uuid.UUID, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		list, err := service.findOwned(ctx, uuid, userID)
+		if err != nil {
+			return err
+		}
+
+		return service.listRepo.SoftDelete(ctx, list.ID)
+	})
+}
+
+func (service *movieListService) AddEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID, note string) (domain.MovieListEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movie, err := service.movieRepo.FindByID(ctx, movieUuid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.MovieListEntry{}, echo.NewHTTPError(http.StatusBadRequest, "The movie is not valid.")
+		}
+		return domain.MovieListEntry{}, err
+	}
+
+	var result domain.MovieListEntry
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		// the list row lock keeps concurrent additions from taking the same position
+		list, err := service.findOwned(ctx, uuid, userID)
+		if err != nil {
+			return err
+		}
+
+		position, err := service.listRepo.NextPosition(ctx, list.ID)
+		if err != nil {
+			return err
+		}
+
+		result, err = service.listRepo.StoreEntry(ctx, &domain.MovieListEntry{
+			MovieListID: list.ID,
+			MovieID:     movie.ID,
+			Position:    position,
+			Note:        note,
+		})
+		return err
+	})
+	if err != nil {
+		return domain.MovieListEntry{}, err
+	}
+	movie.Ratings = nil
+	result.Movie = &movie
+
+	return result, nil
+}
+
+func (service *movieListService) UpdateEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID, note string) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		list, movieID, err := service.findOwnedEntry(ctx, uuid, userID, movieUuid)
+		if err != nil {
+			return err
+		}
+
+		return service.listRepo.UpdateEntryNote(ctx, list.ID, movieID, note)
+	})
+}
+
+func (service *movieListService) RemoveEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		list, movieID, err := service.findOwnedEntry(ctx, uuid, userID, movieUuid)
+		if err != nil {
+			return err
+		}
+
+		return service.listRepo.DeleteEntry(ctx, list.ID, movieID)
+	})
+}
+
+func (service *movieListService) ReorderEntries(ctx context.Context, listUuid uuid.UUID, userID uint, movieUuids []uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		list, err := service.findOwned(ctx, listUuid, userID)
+		if err != nil {
+			return err
+		}
+
+		movieIDs, err := service.listRepo.FetchEntryMovieIDs(ctx, list.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(movieUuids) != len(movieIDs) {
+			return echo.NewHTTPError(http.StatusBadRequest, "The order must list every movie of the list once.")
+		}
+
+		seen := make(map[uuid.UUID]bool, len(movieUuids))
+		for _, movieUuid := range movieUuids {
+			if _, ok := movieIDs[movieUuid]; !ok || seen[movieUuid] {
+				return echo.NewHTTPError(http.StatusBadRequest, "The order must list every movie of the list once.")
+			}
+			seen[movieUuid] = true
+		}
+
+		for i, movieUuid := range movieUuids {
+			if err = service.listRepo.UpdateEntryPosition(ctx, list.ID, movieIDs[movieUuid], int32(i+1)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (service *movieListService) Follow(ctx context.Context, slug string, user *domain.User) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		list, err := service.listRepo.FindBySlug(ctx, slug)
+		if err != nil {
+			return err
+		}
+
+		if !visible(list, user) {
+			return errors.NotFoundErr
+		}
+
+		if err = service.listRepo.StoreFollower(ctx, list.ID, user.ID); err != nil {
+			return err
+		}
+
+		return service.listRepo.RefreshFollowerCount(ctx, list.ID)
+	})
+}
+
+func (service *movieListService) Unfollow(ctx context.Context, slug string, user *domain.User) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		list, err := service.listRepo.FindBySlug(ctx, slug)
+		if err != nil {
+			return err
+		}
+
+		if !visible(list, user) {
+			return errors.NotFoundErr
+		}
+
+		if err = service.listRepo.DeleteFollower(ctx, list.ID, user.ID); err != nil {
+			return err
+		}
+
+		return service.listRepo.RefreshFollowerCount(ctx, list.ID)
+	})
+}
+
+// findOwned locks the list and makes sure it belongs to userID
+func (service *movieListService) findOwned(ctx context.Context, uuid uuid.UUID, userID uint) (domain.MovieList, error) {
+	list, err := service.listRepo.FindByIDForUpdate(ctx, uuid)
+	if err != nil {
+		return domain.MovieList{}, err
+	}
+
+	if list.UserID != userID {
+		return domain.MovieList{}, errors.ForbiddenErr
+	}
+
+	return list, nil
+}
+
+func (service *movieListService) findOwnedEntry(ctx context.Context, uuid uuid.UUID, userID uint, movieUuid uuid.UUID) (domain.MovieList, uint, error) {
+	list, err := service.findOwned(ctx, uuid, userID)
+	if err != nil {
+		return domain.MovieList{}, 0, err
+	}
+
+	movieIDs, err := service.listRepo.FetchEntryMovieIDs(ctx, list.ID)
+	if err != nil {
+		return domain.MovieList{}, 0, err
+	}
+
+	movieID, ok := movieIDs[movieUuid]
+	if !ok {
+		return domain.MovieList{}, 0, errors.NotFoundErr
+	}
+
+	return list, movieID, nil
+}
+
+// visible reports whether viewer, nil for anonymous requests, may see the list
+func visible(list domain.MovieList, viewer *domain.User) bool {
+	if list.Visibility != domain.ListVisibilityPrivate {
+		return true
+	}
+
+	return viewer != nil && viewer.ID == list.UserID
+}
+
+// newSlug builds a readable slug from the title followed by a random suffix, e.g. "best-heist-films-k3x9q2"
+func newSlug(title string) (string, error) {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			builder.WriteRune(r)
+			dash = false
+		} else if !dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+			dash = true
+		}
+
+		if builder.Len() >= slugMaxLength {
+			break
+		}
+	}
+
+	base := strings.TrimSuffix(builder.String(), "-")
+	if base != "" {
+		base += "-"
+	}
+
+	suffix := make([]byte, slugSuffixSize)
+	for i := range suffix {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(slugAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		suffix[i] = slugAlphabet[index.Int64()]
+	}
+
+	return base + string(suffix), nil
+}
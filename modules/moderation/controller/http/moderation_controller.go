@@ -0,0 +1,106 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+)
+
+type ModerationController struct {
+	domain.ModerationService
+}
+
+func NewModerationController(router *echo.Echo, moderationService domain.ModerationService) {
+	controller := &ModerationController{
+		ModerationService: moderationService,
+	}
+
+	router.POST("/ratings/:uuid/reports", controller.Report, middleware.AuthMiddleware.Handler)
+
+	group := router.Group("/admin/moderation", middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+	group.GET("", controller.Index)
+	group.POST("/:uuid/approve", controller.decide(domain.ModerationActionApprove))
+	group.POST("/:uuid/reject", controller.decide(domain.ModerationActionReject))
+	group.POST("/:uuid/ban", controller.decide(domain.ModerationActionBan))
+}
+
+func (controller *ModerationController) Report(ec echo.Context) error {
+	var request reportRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.ModerationService.Report(ec.Request().Context(), id, authUser.ID, request.Reason)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, response.Success{Message: "The review has been reported."})
+}
+
+// Index lists the reviews waiting for a decision, oldest first
+func (controller *ModerationController) Index(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 20
+	}
+
+	data, pagination, err := controller.ModerationService.FetchQueue(ec.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
+// decide returns the handler recording the action on the review, approve publishes it again while
+// reject and ban hide it, ban also keeps its author from signing in
+func (controller *ModerationController) decide(action string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		var request decisionRequest
+		if err := ec.Bind(&request); err != nil {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		}
+
+		if err := ec.Validate(request); err != nil {
+			return err
+		}
+
+		id, err := uuid.Parse(ec.Param("uuid"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+		}
+
+		authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+		data, err := controller.ModerationService.Decide(ec.Request().Context(), id, authUser.ID, action, request.Note)
+		if err != nil {
+			return err
+		}
+
+		return ec.JSON(http.StatusOK, data)
+	}
+}
@@ -0,0 +1,9 @@
+package http
+
+type reportRequest struct {
+	Reason string `json:"reason" form:"reason" validate:"required,max=1000"`
+}
+
+type decisionRequest struct {
+	Note string `json:"note" form:"note" validate:"omitempty,max=1000"`
+}
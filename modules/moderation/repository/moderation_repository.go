@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(gormDB *gorm.DB) domain.ModerationRepository {
+	return &moderationRepository{db: gormDB}
+}
+
+func (repo *moderationRepository) StoreReport(ctx context.Context, report *domain.RatingReport) error {
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Create(report)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *moderationRepository) CountOpenReports(ctx context.Context, ratingID uint) (int64, error) {
+	var count int64
+
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.RatingReport{}).
+		Where("rating_id = ? AND resolved_at IS NULL", ratingID).
+		Count(&count)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return 0, result.Error
+	}
+
+	return count, nil
+}
+
+func (repo *moderationRepository) FetchOpenReports(ctx context.Context, ratingIDs []uint) ([]domain.RatingReport, error) {
+	var reports []domain.RatingReport
+	if len(ratingIDs) == 0 {
+		return reports, nil
+	}
+
+	result := database.Conn(ctx, repo.db).
+		Where("rating_id IN ? AND resolved_at IS NULL", ratingIDs).
+		Preload("User").
+		Order("created_at ASC").
+		Find(&reports)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return reports, nil
+}
+
+func (repo *moderationRepository) ResolveReports(ctx context.Context, ratingID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.RatingReport{}).
+		Where("rating_id = ? AND resolved_at IS NULL", ratingID).
+		Update("resolved_at", time.Now())
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *moderationRepository) StoreDecision(ctx context.Context, decision *domain.ModerationDecision) error {
+	result := database.Conn(ctx, repo.db).Omit("Rating", "Moderator").Clauses(clause.Returning{}).Create(decision)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type moderationService struct {
+	moderationRepo domain.ModerationRepository
+	ratingRepo     domain.RatingRepository
+	movieRepo      domain.MovieRepository
+	userRepo       domain.UserRepository
+	transactor     domain.Transactor
+	rules          []*regexp.Regexp
+	timeout        time.Duration
+}
+
+// NewModerationService fails when a configured moderation pattern is not a valid regular expression
+func NewModerationService(moderationRepo domain.ModerationRepository, ratingRepo domain.RatingRepository, movieRepo domain.MovieRepository, userRepo domain.UserRepository, transactor domain.Transactor, timeout time.Duration) (domain.ModerationService, error) {
+	rules, err := compileRules(configs.Env.Moderation.Words, configs.Env.Moderation.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &moderationService{
+		moderationRepo: moderationRepo,
+		ratingRepo:     ratingRepo,
+		movieRepo:      movieRepo,
+		userRepo:       userRepo,
+		transactor:     transactor,
+		rules:          rules,
+		timeout:        timeout,
+	}, nil
+}
+
+func (service *moderationService) Screen(comment string) bool {
+	for _, rule := range service.rules {
+		if rule.MatchString(comment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (service *moderationService) Report(ctx context.Context, ratingUuid uuid.UUID, userID uint, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		rating, err := service.ratingRepo.FindByIDForUpdate(ctx, ratingUuid)
+		if err != nil {
+			return err
+		}
+
+		if err = service.moderationRepo.StoreReport(ctx, &domain.RatingReport{
+			RatingID: rating.ID,
+			UserID:   userID,
+			Reason:   reason,
+		}); err != nil {
+			return err
+		}
+
+		if rating.ModerationStatus != domain.ModerationStatusPublished {
+			return nil
+		}
+
+		reports, err := service.moderationRepo.CountOpenReports(ctx, rating.ID)
+		if err != nil {
+			return err
+		}
+
+		if reports < int64(configs.Env.Moderation.ReportThreshold) {
+			return nil
+		}
+
+		if err = service.ratingRepo.UpdateModerationStatus(ctx, rating.ID, domain.ModerationStatusPendingReview); err != nil {
+			return err
+		}
+
+		return service.refreshRatingStats(ctx, rating.MovieID)
+	})
+}
+
+func (service *moderationService) FetchQueue(ctx context.Context, page int, perPage int) ([]domain.ModerationQueueItem, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	ratings, err := service.ratingRepo.FetchByModerationStatus(ctx, domain.ModerationStatusPendingReview, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	ratingIDs := make([]uint, 0, len(ratings))
+	for _, rating := range ratings {
+		ratingIDs = append(ratingIDs, rating.ID)
+	}
+
+	reports, err := service.moderationRepo.FetchOpenReports(ctx, ratingIDs)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	reportsByRating := make(map[uint][]domain.RatingReport, len(ratings))
+	for _, report := range reports {
+		reportsByRating[report.RatingID] = append(reportsByRating[report.RatingID], report)
+	}
+
+	items := make([]domain.ModerationQueueItem, 0, len(ratings))
+	for _, rating := range ratings {
+		itemReports := reportsByRating[rating.ID]
+		if itemReports == nil {
+			// flagged by the word list or rules without any report
+			itemReports = make([]domain.RatingReport, 0)
+		}
+
+		items = append(items, domain.ModerationQueueItem{
+			Rating:  rating,
+			Reports: itemReports,
+		})
+	}
+
+	return items, pagination, nil
+}
+
+func (service *moderationService) Decide(ctx context.Context, ratingUuid uuid.UUID, moderatorID uint, action string, note string) (domain.ModerationDecision, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	status := domain.ModerationStatusRejected
+	if action == domain.ModerationActionApprove {
+		status = domain.ModerationStatusPublished
+	}
+
+	var decision domain.ModerationDecision
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		rating, err := service.ratingRepo.FindByIDForUpdate(ctx, ratingUuid)
+		if err != nil {
+			return err
+		}
+
+		if err = service.ratingRepo.UpdateModerationStatus(ctx, rating.ID, status); err != nil {
+			return err
+		}
+		if rating.ModerationStatus != status {
+			if err = service.refreshRatingStats(ctx, rating.MovieID); err != nil {
+				return err
+			}
+		}
+		rating.ModerationStatus = status
+
+		if err = service.moderationRepo.ResolveReports(ctx, rating.ID); err != nil {
+			return err
+		}
+
+		if action == domain.ModerationActionBan {
+			if err = service.userRepo.Ban(ctx, rating.UserID); err != nil {
+				return err
+			}
+		}
+
+		decision = domain.ModerationDecision{
+			RatingID:    rating.ID,
+			ModeratorID: &moderatorID,
+			Action:      action,
+			Note:        note,
+		}
+		if err = service.moderationRepo.StoreDecision(ctx, &decision); err != nil {
+			return err
+		}
+		decision.Rating = &rating
+
+		return nil
+	})
+	if err != nil {
+		return domain.ModerationDecision{}, err
+	}
+
+	return decision, nil
+}
+
+// refreshRatingStats keeps the rating aggregates of the movie in step with its published ratings
+func (service *moderationService) refreshRatingStats(ctx context.Context, movieID uint) error {
+	return service.movieRepo.RefreshRatingStats(ctx, []uint{movieID}, configs.Env.Rating.WeightedMinimumVotes)
+}
+
+// compileRules turns every word into a case-insensitive whole word rule, patterns are used as they are
+func compileRules(words []string, patterns []string) ([]*regexp.Regexp, error) {
+	rules := make([]*regexp.Regexp, 0, len(patterns)+1)
+
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) > 0 {
+		rules = append(rules, regexp.MustCompile(`(?i)\b(?:`+strings.Join(quoted, "|")+`)\b`))
+	}
+
+	for _, pattern := range patterns {
+		rule, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderation pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
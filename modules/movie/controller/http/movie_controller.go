@@ -1,13 +1,22 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go-movie-api/domain"
 	"go-movie-api/middleware"
+	"go-movie-api/utils"
+	"go-movie-api/utils/etag"
+	"go-movie-api/utils/export"
+	"go-movie-api/utils/helper"
+	"go-movie-api/utils/patch"
 	"go-movie-api/utils/response"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type MovieController struct {
@@ -20,11 +29,18 @@ func NewMovieController(router *echo.Echo, movieService domain.MovieService) {
 	}
 
 	group := router.Group("/movies")
-	group.GET("", controller.Index)
+	group.GET("", controller.Index, middleware.AuthMiddleware.OptionalHandler)
+	group.GET("/search", controller.Search)
 	group.GET("/:uuid", controller.Show)
 	group.PUT("/:uuid", controller.Update, middleware.AuthMiddleware.Handler)
+	group.PATCH("/:uuid", controller.Patch, middleware.AuthMiddleware.Handler)
 	group.POST("", controller.Store, middleware.AuthMiddleware.Handler)
 	group.DELETE("/:uuid", controller.Destroy, middleware.AuthMiddleware.Handler)
+	group.POST("/:uuid/restore", controller.Restore, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+
+	router.GET("/admin/trash/movies", controller.Trash, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+
+	router.GET("/exports/movies", controller.Export, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
 }
 
 func (controller *MovieController) Index(ec echo.Context) error {
@@ -38,12 +54,44 @@ func (controller *MovieController) Index(ec echo.Context) error {
 		perPage = 100
 	}
 
+	var request indexRequest
+	if err = ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err = ec.Validate(request); err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(request)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if filter.IncludeDeleted {
+		authUser, ok := ec.Get(middleware.AuthUserKey).(*domain.User)
+		if !ok || !authUser.IsAdmin {
+			return helper.ForbiddenErr
+		}
+	}
+
+	if utils.CursorRequested(ec) {
+		return controller.indexCursor(ec, filter)
+	}
+
 	ctx := ec.Request().Context()
-	data, pagination, err := controller.MovieService.FetchPagination(ctx, page, perPage)
+	data, pagination, err := controller.MovieService.FetchPagination(ctx, filter, page, perPage)
 	if err != nil {
 		return err
 	}
 
+	if filter.IncludeDeleted {
+		return ec.JSON(http.StatusOK, response.Result{
+			Meta: pagination,
+			Data: listedMovies(data),
+		})
+	}
+
 	if data == nil {
 		data = make([]domain.Movie, 0)
 	}
@@ -54,6 +102,97 @@ func (controller *MovieController) Index(ec echo.Context) error {
 	})
 }
 
+func (controller *MovieController) indexCursor(ec echo.Context, filter domain.MovieFilter) error {
+	cursorPagination, err := utils.NewCursorPagination(ec)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	data, cursorPagination, err := controller.MovieService.FetchCursor(ctx, filter, cursorPagination)
+	if err != nil {
+		return err
+	}
+
+	if filter.IncludeDeleted {
+		return ec.JSON(http.StatusOK, response.Result{
+			Meta: cursorPagination,
+			Data: listedMovies(data),
+		})
+	}
+
+	if data == nil {
+		data = make([]domain.Movie, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: cursorPagination,
+		Data: data,
+	})
+}
+
+// Export streams the movies matching the filters of Index, as CSV, NDJSON or Parquet
+func (controller *MovieController) Export(ec echo.Context) error {
+	var request exportRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(request.indexRequest)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	format := request.Format
+	if format == "" {
+		format = export.Negotiate(ec.Request().Header.Get(echo.HeaderAccept))
+	}
+
+	download := export.NewDownload(ec.Response(), "movies", format)
+	if err = controller.MovieService.Export(ec.Request().Context(), filter, format, download); err != nil {
+		return err
+	}
+	download.Commit()
+
+	return nil
+}
+
+func (controller *MovieController) Search(ec echo.Context) error {
+	query := strings.TrimSpace(ec.QueryParam("q"))
+	if query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "the search query is required.")
+	}
+
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 20
+	}
+
+	ctx := ec.Request().Context()
+	data, pagination, err := controller.MovieService.Search(ctx, query, page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.MovieSearchResult, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
 func (controller *MovieController) Show(ec echo.Context) error {
 	id, err := uuid.Parse(ec.Param("uuid"))
 	if err != nil {
@@ -65,7 +204,7 @@ func (controller *MovieController) Show(ec echo.Context) error {
 		return err
 	}
 
-	return ec.JSON(http.StatusOK, data)
+	return etag.JSON(ec, data.Version, data)
 }
 
 func (controller *MovieController) Store(ec echo.Context) error {
@@ -111,6 +250,11 @@ func (controller *MovieController) Update(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
 	var genres []domain.Genre
 	for _, genreID := range request.GenreIDs {
 		genres = append(genres, domain.Genre{Uuid: genreID})
@@ -122,6 +266,62 @@ func (controller *MovieController) Update(ec echo.Context) error {
 		Year:     request.Year,
 		Synopsis: request.Synopsis,
 		Genres:   genres,
+	}, version)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+// Patch changes the movie with a JSON Merge Patch or a JSON Patch of the fields of patchRequest, e.g.
+// {"synopsis": null} clears the synopsis
+func (controller *MovieController) Patch(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	document, err := patch.FromRequest(ec)
+	if err != nil {
+		return err
+	}
+
+	err = controller.MovieService.Patch(ec.Request().Context(), id, version, func(movie *domain.Movie) error {
+		request := patchRequest{
+			Title:    movie.Title,
+			Duration: movie.Duration,
+			Year:     movie.Year,
+			Synopsis: movie.Synopsis,
+			GenreIDs: make([]uuid.UUID, 0, len(movie.Genres)),
+		}
+		for _, genre := range movie.Genres {
+			request.GenreIDs = append(request.GenreIDs, genre.Uuid)
+		}
+
+		if err := document.ApplyTo(&request); err != nil {
+			return err
+		}
+
+		if err := ec.Validate(request); err != nil {
+			return err
+		}
+
+		movie.Title = request.Title
+		movie.Duration = request.Duration
+		movie.Year = request.Year
+		movie.Synopsis = request.Synopsis
+		movie.Genres = make([]domain.Genre, 0, len(request.GenreIDs))
+		for _, genreID := range request.GenreIDs {
+			movie.Genres = append(movie.Genres, domain.Genre{Uuid: genreID})
+		}
+
+		return nil
 	})
 	if err != nil {
 		return err
@@ -136,10 +336,121 @@ func (controller *MovieController) Destroy(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
-	err = controller.MovieService.SoftDelete(ec.Request().Context(), id)
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	// hard=true deletes the movie for good, from the trash or not, which only admins may do
+	if hard, _ := strconv.ParseBool(ec.QueryParam("hard")); hard {
+		if !middleware.IsAdmin(ec) {
+			return helper.ForbiddenErr
+		}
+		err = controller.MovieService.Delete(ec.Request().Context(), id, version)
+	} else {
+		err = controller.MovieService.SoftDelete(ec.Request().Context(), id, version)
+	}
 	if err != nil {
 		return err
 	}
 
 	return ec.JSON(http.StatusOK, response.DeleteSuccess)
 }
+
+// Trash lists the soft deleted movies, the most recently deleted first
+func (controller *MovieController) Trash(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.MovieService.FetchTrashed(ec.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	trashed := make([]trashedMovie, 0, len(data))
+	for _, movie := range data {
+		trashed = append(trashed, trashedMovie{Movie: movie, DeletedAt: movie.DeletedAt.Time})
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: trashed,
+	})
+}
+
+func (controller *MovieController) Restore(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	if err = controller.MovieService.Restore(ec.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.RestoreSuccess)
+}
+
+// parseFilter converts the query parameters of a movie listing into a domain.MovieFilter
+func parseFilter(request indexRequest) (domain.MovieFilter, error) {
+	filter := domain.MovieFilter{
+		YearFrom:       request.YearFrom,
+		YearTo:         request.YearTo,
+		DurationFrom:   request.DurationFrom,
+		DurationTo:     request.DurationTo,
+		GenreMatch:     request.GenreMatch,
+		MinRating:      request.MinRating,
+		IncludeDeleted: request.IncludeDeleted,
+		ReleaseStatus:  request.Release,
+		ReleaseRegion:  request.Region,
+	}
+
+	if filter.YearFrom != 0 && filter.YearTo != 0 && filter.YearFrom > filter.YearTo {
+		return domain.MovieFilter{}, errors.New("the year_from must not be after the year_to.")
+	}
+
+	if filter.GenreMatch == "" {
+		filter.GenreMatch = domain.GenreMatchAny
+	}
+
+	// a region alone asks for the movies released there
+	if filter.ReleaseRegion != "" && filter.ReleaseStatus == "" {
+		filter.ReleaseStatus = domain.ReleaseStatusReleased
+	}
+
+	if request.GenreIDs != "" {
+		for _, value := range strings.Split(request.GenreIDs, ",") {
+			genreID, err := uuid.Parse(strings.TrimSpace(value))
+			if err != nil {
+				return domain.MovieFilter{}, fmt.Errorf("the genre id %q is not valid.", value)
+			}
+			filter.GenreIDs = append(filter.GenreIDs, genreID)
+		}
+	}
+
+	if request.CreatedAfter != "" {
+		createdAfter, err := time.Parse(time.RFC3339, request.CreatedAfter)
+		if err != nil {
+			createdAfter, err = time.Parse(time.DateOnly, request.CreatedAfter)
+		}
+		if err != nil {
+			return domain.MovieFilter{}, errors.New("the created_after date is not valid.")
+		}
+		filter.CreatedAfter = createdAfter
+	}
+
+	sort, err := utils.ParseSort(request.Sort, domain.MovieSortFields)
+	if err != nil {
+		return domain.MovieFilter{}, err
+	}
+	filter.Sort = sort
+
+	return filter, nil
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/patch"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeMovieService applies the patches to one movie the way the movie service does, with its genres loaded
+type fakeMovieService struct {
+	domain.MovieService
+	movie domain.Movie
+}
+
+func (service *fakeMovieService) Patch(_ context.Context, uuid uuid.UUID, _ uint, apply func(movie *domain.Movie) error) error {
+	if uuid != service.movie.Uuid {
+		return errors.New("the movie does not exist")
+	}
+
+	movie := service.movie
+	movie.Genres = append([]domain.Genre(nil), service.movie.Genres...)
+	if err := apply(&movie); err != nil {
+		return err
+	}
+
+	service.movie = movie
+	return nil
+}
+
+var (
+	drama = domain.Genre{Uuid: uuid.MustParse("7f1d2a4e-3b1a-4c51-9d51-2f0b3c6e8a01"), Name: "Drama"}
+	crime = domain.Genre{Uuid: uuid.MustParse("0c9e6b7d-5a4f-4e2b-8c3d-1a2b3c4d5e02"), Name: "Crime"}
+)
+
+func newPatchRequest(t *testing.T, service *fakeMovieService, contentType string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	requestValidator, err := utils.NewValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := echo.New()
+	router.Validator = &utils.RequestValidator{Validator: requestValidator}
+
+	request := httptest.NewRequest(http.MethodPatch, "/movies/"+service.movie.Uuid.String(), strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, contentType)
+	request.Header.Set("If-Match", "*")
+	recorder := httptest.NewRecorder()
+
+	ec := router.NewContext(request, recorder)
+	ec.SetParamNames("uuid")
+	ec.SetParamValues(service.movie.Uuid.String())
+	return ec, recorder
+}
+
+func newFakeMovieService() *fakeMovieService {
+	return &fakeMovieService{movie: domain.Movie{
+		Uuid:     uuid.New(),
+		Title:    "Heat",
+		Year:     1995,
+		Duration: 170,
+		Synopsis: "A heist.",
+		Genres:   []domain.Genre{drama, crime},
+	}}
+}
+
+func TestPatchWithMergePatch(t *testing.T) {
+	service := newFakeMovieService()
+	ec, recorder := newPatchRequest(t, service, patch.MediaTypeMergePatch, `{"synopsis": null, "year": 1996}`)
+
+	if err := (&MovieController{MovieService: service}).Patch(ec); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	movie := service.movie
+	if movie.Synopsis != "" || movie.Year != 1996 || movie.Title != "Heat" || movie.Duration != 170 {
+		t.Errorf("patched movie = %+v", movie)
+	}
+	if len(movie.Genres) != 2 || movie.Genres[0].Uuid != drama.Uuid || movie.Genres[1].Uuid != crime.Uuid {
+		t.Errorf("genres after the patch = %+v, want drama and crime", movie.Genres)
+	}
+}
+
+func TestPatchWithJSONPatch(t *testing.T) {
+	service := newFakeMovieService()
+	ec, recorder := newPatchRequest(t, service, patch.MediaTypeJSONPatch, `[
+		{"op": "test", "path": "/genre_ids/1", "value": "`+crime.Uuid.String()+`"},
+		{"op": "remove", "path": "/genre_ids/1"},
+		{"op": "replace", "path": "/title", "value": "Heat (1995)"}
+	]`)
+
+	if err := (&MovieController{MovieService: service}).Patch(ec); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	movie := service.movie
+	if movie.Title != "Heat (1995)" || movie.Synopsis != "A heist." {
+		t.Errorf("patched movie = %+v", movie)
+	}
+	if len(movie.Genres) != 1 || movie.Genres[0].Uuid != drama.Uuid {
+		t.Errorf("genres after the patch = %+v, want drama", movie.Genres)
+	}
+}
+
+func TestPatchErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{name: "plain JSON", contentType: echo.MIMEApplicationJSON, body: `{"title": "Heat"}`, status: http.StatusUnsupportedMediaType},
+		{name: "malformed merge patch", contentType: patch.MediaTypeMergePatch, body: `{"title":`, status: http.StatusBadRequest},
+		{name: "malformed JSON patch", contentType: patch.MediaTypeJSONPatch, body: `{"op": "remove", "path": "/title"}`, status: http.StatusBadRequest},
+		{name: "failed test", contentType: patch.MediaTypeJSONPatch, body: `[{"op": "test", "path": "/title", "value": "Ronin"}]`, status: http.StatusConflict},
+		{name: "field outside the patchable ones", contentType: patch.MediaTypeMergePatch, body: `{"rating": 9}`, status: http.StatusUnprocessableEntity},
+		{name: "value of another type", contentType: patch.MediaTypeMergePatch, body: `{"year": "1995"}`, status: http.StatusUnprocessableEntity},
+		{name: "required field removed", contentType: patch.MediaTypeJSONPatch, body: `[{"op": "remove", "path": "/title"}]`, status: http.StatusBadRequest},
+		{name: "no genres left", contentType: patch.MediaTypeMergePatch, body: `{"genre_ids": []}`, status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		service := newFakeMovieService()
+		ec, _ := newPatchRequest(t, service, test.contentType, test.body)
+
+		err := (&MovieController{MovieService: service}).Patch(ec)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != test.status {
+			t.Errorf("%s: error %v, want status %d", test.name, err, test.status)
+			continue
+		}
+		if service.movie.Title != "Heat" || len(service.movie.Genres) != 2 {
+			t.Errorf("%s: the movie changed to %+v", test.name, service.movie)
+		}
+	}
+}
@@ -17,3 +17,32 @@ type updateRequest struct {
 	Synopsis string      `json:"synopsis" form:"synopsis" validate:"omitempty"`
 	GenreIDs []uuid.UUID `json:"genre_ids" form:"genre_ids" validate:"omitempty,min=1"`
 }
+
+// patchRequest holds the fields of a movie a PATCH request can change, the patch applies to its JSON
+type patchRequest struct {
+	Title    string      `json:"title" validate:"required"`
+	Duration int32       `json:"duration" validate:"required"`
+	Year     int32       `json:"year" validate:"required"`
+	Synopsis string      `json:"synopsis" validate:"omitempty"`
+	GenreIDs []uuid.UUID `json:"genre_ids" validate:"required,min=1"`
+}
+
+type indexRequest struct {
+	YearFrom       int32   `query:"year_from" validate:"omitempty,min=0"`
+	YearTo         int32   `query:"year_to" validate:"omitempty,min=0"`
+	DurationFrom   int32   `query:"duration_from" validate:"omitempty,min=0"`
+	DurationTo     int32   `query:"duration_to" validate:"omitempty,min=0"`
+	GenreIDs       string  `query:"genre_ids" validate:"omitempty"`
+	GenreMatch     string  `query:"genre_match" validate:"omitempty,oneof=any all"`
+	MinRating      float32 `query:"min_rating" validate:"omitempty,gt=0"`
+	CreatedAfter   string  `query:"created_after" validate:"omitempty"`
+	IncludeDeleted bool    `query:"include_deleted" validate:"omitempty"`
+	Release        string  `query:"release" validate:"omitempty,oneof=released upcoming"`
+	Region         string  `query:"region" validate:"omitempty,iso3166_1_alpha2"`
+	Sort           string  `query:"sort" validate:"omitempty"`
+}
+
+type exportRequest struct {
+	indexRequest
+	Format string `query:"format" validate:"omitempty,oneof=csv ndjson parquet"`
+}
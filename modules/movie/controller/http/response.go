@@ -0,0 +1,31 @@
+package http
+
+import (
+	"go-movie-api/domain"
+	"time"
+)
+
+// trashedMovie is a movie in the trash, with the time it went there
+type trashedMovie struct {
+	domain.Movie
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// listedMovie is a movie of a listing that includes the trash, with the time it went there if it did
+type listedMovie struct {
+	domain.Movie
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func listedMovies(movies []domain.Movie) []listedMovie {
+	listed := make([]listedMovie, 0, len(movies))
+	for _, movie := range movies {
+		item := listedMovie{Movie: movie}
+		if movie.DeletedAt.Valid {
+			item.DeletedAt = &movie.DeletedAt.Time
+		}
+		listed = append(listed, item)
+	}
+
+	return listed
+}
@@ -2,12 +2,29 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"github.com/google/uuid"
+	"go-movie-api/database"
 	"go-movie-api/domain"
 	"go-movie-api/utils"
 	"go-movie-api/utils/helper"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strings"
+	"time"
+)
+
+const (
+	// searchConfig is the text search configuration used to build movies.search_vector
+	searchConfig           = "english"
+	searchHighlightOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10"
+
+	// translationSearchConfig builds movie_translations.search_vector, translations come in any language
+	translationSearchConfig = "simple"
+
+	// recentRatingsLimit is the number of ratings returned with a movie, the rest are listed from /movies/:uuid/ratings
+	recentRatingsLimit = 5
 )
 
 type movieRepository struct {
@@ -18,13 +35,19 @@ func NewMovieRepository(gormDB *gorm.DB) domain.MovieRepository {
 	return &movieRepository{db: gormDB}
 }
 
-func (repo *movieRepository) FetchPagination(ctx context.Context, pagination *utils.Pagination) ([]domain.Movie, error) {
+func (repo *movieRepository) FetchPagination(ctx context.Context, filter domain.MovieFilter, pagination *utils.Pagination) ([]domain.Movie, error) {
 	var movies []domain.Movie
 
-	result := repo.db.WithContext(ctx).
-		Scopes(utils.Paginate(movies, pagination, repo.db)).
-		Preload("Genres").
-		Order("id asc").
+	db := repo.db.WithContext(ctx)
+	if filter.IncludeDeleted {
+		db = db.Unscoped().Session(&gorm.Session{})
+	}
+
+	filtered := db.Scopes(filterMovies(filter))
+	result := db.
+		Scopes(filterMovies(filter), utils.Paginate(movies, pagination, filtered), orderMovies(filter.Sort)).
+		Scopes(preloadGenres).
+		Scopes(preloadTranslations(ctx)).
 		Find(&movies)
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
@@ -34,13 +57,143 @@ func (repo *movieRepository) FetchPagination(ctx context.Context, pagination *ut
 	return movies, nil
 }
 
+// preloadGenres preloads the genres of the movies leaving out those in the trash, which Unscoped would otherwise
+// bring along with the trashed movies since it carries over to the preloads
+func preloadGenres(db *gorm.DB) *gorm.DB {
+	return db.Preload("Genres", "genres.deleted_at IS NULL")
+}
+
+func (repo *movieRepository) FetchCursor(ctx context.Context, filter domain.MovieFilter, pagination *utils.CursorPagination) ([]domain.Movie, error) {
+	var movies []domain.Movie
+
+	db := repo.db.WithContext(ctx)
+	if filter.IncludeDeleted {
+		db = db.Unscoped().Session(&gorm.Session{})
+	}
+
+	filtered := db.Scopes(filterMovies(filter))
+	result := db.
+		Scopes(filterMovies(filter), utils.CursorPaginate(movies, "movies", filter.Sort, pagination, filtered)).
+		Scopes(preloadGenres).
+		Scopes(preloadTranslations(ctx)).
+		Find(&movies)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return utils.CursorPage(movies, pagination, repo.db)
+}
+
+// Search matches the query against the search_vector column of the movies and of their translations, parsed with
+// websearch_to_tsquery and ordered by ts_rank. Highlights are taken from the translation picked for the request locales.
+func (repo *movieRepository) Search(ctx context.Context, query string, pagination *utils.Pagination) ([]domain.MovieSearchResult, error) {
+	var rows []struct {
+		ID                uint
+		Rank              float32
+		TitleHighlight    string
+		SynopsisHighlight string
+	}
+
+	locales := utils.Locales(ctx)
+	if len(locales) == 0 {
+		// no translation has an empty locale, so the highlights fall back to the original text
+		locales = []string{""}
+	}
+
+	matches := repo.db.WithContext(ctx).
+		Where("search_vector @@ websearch_to_tsquery(?::regconfig, ?) OR id IN (?)", searchConfig, query,
+			repo.db.Table("movie_translations").Select("movie_id").
+				Where("search_vector @@ websearch_to_tsquery(?::regconfig, ?)", translationSearchConfig, query))
+	result := repo.db.WithContext(ctx).
+		Scopes(utils.Paginate(&domain.Movie{}, pagination, matches)).
+		Model(&domain.Movie{}).
+		Select(
+			"movies.id, GREATEST(ts_rank(movies.search_vector, query), coalesce(("+
+				"SELECT max(ts_rank(movie_translations.search_vector, translation_query)) FROM movie_translations "+
+				"WHERE movie_translations.movie_id = movies.id), 0)) AS rank, "+
+				"CASE WHEN localized.title IS NULL "+
+				"THEN ts_headline(?::regconfig, movies.title, query, ?) "+
+				"ELSE ts_headline(?::regconfig, localized.title, translation_query, ?) END AS title_highlight, "+
+				"CASE WHEN localized.title IS NULL "+
+				"THEN ts_headline(?::regconfig, coalesce(movies.synopsis, ''), query, ?) "+
+				"ELSE ts_headline(?::regconfig, coalesce(localized.synopsis, ''), translation_query, ?) END AS synopsis_highlight",
+			searchConfig, searchHighlightOptions, translationSearchConfig, searchHighlightOptions,
+			searchConfig, searchHighlightOptions, translationSearchConfig, searchHighlightOptions,
+		).
+		Joins("CROSS JOIN websearch_to_tsquery(?::regconfig, ?) AS query", searchConfig, query).
+		Joins("CROSS JOIN websearch_to_tsquery(?::regconfig, ?) AS translation_query", translationSearchConfig, query).
+		Joins("LEFT JOIN LATERAL (SELECT title, synopsis FROM movie_translations "+
+			"WHERE movie_translations.movie_id = movies.id AND movie_translations.locale IN ? "+
+			"ORDER BY array_position(?::text[], movie_translations.locale) LIMIT 1) AS localized ON true",
+			locales, "{"+strings.Join(locales, ",")+"}").
+		Where("movies.search_vector @@ query OR EXISTS (SELECT 1 FROM movie_translations " +
+			"WHERE movie_translations.movie_id = movies.id AND movie_translations.search_vector @@ translation_query)").
+		Order("rank desc, movies.id asc").
+		Scan(&rows)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var movies []domain.Movie
+	result = repo.db.WithContext(ctx).Preload("Genres").Scopes(preloadTranslations(ctx)).Where("id IN ?", ids).Find(&movies)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	moviesByID := make(map[uint]domain.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	searchResults := make([]domain.MovieSearchResult, 0, len(rows))
+	for _, row := range rows {
+		searchResults = append(searchResults, domain.MovieSearchResult{
+			Movie:             moviesByID[row.ID],
+			Rank:              row.Rank,
+			TitleHighlight:    row.TitleHighlight,
+			SynopsisHighlight: row.SynopsisHighlight,
+		})
+	}
+
+	return searchResults, nil
+}
+
 func (repo *movieRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Movie, error) {
 	var movie domain.Movie
 
-	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", uuid.String()).
 		Preload("Genres").
-		Preload("Ratings").
+		Preload("Ratings", func(db *gorm.DB) *gorm.DB {
+			return db.Where("ratings.moderation_status = ?", domain.ModerationStatusPublished).
+				Order("ratings.created_at DESC").Order("ratings.id DESC").Limit(recentRatingsLimit)
+		}).
 		Preload("Ratings.User").
+		Preload("Credits", func(db *gorm.DB) *gorm.DB {
+			return db.Order("credits.billing_order ASC").Order("credits.id ASC")
+		}).
+		Preload("Credits.Person").
+		Preload("Collections", func(db *gorm.DB) *gorm.DB {
+			return db.Order("collections.name ASC")
+		}).
+		Preload("Releases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("releases.country ASC").Order("releases.release_date ASC")
+		}).
+		Preload("ExternalIDs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("external_ids.source ASC")
+		}).
+		Scopes(preloadTranslations(ctx)).
 		First(&movie)
 	if result.Error != nil {
 		return domain.Movie{}, result.Error
@@ -52,7 +205,7 @@ func (repo *movieRepository) FindByID(ctx context.Context, uuid uuid.UUID) (doma
 func (repo *movieRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Movie, error) {
 	var movie domain.Movie
 
-	result := repo.db.WithContext(ctx).
+	result := database.Conn(ctx, repo.db).
 		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("uuid = ?", uuid.String()).
 		First(&movie)
@@ -67,8 +220,98 @@ func (repo *movieRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UU
 	return movie, nil
 }
 
+func (repo *movieRepository) FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]domain.Movie, error) {
+	var movies []domain.Movie
+
+	result := database.Conn(ctx, repo.db).Where("uuid IN ?", uuids).Find(&movies)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return movies, nil
+}
+
+func (repo *movieRepository) Export(ctx context.Context, filter domain.MovieFilter, fn func(domain.MovieExport) error) error {
+	db := repo.db.WithContext(ctx)
+	if filter.IncludeDeleted {
+		db = db.Unscoped().Session(&gorm.Session{})
+	}
+
+	rows, err := db.Model(&domain.Movie{}).
+		Scopes(filterMovies(filter), orderMovies(filter.Sort)).
+		Select(`movies.uuid, movies.title, movies.year, movies.duration, COALESCE(movies.synopsis, ''),
+			COALESCE((SELECT json_agg(genres.name ORDER BY genres.name) FROM movie_genres
+				JOIN genres ON genres.id = movie_genres.genre_id AND genres.deleted_at IS NULL
+				WHERE movie_genres.movie_id = movies.id), '[]'),
+			movies.rating_count, movies.rating_average, movies.rating_weighted,
+			movies.created_at, movies.updated_at, movies.deleted_at`).
+		Rows()
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie domain.MovieExport
+		var genres []byte
+		err = rows.Scan(&movie.Uuid, &movie.Title, &movie.Year, &movie.Duration, &movie.Synopsis, &genres,
+			&movie.RatingCount, &movie.RatingAverage, &movie.RatingWeighted, &movie.CreatedAt, &movie.UpdatedAt, &movie.DeletedAt)
+		if err != nil {
+			utils.Logger.Error(err.Error())
+			return err
+		}
+		if err = json.Unmarshal(genres, &movie.Genres); err != nil {
+			return err
+		}
+
+		if err = fn(movie); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (repo *movieRepository) UpdateImageKey(ctx context.Context, movieID uint, kind string, key string) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.Movie{}).
+		Where("id = ?", movieID).
+		UpdateColumn(kind+"_key", key)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *movieRepository) UpdateFields(ctx context.Context, movieID uint, fields map[string]interface{}) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.Movie{}).
+		Where("id = ?", movieID).
+		Updates(fields)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *movieRepository) ReplaceGenres(ctx context.Context, movieID uint, genres []domain.Genre) error {
+	err := database.Conn(ctx, repo.db).Model(&domain.Movie{ID: movieID}).Association("Genres").Replace(genres)
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (repo *movieRepository) Store(ctx context.Context, movie *domain.Movie) (domain.Movie, error) {
-	result := repo.db.WithContext(ctx).Clauses(clause.Returning{}).Omit("uuid").Create(&movie)
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Omit("uuid").Create(&movie)
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return domain.Movie{}, result.Error
@@ -78,11 +321,13 @@ func (repo *movieRepository) Store(ctx context.Context, movie *domain.Movie) (do
 }
 
 func (repo *movieRepository) Update(ctx context.Context, movie *domain.Movie) error {
-	if err := repo.db.Model(&movie).Association("Genres").Replace(movie.Genres); err != nil {
+	db := database.Conn(ctx, repo.db)
+
+	if err := db.Model(&movie).Association("Genres").Replace(movie.Genres); err != nil {
 		return err
 	}
 
-	result := repo.db.WithContext(ctx).Model(movie).Where("uuid = ?", movie.Uuid.String()).Updates(movie)
+	result := db.Model(movie).Where("uuid = ?", movie.Uuid.String()).Updates(movie)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -91,7 +336,7 @@ func (repo *movieRepository) Update(ctx context.Context, movie *domain.Movie) er
 }
 
 func (repo *movieRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).Delete(&domain.Movie{})
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", uuid.String()).Delete(&domain.Movie{})
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
@@ -101,7 +346,7 @@ func (repo *movieRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) err
 }
 
 func (repo *movieRepository) Delete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.Movie{})
+	result := database.Conn(ctx, repo.db).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.Movie{})
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
@@ -109,3 +354,239 @@ func (repo *movieRepository) Delete(ctx context.Context, uuid uuid.UUID) error {
 
 	return nil
 }
+
+func (repo *movieRepository) FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Movie, error) {
+	var movie domain.Movie
+
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = ?", uuid.String()).
+		First(&movie)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Movie{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Movie{}, result.Error
+	}
+
+	return movie, nil
+}
+
+func (repo *movieRepository) FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]domain.Movie, error) {
+	var movies []domain.Movie
+
+	filtered := database.Conn(ctx, repo.db).Scopes(database.Trashed(deletedBefore))
+	result := database.Conn(ctx, repo.db).
+		Scopes(database.Trashed(deletedBefore), utils.Paginate(movies, pagination, filtered)).
+		Order("deleted_at DESC, id DESC").
+		Find(&movies)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return movies, nil
+}
+
+func (repo *movieRepository) Restore(ctx context.Context, uuid uuid.UUID) error {
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Model(&domain.Movie{}).
+		Where("uuid = ? AND deleted_at IS NOT NULL", uuid.String()).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+// RefreshRatingStats recomputes count, mean and 0.5 step histogram from the published non-deleted ratings, then the
+// Bayesian weighted score (v*R + m*C) / (v + m), where C is the mean rating over every movie and m is minimumVotes
+func (repo *movieRepository) RefreshRatingStats(ctx context.Context, movieIDs []uint, minimumVotes int) error {
+	db := database.Conn(ctx, repo.db)
+
+	movieCondition, ratingCondition := "TRUE", "TRUE"
+	if movieIDs != nil {
+		movieCondition, ratingCondition = "movies.id IN @ids", "ratings.movie_id IN @ids"
+	}
+	vars := []interface{}{sql.Named("ids", movieIDs), sql.Named("minimum_votes", minimumVotes), sql.Named("published", domain.ModerationStatusPublished)}
+
+	// Locking the movies first makes concurrent rating writers queue up, so that the statements below
+	// take their snapshot after the previous writer committed and see its rating
+	if movieIDs != nil {
+		if err := db.Exec("SELECT id FROM movies WHERE id IN @ids ORDER BY id FOR UPDATE", vars...).Error; err != nil {
+			utils.Logger.Error(err.Error())
+			return err
+		}
+	}
+
+	result := db.Exec(`
+		WITH buckets AS (
+			SELECT ratings.movie_id, round(ratings.rating * 2) / 2 AS bucket, COUNT(*) AS total, SUM(ratings.rating) AS score
+			FROM ratings
+			WHERE ratings.deleted_at IS NULL AND ratings.moderation_status = @published AND `+ratingCondition+`
+			GROUP BY ratings.movie_id, round(ratings.rating * 2) / 2
+		), stats AS (
+			SELECT movie_id, SUM(total) AS total, SUM(score) / SUM(total) AS average,
+				jsonb_object_agg(to_char(bucket, 'FM990.0'), total) AS histogram
+			FROM buckets
+			GROUP BY movie_id
+		)
+		UPDATE movies
+		SET rating_count     = COALESCE(stats.total, 0),
+			rating_average   = COALESCE(stats.average, 0),
+			rating_histogram = COALESCE(stats.histogram, '{}')
+		FROM movies AS target
+			LEFT JOIN stats ON stats.movie_id = target.id
+		WHERE movies.id = target.id AND `+movieCondition, vars...)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return refreshWeighted(db, movieCondition, vars)
+}
+
+// RefreshWeightedRatings recomputes the weighted score of every movie from the current mean rating over every movie,
+// leaving alone the movies whose score did not change
+func (repo *movieRepository) RefreshWeightedRatings(ctx context.Context, minimumVotes int) error {
+	vars := []interface{}{sql.Named("minimum_votes", minimumVotes)}
+
+	return refreshWeighted(database.Conn(ctx, repo.db), "movies.rating_weighted IS DISTINCT FROM "+weightedScore, vars)
+}
+
+// weightedScore is the Bayesian weighted score of a movie, computed against the overall mean of refreshWeighted
+const weightedScore = `CASE
+			WHEN movies.rating_count = 0 THEN 0
+			ELSE ROUND((movies.rating_count * movies.rating_average + @minimum_votes * overall.average) / (movies.rating_count + @minimum_votes), 3)
+		END`
+
+func refreshWeighted(db *gorm.DB, movieCondition string, vars []interface{}) error {
+	result := db.Exec(`
+		UPDATE movies
+		SET rating_weighted = `+weightedScore+`
+		FROM (
+			SELECT COALESCE(SUM(rating_count * rating_average) / NULLIF(SUM(rating_count), 0), 0) AS average
+			FROM movies
+			WHERE deleted_at IS NULL
+		) AS overall
+		WHERE `+movieCondition, vars...)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+// preloadTranslations loads the translations of the movies and of their genres in the locales of the request
+func preloadTranslations(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		locales := utils.Locales(ctx)
+		if len(locales) == 0 {
+			return db
+		}
+
+		return db.
+			Preload("Translations", "locale IN ?", locales).
+			Preload("Genres.Translations", "locale IN ?", locales)
+	}
+}
+
+// filterMovies applies every non-zero condition of the filter
+func filterMovies(filter domain.MovieFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.YearFrom > 0 {
+			db = db.Where("movies.year >= ?", filter.YearFrom)
+		}
+		if filter.YearTo > 0 {
+			db = db.Where("movies.year <= ?", filter.YearTo)
+		}
+		if filter.DurationFrom > 0 {
+			db = db.Where("movies.duration >= ?", filter.DurationFrom)
+		}
+		if filter.DurationTo > 0 {
+			db = db.Where("movies.duration <= ?", filter.DurationTo)
+		}
+		if !filter.CreatedAfter.IsZero() {
+			db = db.Where("movies.created_at > ?", filter.CreatedAfter)
+		}
+
+		if len(filter.GenreIDs) > 0 {
+			genreMovies := db.Session(&gorm.Session{NewDB: true}).
+				Table("movie_genres").
+				Select("movie_genres.movie_id").
+				Joins("JOIN genres ON genres.id = movie_genres.genre_id").
+				Where("genres.uuid IN ?", filter.GenreIDs)
+			if filter.GenreMatch == domain.GenreMatchAll {
+				genreMovies = genreMovies.
+					Group("movie_genres.movie_id").
+					Having("COUNT(DISTINCT genres.id) = ?", len(filter.GenreIDs))
+			}
+			db = db.Where("movies.id IN (?)", genreMovies)
+		}
+
+		if filter.MinRating > 0 {
+			db = db.Where("movies.rating_average >= ?", filter.MinRating)
+		}
+		if filter.MinRatingCount > 0 {
+			db = db.Where("movies.rating_count >= ?", filter.MinRatingCount)
+		}
+
+		if filter.ReleaseStatus != "" {
+			released := releasedMovies(db, filter.ReleaseRegion, "releases.release_date <= CURRENT_DATE")
+			if filter.ReleaseStatus == domain.ReleaseStatusUpcoming {
+				scheduled := releasedMovies(db, filter.ReleaseRegion, "releases.release_date > CURRENT_DATE")
+				db = db.Where("movies.id IN (?) AND movies.id NOT IN (?)", scheduled, released)
+			} else {
+				db = db.Where("movies.id IN (?)", released)
+			}
+		}
+
+		return db
+	}
+}
+
+// releasedMovies selects the ids of the movies with a release matching condition, in region when it is set
+func releasedMovies(db *gorm.DB, region string, condition string) *gorm.DB {
+	releases := db.Session(&gorm.Session{NewDB: true}).
+		Table("releases").
+		Select("releases.movie_id").
+		Where(condition)
+	if region != "" {
+		releases = releases.Where("releases.country = ?", region)
+	}
+
+	return releases
+}
+
+// orderMovies sorts by the given fields, falling back to id so that pages are stable
+func orderMovies(sort []utils.SortField) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := make([]clause.OrderByColumn, 0, len(sort)+1)
+		sortedByID := false
+		for _, field := range sort {
+			columns = append(columns, clause.OrderByColumn{
+				Column: clause.Column{Table: "movies", Name: field.Field},
+				Desc:   field.Desc,
+			})
+			sortedByID = sortedByID || field.Field == "id"
+		}
+		if !sortedByID {
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Table: "movies", Name: "id"}})
+		}
+
+		return db.Clauses(clause.OrderBy{Columns: columns})
+	}
+}
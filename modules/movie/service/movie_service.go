@@ -6,27 +6,58 @@ import (
 	"github.com/labstack/echo/v4"
 	"go-movie-api/domain"
 	"go-movie-api/utils"
+	"go-movie-api/utils/export"
 	errors "go-movie-api/utils/helper"
+	"go-movie-api/utils/parquet"
 	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"time"
 )
 
+// movieExportColumns are the columns of a movies export, genres are their names
+var movieExportColumns = []export.Column{
+	{Name: "id", Type: parquet.String},
+	{Name: "title", Type: parquet.String},
+	{Name: "year", Type: parquet.Int32},
+	{Name: "duration", Type: parquet.Int32},
+	{Name: "synopsis", Type: parquet.String},
+	{Name: "genres", Type: parquet.String, List: true},
+	{Name: "rating_count", Type: parquet.Int32},
+	{Name: "rating_average", Type: parquet.Float},
+	{Name: "rating_weighted", Type: parquet.Float},
+	{Name: "created_at", Type: parquet.Timestamp},
+	{Name: "updated_at", Type: parquet.Timestamp},
+	{Name: "deleted_at", Type: parquet.Timestamp, Optional: true},
+}
+
 type movieService struct {
-	movieRepo domain.MovieRepository
-	genreRepo domain.GenreRepository
-	timeout   time.Duration
+	movieRepo           domain.MovieRepository
+	genreRepo           domain.GenreRepository
+	watchlistRepo       domain.WatchlistRepository
+	autocompleteService domain.AutocompleteService
+	imageService        domain.ImageService
+	provenanceRepo      domain.ProvenanceRepository
+	revisionService     domain.RevisionService
+	transactor          domain.Transactor
+	timeout             time.Duration
 }
 
-func NewMovieService(movieRepo domain.MovieRepository, genreRepo domain.GenreRepository, timeout time.Duration) domain.MovieService {
+func NewMovieService(movieRepo domain.MovieRepository, genreRepo domain.GenreRepository, watchlistRepo domain.WatchlistRepository, autocompleteService domain.AutocompleteService, imageService domain.ImageService, provenanceRepo domain.ProvenanceRepository, revisionService domain.RevisionService, transactor domain.Transactor, timeout time.Duration) domain.MovieService {
 	return &movieService{
-		movieRepo: movieRepo,
-		genreRepo: genreRepo,
-		timeout:   timeout,
+		movieRepo:           movieRepo,
+		genreRepo:           genreRepo,
+		watchlistRepo:       watchlistRepo,
+		autocompleteService: autocompleteService,
+		imageService:        imageService,
+		provenanceRepo:      provenanceRepo,
+		revisionService:     revisionService,
+		transactor:          transactor,
+		timeout:             timeout,
 	}
 }
 
-func (service *movieService) FetchPagination(ctx context.Context, page int, perPage int) ([]domain.Movie, utils.Pagination, error) {
+func (service *movieService) FetchPagination(ctx context.Context, filter domain.MovieFilter, page int, perPage int) ([]domain.Movie, utils.Pagination, error) {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
@@ -34,14 +65,80 @@ func (service *movieService) FetchPagination(ctx context.Context, page int, perP
 		Page:    page,
 		PerPage: perPage,
 	}
-	movies, err := service.movieRepo.FetchPagination(ctx, &pagination)
+	movies, err := service.movieRepo.FetchPagination(ctx, filter, &pagination)
 	if err != nil {
 		return nil, utils.Pagination{}, err
 	}
 
+	for i := range movies {
+		movies[i].Localize(utils.Locales(ctx))
+		service.imageService.Resolve(&movies[i])
+	}
+
+	return movies, pagination, nil
+}
+
+func (service *movieService) Search(ctx context.Context, query string, page int, perPage int) ([]domain.MovieSearchResult, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	movies, err := service.movieRepo.Search(ctx, query, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	for i := range movies {
+		movies[i].Localize(utils.Locales(ctx))
+		service.imageService.Resolve(&movies[i].Movie)
+	}
+
+	return movies, pagination, nil
+}
+
+func (service *movieService) FetchCursor(ctx context.Context, filter domain.MovieFilter, pagination utils.CursorPagination) ([]domain.Movie, utils.CursorPagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movies, err := service.movieRepo.FetchCursor(ctx, filter, &pagination)
+	if err != nil {
+		return nil, utils.CursorPagination{}, err
+	}
+
+	for i := range movies {
+		movies[i].Localize(utils.Locales(ctx))
+		service.imageService.Resolve(&movies[i])
+	}
+
 	return movies, pagination, nil
 }
 
+// Export has no timeout, it lasts as long as the client reads and stops when ctx is cancelled
+func (service *movieService) Export(ctx context.Context, filter domain.MovieFilter, format string, w io.Writer) error {
+	writer, err := export.NewWriter(format, w, movieExportColumns)
+	if err != nil {
+		return err
+	}
+
+	err = service.movieRepo.Export(ctx, filter, func(movie domain.MovieExport) error {
+		var deletedAt interface{}
+		if movie.DeletedAt != nil {
+			deletedAt = *movie.DeletedAt
+		}
+
+		return writer.Write(movie.Uuid.String(), movie.Title, movie.Year, movie.Duration, movie.Synopsis, movie.Genres,
+			movie.RatingCount, movie.RatingAverage, movie.RatingWeighted, movie.CreatedAt, movie.UpdatedAt, deletedAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
 func (service *movieService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Movie, error) {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
@@ -53,6 +150,8 @@ func (service *movieService) FindByID(ctx context.Context, uuid uuid.UUID) (doma
 		}
 		return domain.Movie{}, err
 	}
+	movie.Localize(utils.Locales(ctx))
+	service.imageService.Resolve(&movie)
 
 	return movie, nil
 }
@@ -74,27 +173,31 @@ func (service *movieService) Store(ctx context.Context, movie *domain.Movie) (do
 	}
 
 	movie.Genres = genres
-	result, err := service.movieRepo.Store(ctx, movie)
+	var result domain.Movie
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if result, err = service.movieRepo.Store(ctx, movie); err != nil {
+			return err
+		}
+
+		// fields entered through the API are manual edits, imports leave them as they are
+		if err = service.provenanceRepo.Record(ctx, result.ID, result.ImportableFields(), domain.FieldSourceManual); err != nil {
+			return err
+		}
+
+		return service.revisionService.Record(ctx, domain.RevisionSubjectMovie, result.ID, domain.RevisionActionCreated)
+	})
 	if err != nil {
 		return domain.Movie{}, err
 	}
+	service.autocompleteService.Invalidate()
 
 	return result, nil
 }
 
-func (service *movieService) Update(ctx context.Context, movie *domain.Movie) error {
+func (service *movieService) Update(ctx context.Context, movie *domain.Movie, version uint) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	result, err := service.movieRepo.FindByIDForUpdate(ctx, movie.Uuid)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
-		}
-		return err
-	}
-	movie.ID = result.ID
-
 	var ids []uuid.UUID
 	for _, genre := range movie.Genres {
 		ids = append(ids, genre.Uuid)
@@ -108,45 +211,191 @@ func (service *movieService) Update(ctx context.Context, movie *domain.Movie) er
 	}
 
 	movie.Genres = genres
-	if err = service.movieRepo.Update(ctx, movie); err != nil {
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		// locking the movie keeps concurrent updates from taking the same revision
+		result, err := service.movieRepo.FindByIDForUpdate(ctx, movie.Uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && result.Version != version {
+			return errors.PreconditionFailedErr
+		}
+		movie.ID = result.ID
+		movie.Version = result.Version + 1
+
+		if err = service.movieRepo.Update(ctx, movie); err != nil {
+			return err
+		}
+
+		if err = service.provenanceRepo.Record(ctx, movie.ID, movie.ImportableFields(), domain.FieldSourceManual); err != nil {
+			return err
+		}
+
+		return service.revisionService.Record(ctx, domain.RevisionSubjectMovie, movie.ID, domain.RevisionActionUpdated)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
 
 	return nil
 }
 
-func (service *movieService) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+func (service *movieService) Patch(ctx context.Context, movieUuid uuid.UUID, version uint, apply func(movie *domain.Movie) error) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.movieRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		locked, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+		if err != nil {
+			return err
+		}
+		if version != domain.AnyVersion && locked.Version != version {
+			return errors.PreconditionFailedErr
+		}
+
+		// the locked row has no genres, the patch applies to the movie with them
+		current, err := service.movieRepo.FindByID(ctx, movieUuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+
+		patched := current
+		patched.Genres = append([]domain.Genre(nil), current.Genres...)
+		if err = apply(&patched); err != nil {
+			return err
+		}
+
+		var ids []uuid.UUID
+		for _, genre := range patched.Genres {
+			ids = append(ids, genre.Uuid)
+		}
+		genres, err := service.genreRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if len(ids) != len(genres) {
+			return echo.NewHTTPError(http.StatusBadRequest, "The genre(s) is not valid.")
+		}
+
+		err = service.movieRepo.UpdateFields(ctx, locked.ID, map[string]interface{}{
+			domain.MovieFieldTitle:    patched.Title,
+			domain.MovieFieldYear:     patched.Year,
+			domain.MovieFieldDuration: patched.Duration,
+			domain.MovieFieldSynopsis: patched.Synopsis,
+			"version":                 locked.Version + 1,
+		})
+		if err != nil {
+			return err
 		}
+
+		if err = service.movieRepo.ReplaceGenres(ctx, locked.ID, genres); err != nil {
+			return err
+		}
+
+		// only the fields the patch changed become manual edits, a cleared field stays cleared by imports
+		if err = service.provenanceRepo.Record(ctx, locked.ID, current.ChangedFields(&patched), domain.FieldSourceManual); err != nil {
+			return err
+		}
+
+		return service.revisionService.Record(ctx, domain.RevisionSubjectMovie, locked.ID, domain.RevisionActionUpdated)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
+
+	return nil
+}
 
-	if err := service.movieRepo.SoftDelete(ctx, uuid); err != nil {
+func (service *movieService) SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		movie, err := service.movieRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && movie.Version != version {
+			return errors.PreconditionFailedErr
+		}
+
+		if err = service.movieRepo.SoftDelete(ctx, uuid); err != nil {
+			return err
+		}
+
+		// a removed movie cannot be watched later, its watchlist entries go with it
+		return service.watchlistRepo.DeleteByMovie(ctx, movie.ID)
+	})
+	if err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
 
 	return nil
 }
 
-func (service *movieService) Delete(ctx context.Context, uuid uuid.UUID) error {
+func (service *movieService) Delete(ctx context.Context, uuid uuid.UUID, version uint) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.movieRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
+	var movie domain.Movie
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if movie, err = service.movieRepo.FindByIDWithTrashedForUpdate(ctx, uuid); err != nil {
+			return err
+		}
+		if version != domain.AnyVersion && movie.Version != version {
+			return errors.PreconditionFailedErr
 		}
+
+		// the genre links, ratings, credits and the rest of the movie go with it through the foreign keys
+		return service.movieRepo.Delete(ctx, uuid)
+	})
+	if err != nil {
 		return err
 	}
+	service.imageService.Purge(ctx, movie)
+	service.autocompleteService.Invalidate()
+
+	return nil
+}
+
+func (service *movieService) FetchTrashed(ctx context.Context, page int, perPage int) ([]domain.Movie, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	movies, err := service.movieRepo.FetchTrashed(ctx, time.Time{}, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return movies, pagination, nil
+}
+
+// Restore brings the movie back with its genres and ratings, which the soft delete left in place
+func (service *movieService) Restore(ctx context.Context, uuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
 
-	if err := service.movieRepo.Delete(ctx, uuid); err != nil {
+	if err := service.movieRepo.Restore(ctx, uuid); err != nil {
 		return err
 	}
+	service.autocompleteService.Invalidate()
 
 	return nil
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/domain"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+// fakeMovieRepo keeps one movie, the rows it returns carry the genres only where the real repository preloads them
+type fakeMovieRepo struct {
+	domain.MovieRepository
+	movie  domain.Movie
+	genres []domain.Genre
+}
+
+func (repo *fakeMovieRepo) FindByID(_ context.Context, uuid uuid.UUID) (domain.Movie, error) {
+	if uuid != repo.movie.Uuid {
+		return domain.Movie{}, gorm.ErrRecordNotFound
+	}
+	movie := repo.movie
+	movie.Genres = append([]domain.Genre(nil), repo.genres...)
+	return movie, nil
+}
+
+func (repo *fakeMovieRepo) FindByIDForUpdate(_ context.Context, uuid uuid.UUID) (domain.Movie, error) {
+	if uuid != repo.movie.Uuid {
+		return domain.Movie{}, gorm.ErrRecordNotFound
+	}
+	return repo.movie, nil
+}
+
+func (repo *fakeMovieRepo) FindByIDs(_ context.Context, uuids []uuid.UUID) ([]domain.Movie, error) {
+	var movies []domain.Movie
+	for _, uuid := range uuids {
+		if uuid == repo.movie.Uuid {
+			movies = append(movies, repo.movie)
+		}
+	}
+	return movies, nil
+}
+
+func (repo *fakeMovieRepo) UpdateFields(_ context.Context, _ uint, fields map[string]interface{}) error {
+	repo.movie.Title = fields[domain.MovieFieldTitle].(string)
+	repo.movie.Year = fields[domain.MovieFieldYear].(int32)
+	repo.movie.Duration = fields[domain.MovieFieldDuration].(int32)
+	repo.movie.Synopsis = fields[domain.MovieFieldSynopsis].(string)
+	repo.movie.Version = fields["version"].(uint)
+	return nil
+}
+
+func (repo *fakeMovieRepo) ReplaceGenres(_ context.Context, _ uint, genres []domain.Genre) error {
+	repo.genres = genres
+	return nil
+}
+
+type fakeGenreRepo struct {
+	domain.GenreRepository
+	genres []domain.Genre
+}
+
+func (repo *fakeGenreRepo) FindByIDs(_ context.Context, uuids []uuid.UUID) ([]domain.Genre, error) {
+	var genres []domain.Genre
+	for _, uuid := range uuids {
+		for _, genre := range repo.genres {
+			if genre.Uuid == uuid {
+				genres = append(genres, genre)
+			}
+		}
+	}
+	return genres, nil
+}
+
+type fakeProvenanceRepo struct {
+	domain.ProvenanceRepository
+	fields []string
+}
+
+func (repo *fakeProvenanceRepo) Record(_ context.Context, _ uint, fields []string, _ string) error {
+	repo.fields = append(repo.fields, fields...)
+	return nil
+}
+
+type fakeRevisionService struct {
+	domain.RevisionService
+}
+
+func (service *fakeRevisionService) Record(context.Context, string, uint, string) error {
+	return nil
+}
+
+type fakeAutocompleteService struct {
+	domain.AutocompleteService
+}
+
+func (service *fakeAutocompleteService) Invalidate() {}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestPatchKeepsGenres(t *testing.T) {
+	drama := domain.Genre{ID: 1, Uuid: uuid.New(), Name: "Drama"}
+	crime := domain.Genre{ID: 2, Uuid: uuid.New(), Name: "Crime"}
+	movieRepo := &fakeMovieRepo{
+		movie:  domain.Movie{ID: 7, Uuid: uuid.New(), Title: "Heat", Year: 1995, Duration: 170, Synopsis: "A heist.", Version: 3},
+		genres: []domain.Genre{drama, crime},
+	}
+	provenanceRepo := &fakeProvenanceRepo{}
+	service := NewMovieService(movieRepo, &fakeGenreRepo{genres: []domain.Genre{drama, crime}}, nil,
+		&fakeAutocompleteService{}, nil, provenanceRepo, &fakeRevisionService{}, fakeTransactor{}, time.Second)
+
+	err := service.Patch(context.Background(), movieRepo.movie.Uuid, 3, func(movie *domain.Movie) error {
+		if len(movie.Genres) != 2 {
+			t.Errorf("the patch applies to %d genres, want 2", len(movie.Genres))
+		}
+		movie.Synopsis = ""
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if movieRepo.movie.Synopsis != "" || movieRepo.movie.Title != "Heat" || movieRepo.movie.Version != 4 {
+		t.Errorf("patched movie = %+v", movieRepo.movie)
+	}
+	if len(movieRepo.genres) != 2 || movieRepo.genres[0].Uuid != drama.Uuid || movieRepo.genres[1].Uuid != crime.Uuid {
+		t.Errorf("genres after the patch = %+v, want drama and crime", movieRepo.genres)
+	}
+	if len(provenanceRepo.fields) != 1 || provenanceRepo.fields[0] != domain.MovieFieldSynopsis {
+		t.Errorf("manual fields = %v, want only the synopsis", provenanceRepo.fields)
+	}
+}
+
+func TestPatchFailsOnAnotherVersion(t *testing.T) {
+	movieRepo := &fakeMovieRepo{movie: domain.Movie{ID: 7, Uuid: uuid.New(), Title: "Heat", Version: 3}}
+	service := NewMovieService(movieRepo, &fakeGenreRepo{}, nil, &fakeAutocompleteService{}, nil,
+		&fakeProvenanceRepo{}, &fakeRevisionService{}, fakeTransactor{}, time.Second)
+
+	err := service.Patch(context.Background(), movieRepo.movie.Uuid, 2, func(movie *domain.Movie) error {
+		t.Error("the patch applied to a stale version")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("the patch of a stale version succeeded")
+	}
+}
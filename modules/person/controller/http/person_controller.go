@@ -0,0 +1,180 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type PersonController struct {
+	domain.PersonService
+}
+
+func NewPersonController(router *echo.Echo, personService domain.PersonService) {
+	controller := &PersonController{
+		PersonService: personService,
+	}
+
+	group := router.Group("/people")
+	group.GET("", controller.Index)
+	group.GET("/:uuid", controller.Show)
+	group.POST("", controller.Store, middleware.AuthMiddleware.Handler)
+	group.PUT("/:uuid", controller.Update, middleware.AuthMiddleware.Handler)
+	group.DELETE("/:uuid", controller.Destroy, middleware.AuthMiddleware.Handler)
+
+	router.PUT("/movies/:uuid/credits", controller.ReplaceCredits, middleware.AuthMiddleware.Handler)
+}
+
+// Index lists people by name, ?q= searches the names
+func (controller *PersonController) Index(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.PersonService.FetchPagination(ec.Request().Context(), ec.QueryParam("q"), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Person, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
+func (controller *PersonController) Show(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.PersonService.FindByID(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *PersonController) Store(ec echo.Context) error {
+	var request storeRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	data, err := controller.PersonService.Store(ec.Request().Context(), &domain.Person{
+		Name:      request.Name,
+		BirthDate: parseDate(request.BirthDate),
+		Bio:       request.Bio,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+func (controller *PersonController) Update(ec echo.Context) error {
+	var request updateRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	err = controller.PersonService.Update(ec.Request().Context(), &domain.Person{
+		Uuid:      id,
+		Name:      request.Name,
+		BirthDate: parseDate(request.BirthDate),
+		Bio:       request.Bio,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+func (controller *PersonController) Destroy(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	err = controller.PersonService.SoftDelete(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+// ReplaceCredits replaces the cast and crew of the movie with the given credits
+func (controller *PersonController) ReplaceCredits(ec echo.Context) error {
+	var request replaceCreditsRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	credits := make([]domain.Credit, 0, len(request.Credits))
+	for _, credit := range request.Credits {
+		credits = append(credits, domain.Credit{
+			Role:          credit.Role,
+			CharacterName: credit.CharacterName,
+			BillingOrder:  credit.BillingOrder,
+			Person:        &domain.Person{Uuid: credit.PersonUuid},
+		})
+	}
+
+	data, err := controller.PersonService.ReplaceCredits(ec.Request().Context(), id, credits)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+// parseDate parses a validated YYYY-MM-DD date, an empty value is nil
+func parseDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	date, _ := time.Parse(time.DateOnly, value)
+	return &date
+}
@@ -0,0 +1,26 @@
+package http
+
+import "github.com/google/uuid"
+
+type storeRequest struct {
+	Name      string `json:"name" form:"name" validate:"required,max=255"`
+	BirthDate string `json:"birth_date" form:"birth_date" validate:"omitempty,datetime=2006-01-02"`
+	Bio       string `json:"bio" form:"bio" validate:"omitempty"`
+}
+
+type updateRequest struct {
+	Name      string `json:"name" form:"name" validate:"omitempty,max=255"`
+	BirthDate string `json:"birth_date" form:"birth_date" validate:"omitempty,datetime=2006-01-02"`
+	Bio       string `json:"bio" form:"bio" validate:"omitempty"`
+}
+
+type creditRequest struct {
+	PersonUuid    uuid.UUID `json:"person_id" validate:"required"`
+	Role          string    `json:"role" validate:"required,oneof=director writer actor"`
+	CharacterName string    `json:"character_name" validate:"omitempty,max=255"`
+	BillingOrder  int32     `json:"billing_order" validate:"omitempty,min=0"`
+}
+
+type replaceCreditsRequest struct {
+	Credits []creditRequest `json:"credits" validate:"dive"`
+}
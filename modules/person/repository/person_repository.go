@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+type personRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonRepository(gormDB *gorm.DB) domain.PersonRepository {
+	return &personRepository{db: gormDB}
+}
+
+func (repo *personRepository) FetchPagination(ctx context.Context, query string, pagination *utils.Pagination) ([]domain.Person, error) {
+	var people []domain.Person
+
+	filtered := repo.db.WithContext(ctx).Scopes(matchName(query))
+	result := repo.db.WithContext(ctx).
+		Scopes(matchName(query), utils.Paginate(people, pagination, filtered)).
+		Order("name ASC").
+		Order("id ASC").
+		Find(&people)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return people, nil
+}
+
+func (repo *personRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Person, error) {
+	var person domain.Person
+
+	result := repo.db.WithContext(ctx).
+		Preload("Credits", func(db *gorm.DB) *gorm.DB {
+			return db.Joins("JOIN movies ON movies.id = credits.movie_id AND movies.deleted_at IS NULL").
+				Order("movies.year DESC").
+				Order("credits.billing_order ASC")
+		}).
+		Preload("Credits.Movie").
+		Where("uuid = ?", uuid.String()).
+		First(&person)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Person{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Person{}, result.Error
+	}
+
+	return person, nil
+}
+
+func (repo *personRepository) FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]domain.Person, error) {
+	var people []domain.Person
+
+	result := database.Conn(ctx, repo.db).Where("uuid IN ?", uuids).Find(&people)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return people, nil
+}
+
+func (repo *personRepository) Store(ctx context.Context, person *domain.Person) (domain.Person, error) {
+	result := repo.db.WithContext(ctx).Clauses(clause.Returning{}).Omit("uuid", "Credits").Create(person)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return domain.Person{}, result.Error
+	}
+
+	return *person, nil
+}
+
+func (repo *personRepository) Update(ctx context.Context, person *domain.Person) error {
+	result := repo.db.WithContext(ctx).Model(person).Where("uuid = ?", person.Uuid.String()).Omit("Credits").Updates(person)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *personRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).Delete(&domain.Person{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *personRepository) ReplaceCredits(ctx context.Context, movieID uint, credits []domain.Credit) error {
+	db := database.Conn(ctx, repo.db)
+
+	if result := db.Where("movie_id = ?", movieID).Delete(&domain.Credit{}); result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if len(credits) == 0 {
+		return nil
+	}
+
+	if result := db.Omit("Movie", "Person").Create(&credits); result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+// matchName keeps the people whose name contains query, ignoring case, served by the trigram index on lower(name)
+func matchName(query string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			return db
+		}
+
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(query))
+		return db.Where("lower(people.name) LIKE ?", "%"+escaped+"%")
+	}
+}
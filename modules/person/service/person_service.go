@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"net/http"
+	"time"
+)
+
+type personService struct {
+	personRepo domain.PersonRepository
+	movieRepo  domain.MovieRepository
+	transactor domain.Transactor
+	timeout    time.Duration
+}
+
+func NewPersonService(personRepo domain.PersonRepository, movieRepo domain.MovieRepository, transactor domain.Transactor, timeout time.Duration) domain.PersonService {
+	return &personService{
+		personRepo: personRepo,
+		movieRepo:  movieRepo,
+		transactor: transactor,
+		timeout:    timeout,
+	}
+}
+
+func (service *personService) FetchPagination(ctx context.Context, query string, page int, perPage int) ([]domain.Person, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	people, err := service.personRepo.FetchPagination(ctx, query, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return people, pagination, nil
+}
+
+func (service *personService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Person, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.personRepo.FindByID(ctx, uuid)
+}
+
+func (service *personService) Store(ctx context.Context, person *domain.Person) (domain.Person, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.personRepo.Store(ctx, person)
+}
+
+func (service *personService) Update(ctx context.Context, person *domain.Person) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.personRepo.Update(ctx, person)
+}
+
+func (service *personService) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.personRepo.SoftDelete(ctx, uuid)
+}
+
+func (service *personService) ReplaceCredits(ctx context.Context, movieUuid uuid.UUID, credits []domain.Credit) ([]domain.Credit, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		movie, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+		if err != nil {
+			return err
+		}
+
+		var ids []uuid.UUID
+		for _, credit := range credits {
+			ids = append(ids, credit.Person.Uuid)
+		}
+		people, err := service.personRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		peopleByUuid := make(map[uuid.UUID]domain.Person, len(people))
+		for _, person := range people {
+			peopleByUuid[person.Uuid] = person
+		}
+
+		for i := range credits {
+			person, ok := peopleByUuid[credits[i].Person.Uuid]
+			if !ok {
+				return echo.NewHTTPError(http.StatusBadRequest, "The person(s) is not valid.")
+			}
+
+			credits[i].MovieID = movie.ID
+			credits[i].PersonID = person.ID
+			credits[i].Person = &person
+		}
+
+		return service.personRepo.ReplaceCredits(ctx, movie.ID, credits)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
@@ -1,12 +1,19 @@
 package http
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go-movie-api/domain"
 	"go-movie-api/middleware"
+	"go-movie-api/utils"
+	"go-movie-api/utils/etag"
+	"go-movie-api/utils/export"
+	"go-movie-api/utils/helper"
+	"go-movie-api/utils/patch"
 	"go-movie-api/utils/response"
 	"net/http"
+	"strconv"
 )
 
 type RatingController struct {
@@ -19,10 +26,134 @@ func NewRatingController(router *echo.Echo, genreService domain.RatingService) {
 	}
 
 	group := router.Group("/ratings")
-	group.GET("/:uuid", controller.Show)
+	group.GET("/:uuid", controller.Show, middleware.AuthMiddleware.OptionalHandler)
 	group.POST("", controller.Store, middleware.AuthMiddleware.Handler)
 	group.PUT("/:uuid", controller.Update, middleware.AuthMiddleware.Handler)
+	group.PATCH("/:uuid", controller.Patch, middleware.AuthMiddleware.Handler)
 	group.DELETE("/:uuid", controller.Destroy, middleware.AuthMiddleware.Handler)
+	group.POST("/:uuid/restore", controller.Restore, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+	group.PUT("/:uuid/votes", controller.Vote, middleware.AuthMiddleware.Handler)
+	group.DELETE("/:uuid/votes", controller.RetractVote, middleware.AuthMiddleware.Handler)
+
+	router.GET("/movies/:uuid/ratings", controller.IndexByMovie)
+	router.PUT("/movies/:uuid/my-rating", controller.Upsert, middleware.AuthMiddleware.Handler)
+	router.GET("/users/:uuid/ratings", controller.IndexByUser, middleware.AuthMiddleware.Handler)
+	router.GET("/admin/trash/ratings", controller.Trash, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+	router.GET("/exports/ratings", controller.Export, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
+}
+
+// Export streams the published ratings, of a movie or user when movie_id or user_id is given, as CSV, NDJSON or Parquet
+func (controller *RatingController) Export(ec echo.Context) error {
+	var request exportRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	filter := domain.RatingExportFilter{
+		RatingFilter: domain.RatingFilter{
+			Sort:        request.Sort,
+			WithComment: request.WithComment,
+		},
+	}
+	if request.MovieUuid != "" {
+		filter.MovieUuid = uuid.MustParse(request.MovieUuid)
+	}
+	if request.UserUuid != "" {
+		filter.UserUuid = uuid.MustParse(request.UserUuid)
+	}
+
+	format := request.Format
+	if format == "" {
+		format = export.Negotiate(ec.Request().Header.Get(echo.HeaderAccept))
+	}
+
+	download := export.NewDownload(ec.Response(), "ratings", format)
+	if err := controller.RatingService.Export(ec.Request().Context(), filter, format, download); err != nil {
+		return err
+	}
+	download.Commit()
+
+	return nil
+}
+
+func (controller *RatingController) IndexByMovie(ec echo.Context) error {
+	return controller.index(ec, controller.RatingService.FetchByMovie, controller.RatingService.FetchByMovieCursor)
+}
+
+func (controller *RatingController) IndexByUser(ec echo.Context) error {
+	return controller.index(ec, controller.RatingService.FetchByUser, controller.RatingService.FetchByUserCursor)
+}
+
+// index lists the ratings of the movie or user identified by the uuid path parameter, a page at a time with fetch
+// or, when a cursor is requested, with fetchCursor
+func (controller *RatingController) index(ec echo.Context, fetch func(ctx context.Context, uuid uuid.UUID, filter domain.RatingFilter, page int, perPage int) ([]domain.Rating, utils.Pagination, error), fetchCursor func(ctx context.Context, uuid uuid.UUID, filter domain.RatingFilter, pagination utils.CursorPagination) ([]domain.Rating, utils.CursorPagination, error)) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 20
+	}
+
+	var request indexRequest
+	if err = ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err = ec.Validate(request); err != nil {
+		return err
+	}
+
+	filter := domain.RatingFilter{
+		Sort:        request.Sort,
+		WithComment: request.WithComment,
+	}
+
+	if utils.CursorRequested(ec) {
+		cursorPagination, err := utils.NewCursorPagination(ec)
+		if err != nil {
+			return err
+		}
+
+		data, cursorPagination, err := fetchCursor(ec.Request().Context(), id, filter, cursorPagination)
+		if err != nil {
+			return err
+		}
+
+		if data == nil {
+			data = make([]domain.Rating, 0)
+		}
+
+		return ec.JSON(http.StatusOK, response.Result{
+			Meta: cursorPagination,
+			Data: data,
+		})
+	}
+
+	data, pagination, err := fetch(ec.Request().Context(), id, filter, page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Rating, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
 }
 
 func (controller *RatingController) Show(ec echo.Context) error {
@@ -31,12 +162,14 @@ func (controller *RatingController) Show(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
-	data, err := controller.RatingService.FindByID(ec.Request().Context(), id)
+	viewer, _ := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.RatingService.FindByID(ec.Request().Context(), id, viewer)
 	if err != nil {
 		return err
 	}
 
-	return ec.JSON(http.StatusOK, data)
+	return etag.JSON(ec, data.Version, data)
 }
 
 func (controller *RatingController) Store(ec echo.Context) error {
@@ -52,7 +185,7 @@ func (controller *RatingController) Store(ec echo.Context) error {
 	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
 
 	data, err := controller.RatingService.Store(ec.Request().Context(), &domain.Rating{
-		Rating:  request.Rating,
+		Rating:  *request.Rating,
 		Comment: request.Comment,
 		Movie: &domain.Movie{
 			Uuid: request.MovieUuid,
@@ -81,10 +214,101 @@ func (controller *RatingController) Update(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
-	err = controller.RatingService.Update(ec.Request().Context(), &domain.Rating{
-		Uuid:    id,
-		Rating:  request.Rating,
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	// a rating of 0 is on a scale starting at 0, the patch writes it where an update would skip the zero value
+	err = controller.RatingService.Patch(ec.Request().Context(), id, version, func(rating *domain.Rating) error {
+		if request.Rating != nil {
+			rating.Rating = *request.Rating
+		}
+		if request.Comment != "" {
+			rating.Comment = request.Comment
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+// Upsert creates or replaces the rating of the authenticated user for the movie
+func (controller *RatingController) Upsert(ec echo.Context) error {
+	var request upsertRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	movieID, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, created, err := controller.RatingService.Upsert(ec.Request().Context(), &domain.Rating{
+		Rating:  *request.Rating,
 		Comment: request.Comment,
+		Movie: &domain.Movie{
+			Uuid: movieID,
+		},
+		UserID: authUser.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		return ec.JSON(http.StatusCreated, data)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+// Patch changes the rating with a JSON Merge Patch or a JSON Patch of the fields of patchRequest, e.g.
+// {"comment": ""} empties the comment
+func (controller *RatingController) Patch(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	document, err := patch.FromRequest(ec)
+	if err != nil {
+		return err
+	}
+
+	err = controller.RatingService.Patch(ec.Request().Context(), id, version, func(rating *domain.Rating) error {
+		request := patchRequest{
+			Rating:  &rating.Rating,
+			Comment: rating.Comment,
+		}
+
+		if err := document.ApplyTo(&request); err != nil {
+			return err
+		}
+
+		if err := ec.Validate(request); err != nil {
+			return err
+		}
+
+		rating.Rating = *request.Rating
+		rating.Comment = request.Comment
+
+		return nil
 	})
 	if err != nil {
 		return err
@@ -99,10 +323,106 @@ func (controller *RatingController) Destroy(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
-	err = controller.RatingService.SoftDelete(ec.Request().Context(), id)
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	// hard=true deletes the rating for good, from the trash or not, which only admins may do
+	if hard, _ := strconv.ParseBool(ec.QueryParam("hard")); hard {
+		if !middleware.IsAdmin(ec) {
+			return helper.ForbiddenErr
+		}
+		err = controller.RatingService.Delete(ec.Request().Context(), id, version)
+	} else {
+		err = controller.RatingService.SoftDelete(ec.Request().Context(), id, version)
+	}
 	if err != nil {
 		return err
 	}
 
 	return ec.JSON(http.StatusOK, response.DeleteSuccess)
 }
+
+// Trash lists the soft deleted ratings, the most recently deleted first
+func (controller *RatingController) Trash(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.RatingService.FetchTrashed(ec.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	trashed := make([]trashedRating, 0, len(data))
+	for _, rating := range data {
+		trashed = append(trashed, trashedRating{Rating: rating, DeletedAt: rating.DeletedAt.Time})
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: trashed,
+	})
+}
+
+func (controller *RatingController) Restore(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	if err = controller.RatingService.Restore(ec.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.RestoreSuccess)
+}
+
+// Vote records whether the authenticated user finds the review helpful, replacing their previous vote
+func (controller *RatingController) Vote(ec echo.Context) error {
+	var request voteRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.RatingService.Vote(ec.Request().Context(), id, authUser.ID, *request.Helpful)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *RatingController) RetractVote(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.RatingService.RetractVote(ec.Request().Context(), id, authUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
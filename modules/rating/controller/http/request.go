@@ -3,12 +3,40 @@ package http
 import "github.com/google/uuid"
 
 type storeRequest struct {
-	Rating    float32   `json:"rating" form:"rating" validate:"required"`
+	Rating    *float32  `json:"rating" form:"rating" validate:"required,rating_scale"`
 	Comment   string    `json:"comment" form:"comment" validate:"omitempty"`
 	MovieUuid uuid.UUID `json:"movie_id" form:"movie_id" validate:"required"`
 }
 
 type updateRequest struct {
-	Rating  float32 `json:"rating" form:"rating" validate:"omitempty"`
-	Comment string  `json:"comment" form:"comment" validate:"omitempty"`
+	Rating  *float32 `json:"rating" form:"rating" validate:"omitempty,rating_scale"`
+	Comment string   `json:"comment" form:"comment" validate:"omitempty"`
+}
+
+// patchRequest holds the fields of a rating a PATCH request can change, the patch applies to its JSON
+type patchRequest struct {
+	Rating  *float32 `json:"rating" validate:"required,rating_scale"`
+	Comment string   `json:"comment" validate:"omitempty"`
+}
+
+type upsertRequest struct {
+	Rating  *float32 `json:"rating" form:"rating" validate:"required,rating_scale"`
+	Comment string   `json:"comment" form:"comment" validate:"omitempty"`
+}
+
+type indexRequest struct {
+	Sort        string `query:"sort" validate:"omitempty,oneof=newest highest lowest helpful"`
+	WithComment bool   `query:"with_comment" validate:"omitempty"`
+}
+
+type voteRequest struct {
+	Helpful *bool `json:"helpful" form:"helpful" validate:"required"`
+}
+
+type exportRequest struct {
+	Sort        string `query:"sort" validate:"omitempty,oneof=newest highest lowest helpful"`
+	WithComment bool   `query:"with_comment" validate:"omitempty"`
+	MovieUuid   string `query:"movie_id" validate:"omitempty,uuid"`
+	UserUuid    string `query:"user_id" validate:"omitempty,uuid"`
+	Format      string `query:"format" validate:"omitempty,oneof=csv ndjson parquet"`
 }
@@ -0,0 +1,12 @@
+package http
+
+import (
+	"go-movie-api/domain"
+	"time"
+)
+
+// trashedRating is a rating in the trash, with the time it went there
+type trashedRating struct {
+	domain.Rating
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"github.com/google/uuid"
+	"go-movie-api/database"
 	"go-movie-api/domain"
 	"go-movie-api/utils"
 	"go-movie-api/utils/helper"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
 type ratingRepository struct {
@@ -18,10 +20,98 @@ func NewRatingRepository(gormDB *gorm.DB) domain.RatingRepository {
 	return &ratingRepository{db: gormDB}
 }
 
+func (repo *ratingRepository) FetchByMovie(ctx context.Context, movieID uint, filter domain.RatingFilter, pagination *utils.Pagination) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+
+	filtered := database.Conn(ctx, repo.db).Where("movie_id = ?", movieID).Scopes(filterRatings(filter))
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ?", movieID).
+		Scopes(filterRatings(filter), utils.Paginate(ratings, pagination, filtered), orderRatings(filter.Sort)).
+		Preload("User").
+		Find(&ratings)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return ratings, nil
+}
+
+func (repo *ratingRepository) FetchByUser(ctx context.Context, userID uint, filter domain.RatingFilter, pagination *utils.Pagination) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+
+	filtered := database.Conn(ctx, repo.db).Where("user_id = ?", userID).Scopes(filterRatings(filter))
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ?", userID).
+		Scopes(filterRatings(filter), utils.Paginate(ratings, pagination, filtered), orderRatings(filter.Sort)).
+		Preload("Movie").
+		Find(&ratings)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return ratings, nil
+}
+
+func (repo *ratingRepository) FetchByMovieCursor(ctx context.Context, movieID uint, filter domain.RatingFilter, pagination *utils.CursorPagination) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+
+	filtered := database.Conn(ctx, repo.db).Where("movie_id = ?", movieID).Scopes(filterRatings(filter))
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ?", movieID).
+		Scopes(filterRatings(filter), utils.CursorPaginate(ratings, "ratings", ratingSort(filter.Sort), pagination, filtered)).
+		Preload("User").
+		Find(&ratings)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return utils.CursorPage(ratings, pagination, repo.db)
+}
+
+func (repo *ratingRepository) FetchByUserCursor(ctx context.Context, userID uint, filter domain.RatingFilter, pagination *utils.CursorPagination) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+
+	filtered := database.Conn(ctx, repo.db).Where("user_id = ?", userID).Scopes(filterRatings(filter))
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ?", userID).
+		Scopes(filterRatings(filter), utils.CursorPaginate(ratings, "ratings", ratingSort(filter.Sort), pagination, filtered)).
+		Preload("Movie").
+		Find(&ratings)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return utils.CursorPage(ratings, pagination, repo.db)
+}
+
+func (repo *ratingRepository) FetchByModerationStatus(ctx context.Context, status string, pagination *utils.Pagination) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+
+	filtered := database.Conn(ctx, repo.db).Where("moderation_status = ?", status)
+	result := database.Conn(ctx, repo.db).
+		Where("moderation_status = ?", status).
+		Scopes(utils.Paginate(ratings, pagination, filtered)).
+		Preload("User").
+		Preload("Movie").
+		Order("ratings.updated_at ASC").
+		Order("ratings.id ASC").
+		Find(&ratings)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return ratings, nil
+}
+
 func (repo *ratingRepository) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Rating, error) {
 	var rating domain.Rating
 
-	result := repo.db.WithContext(ctx).
+	result := database.Conn(ctx, repo.db).
 		Preload("User").
 		Preload("Movie").
 		Where("uuid = ?", uuid.String()).
@@ -39,7 +129,7 @@ func (repo *ratingRepository) FindByID(ctx context.Context, uuid uuid.UUID) (dom
 func (repo *ratingRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Rating, error) {
 	var rating domain.Rating
 
-	result := repo.db.WithContext(ctx).
+	result := database.Conn(ctx, repo.db).
 		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("uuid = ?", uuid.String()).
 		First(&rating)
@@ -54,9 +144,66 @@ func (repo *ratingRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.U
 	return rating, nil
 }
 
+func (repo *ratingRepository) FindByUserAndMovie(ctx context.Context, userID uint, movieID uint) (domain.Rating, error) {
+	var rating domain.Rating
+
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		First(&rating)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Rating{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Rating{}, result.Error
+	}
+
+	return rating, nil
+}
+
+func (repo *ratingRepository) Export(ctx context.Context, filter domain.RatingExportFilter, fn func(domain.RatingExport) error) error {
+	db := repo.db.WithContext(ctx).
+		Model(&domain.Rating{}).
+		Joins("JOIN movies ON movies.id = ratings.movie_id AND movies.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = ratings.user_id")
+	if filter.MovieUuid != uuid.Nil {
+		db = db.Where("movies.uuid = ?", filter.MovieUuid.String())
+	}
+	if filter.UserUuid != uuid.Nil {
+		db = db.Where("users.uuid = ?", filter.UserUuid.String())
+	}
+
+	rows, err := db.
+		Scopes(filterRatings(filter.RatingFilter), orderRatings(filter.Sort)).
+		Select(`ratings.uuid, movies.uuid, users.uuid, ratings.rating, COALESCE(ratings.comment, ''),
+			ratings.helpful_count, ratings.unhelpful_count, ratings.created_at, ratings.updated_at`).
+		Rows()
+	if err != nil {
+		utils.Logger.Error(err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rating domain.RatingExport
+		err = rows.Scan(&rating.Uuid, &rating.MovieUuid, &rating.UserUuid, &rating.Rating, &rating.Comment,
+			&rating.HelpfulCount, &rating.UnhelpfulCount, &rating.CreatedAt, &rating.UpdatedAt)
+		if err != nil {
+			utils.Logger.Error(err.Error())
+			return err
+		}
+
+		if err = fn(rating); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (repo *ratingRepository) Store(ctx context.Context, rating *domain.Rating) (domain.Rating, error) {
 	var movie domain.Movie
-	result := repo.db.WithContext(ctx).Where("uuid = ?", rating.Movie.Uuid.String()).First(&movie)
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", rating.Movie.Uuid.String()).First(&movie)
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return domain.Rating{}, result.Error
@@ -64,8 +211,11 @@ func (repo *ratingRepository) Store(ctx context.Context, rating *domain.Rating)
 
 	rating.MovieID = movie.ID
 	rating.Movie = &movie
-	result = repo.db.WithContext(ctx).Clauses(clause.Returning{}).Omit("uuid").Create(rating)
+	result = database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Omit("uuid").Create(rating)
 	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return domain.Rating{}, helper.ConflictErr
+		}
 		utils.Logger.Error(result.Error.Error())
 		return domain.Rating{}, result.Error
 	}
@@ -73,8 +223,19 @@ func (repo *ratingRepository) Store(ctx context.Context, rating *domain.Rating)
 	return *rating, nil
 }
 
+// StoreBatch inserts ratings whose MovieID and UserID are already resolved, batchSize rows per statement
+func (repo *ratingRepository) StoreBatch(ctx context.Context, ratings []domain.Rating, batchSize int) error {
+	result := database.Conn(ctx, repo.db).Omit("uuid", "Movie", "User").CreateInBatches(ratings, batchSize)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
 func (repo *ratingRepository) Update(ctx context.Context, rating *domain.Rating) error {
-	result := repo.db.WithContext(ctx).Model(rating).Where("uuid = ?", rating.Uuid.String()).Updates(rating)
+	result := database.Conn(ctx, repo.db).Model(rating).Where("uuid = ?", rating.Uuid.String()).Updates(rating)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -82,8 +243,21 @@ func (repo *ratingRepository) Update(ctx context.Context, rating *domain.Rating)
 	return nil
 }
 
+func (repo *ratingRepository) Replace(ctx context.Context, rating *domain.Rating) error {
+	result := database.Conn(ctx, repo.db).Model(rating).
+		Where("uuid = ?", rating.Uuid.String()).
+		Select("rating", "comment", "moderation_status", "updated_at", "version").
+		Updates(rating)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
 func (repo *ratingRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).Delete(&domain.Rating{})
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", uuid.String()).Delete(&domain.Rating{})
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
@@ -93,7 +267,96 @@ func (repo *ratingRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) er
 }
 
 func (repo *ratingRepository) Delete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.Rating{})
+	result := database.Conn(ctx, repo.db).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.Rating{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *ratingRepository) FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Rating, error) {
+	var rating domain.Rating
+
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = ?", uuid.String()).
+		First(&rating)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Rating{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Rating{}, result.Error
+	}
+
+	return rating, nil
+}
+
+func (repo *ratingRepository) FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+
+	filtered := database.Conn(ctx, repo.db).Scopes(database.Trashed(deletedBefore))
+	result := database.Conn(ctx, repo.db).
+		Scopes(database.Trashed(deletedBefore), utils.Paginate(ratings, pagination, filtered)).
+		Preload("Movie", withTrashed).
+		Preload("User", withTrashed).
+		Order("deleted_at DESC, id DESC").
+		Find(&ratings)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return ratings, nil
+}
+
+func (repo *ratingRepository) Restore(ctx context.Context, uuid uuid.UUID) error {
+	// a rating whose movie or user is in the trash stays there, as if it was not found
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Model(&domain.Rating{}).
+		Where("uuid = ? AND deleted_at IS NOT NULL", uuid.String()).
+		Where("movie_id IN (SELECT id FROM movies WHERE deleted_at IS NULL)").
+		Where("user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)").
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *ratingRepository) FetchMovieIDsByUser(ctx context.Context, userID uint) ([]uint, error) {
+	var movieIDs []uint
+
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.Rating{}).
+		Where("user_id = ?", userID).
+		Pluck("movie_id", &movieIDs)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return movieIDs, nil
+}
+
+func (repo *ratingRepository) UpdateModerationStatus(ctx context.Context, ratingID uint, status string) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.Rating{}).
+		Where("id = ?", ratingID).
+		Update("moderation_status", status)
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
@@ -101,3 +364,64 @@ func (repo *ratingRepository) Delete(ctx context.Context, uuid uuid.UUID) error
 
 	return nil
 }
+
+func (repo *ratingRepository) RefreshVoteCounts(ctx context.Context, ratingID uint) error {
+	result := database.Conn(ctx, repo.db).Exec(`
+		UPDATE ratings
+		SET helpful_count   = (SELECT COUNT(*) FROM rating_votes WHERE rating_votes.rating_id = ratings.id AND rating_votes.helpful),
+		    unhelpful_count = (SELECT COUNT(*) FROM rating_votes WHERE rating_votes.rating_id = ratings.id AND NOT rating_votes.helpful)
+		WHERE ratings.id = ?`, ratingID)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+// withTrashed preloads the movie or user of a trashed rating even when it went to the trash too
+func withTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// filterRatings keeps the published reviews matching the filter
+func filterRatings(filter domain.RatingFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("ratings.moderation_status = ?", domain.ModerationStatusPublished)
+		if filter.WithComment {
+			db = db.Where("ratings.comment <> ''")
+		}
+
+		return db
+	}
+}
+
+// ratingSort returns the columns of the requested sort, newest first by default, with id as the tie breaker
+func ratingSort(sort string) []utils.SortField {
+	var fields []utils.SortField
+	switch sort {
+	case domain.RatingSortHighest:
+		fields = []utils.SortField{{Field: "rating", Desc: true}}
+	case domain.RatingSortLowest:
+		fields = []utils.SortField{{Field: "rating"}}
+	case domain.RatingSortHelpful:
+		fields = []utils.SortField{{Field: "helpful_score", Desc: true}, {Field: "helpful_count", Desc: true}}
+	}
+
+	return append(fields, utils.SortField{Field: "created_at", Desc: true}, utils.SortField{Field: "id", Desc: true})
+}
+
+// orderRatings orders by the requested sort, the order cursor pages follow as well
+func orderRatings(sort string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := make([]clause.OrderByColumn, 0, 4)
+		for _, field := range ratingSort(sort) {
+			columns = append(columns, clause.OrderByColumn{
+				Column: clause.Column{Table: "ratings", Name: field.Field},
+				Desc:   field.Desc,
+			})
+		}
+
+		return db.Clauses(clause.OrderBy{Columns: columns})
+	}
+}
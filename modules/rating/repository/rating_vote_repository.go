@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ratingVoteRepository struct {
+	db *gorm.DB
+}
+
+func NewRatingVoteRepository(gormDB *gorm.DB) domain.RatingVoteRepository {
+	return &ratingVoteRepository{db: gormDB}
+}
+
+func (repo *ratingVoteRepository) Upsert(ctx context.Context, vote *domain.RatingVote) error {
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "rating_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+		}, clause.Returning{}).
+		Create(vote)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *ratingVoteRepository) Delete(ctx context.Context, ratingID uint, userID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Where("rating_id = ? AND user_id = ?", ratingID, userID).
+		Delete(&domain.RatingVote{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
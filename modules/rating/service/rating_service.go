@@ -2,30 +2,164 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
 	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/export"
 	errors "go-movie-api/utils/helper"
+	"go-movie-api/utils/parquet"
 	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"time"
 )
 
+// ratingExportColumns are the columns of a ratings export, the movie and user are given by id
+var ratingExportColumns = []export.Column{
+	{Name: "id", Type: parquet.String},
+	{Name: "movie_id", Type: parquet.String},
+	{Name: "user_id", Type: parquet.String},
+	{Name: "rating", Type: parquet.Float},
+	{Name: "comment", Type: parquet.String},
+	{Name: "helpful_count", Type: parquet.Int32},
+	{Name: "unhelpful_count", Type: parquet.Int32},
+	{Name: "created_at", Type: parquet.Timestamp},
+	{Name: "updated_at", Type: parquet.Timestamp},
+}
+
 type ratingService struct {
 	ratingRepo domain.RatingRepository
 	movieRepo  domain.MovieRepository
+	userRepo   domain.UserRepository
+	voteRepo   domain.RatingVoteRepository
+	moderation domain.ModerationService
+	transactor domain.Transactor
 	timeout    time.Duration
 }
 
-func NewRatingService(ratingRepo domain.RatingRepository, movieRepo domain.MovieRepository, timeout time.Duration) domain.RatingService {
+func NewRatingService(ratingRepo domain.RatingRepository, movieRepo domain.MovieRepository, userRepo domain.UserRepository, voteRepo domain.RatingVoteRepository, moderation domain.ModerationService, transactor domain.Transactor, timeout time.Duration) domain.RatingService {
 	return &ratingService{
 		ratingRepo: ratingRepo,
 		movieRepo:  movieRepo,
+		userRepo:   userRepo,
+		voteRepo:   voteRepo,
+		moderation: moderation,
+		transactor: transactor,
 		timeout:    timeout,
 	}
 }
 
-func (service *ratingService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.Rating, error) {
+func (service *ratingService) FetchByMovie(ctx context.Context, movieUuid uuid.UUID, filter domain.RatingFilter, page int, perPage int) ([]domain.Rating, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	// only the id of the movie is needed, FindByID would load its genres and recent ratings as well
+	movies, err := service.movieRepo.FindByIDs(ctx, []uuid.UUID{movieUuid})
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	if len(movies) == 0 {
+		return nil, utils.Pagination{}, errors.NotFoundErr
+	}
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	ratings, err := service.ratingRepo.FetchByMovie(ctx, movies[0].ID, filter, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return ratings, pagination, nil
+}
+
+func (service *ratingService) FetchByUser(ctx context.Context, userUuid uuid.UUID, filter domain.RatingFilter, page int, perPage int) ([]domain.Rating, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	user, err := service.userRepo.FindByID(ctx, userUuid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.Pagination{}, errors.NotFoundErr
+		}
+		return nil, utils.Pagination{}, err
+	}
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	ratings, err := service.ratingRepo.FetchByUser(ctx, user.ID, filter, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return ratings, pagination, nil
+}
+
+func (service *ratingService) FetchByMovieCursor(ctx context.Context, movieUuid uuid.UUID, filter domain.RatingFilter, pagination utils.CursorPagination) ([]domain.Rating, utils.CursorPagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movies, err := service.movieRepo.FindByIDs(ctx, []uuid.UUID{movieUuid})
+	if err != nil {
+		return nil, utils.CursorPagination{}, err
+	}
+	if len(movies) == 0 {
+		return nil, utils.CursorPagination{}, errors.NotFoundErr
+	}
+
+	ratings, err := service.ratingRepo.FetchByMovieCursor(ctx, movies[0].ID, filter, &pagination)
+	if err != nil {
+		return nil, utils.CursorPagination{}, err
+	}
+
+	return ratings, pagination, nil
+}
+
+func (service *ratingService) FetchByUserCursor(ctx context.Context, userUuid uuid.UUID, filter domain.RatingFilter, pagination utils.CursorPagination) ([]domain.Rating, utils.CursorPagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	user, err := service.userRepo.FindByID(ctx, userUuid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.CursorPagination{}, errors.NotFoundErr
+		}
+		return nil, utils.CursorPagination{}, err
+	}
+
+	ratings, err := service.ratingRepo.FetchByUserCursor(ctx, user.ID, filter, &pagination)
+	if err != nil {
+		return nil, utils.CursorPagination{}, err
+	}
+
+	return ratings, pagination, nil
+}
+
+// Export has no timeout, it lasts as long as the client reads and stops when ctx is cancelled
+func (service *ratingService) Export(ctx context.Context, filter domain.RatingExportFilter, format string, w io.Writer) error {
+	writer, err := export.NewWriter(format, w, ratingExportColumns)
+	if err != nil {
+		return err
+	}
+
+	err = service.ratingRepo.Export(ctx, filter, func(rating domain.RatingExport) error {
+		return writer.Write(rating.Uuid.String(), rating.MovieUuid.String(), rating.UserUuid.String(), rating.Rating, rating.Comment,
+			rating.HelpfulCount, rating.UnhelpfulCount, rating.CreatedAt, rating.UpdatedAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (service *ratingService) FindByID(ctx context.Context, uuid uuid.UUID, viewer *domain.User) (domain.Rating, error) {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
@@ -37,6 +171,11 @@ func (service *ratingService) FindByID(ctx context.Context, uuid uuid.UUID) (dom
 		return domain.Rating{}, err
 	}
 
+	// a review held for moderation or rejected does not exist for the public
+	if rating.ModerationStatus != domain.ModerationStatusPublished && (viewer == nil || (viewer.ID != rating.UserID && !viewer.IsAdmin)) {
+		return domain.Rating{}, errors.NotFoundErr
+	}
+
 	return rating, nil
 }
 
@@ -44,74 +183,349 @@ func (service *ratingService) Store(ctx context.Context, rating *domain.Rating)
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	movie, err := service.movieRepo.FindByID(ctx, rating.Movie.Uuid)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return domain.Rating{}, echo.NewHTTPError(http.StatusBadRequest, "The movie is not valid.")
+	var result domain.Rating
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		// the movie row lock serializes concurrent ratings of the same movie, so the lookup below cannot race
+		movie, err := service.findMovieForUpdate(ctx, rating.Movie.Uuid)
+		if err != nil {
+			return err
 		}
 
-		return domain.Rating{}, err
-	}
+		existing, err := service.ratingRepo.FindByUserAndMovie(ctx, rating.UserID, movie.ID)
+		if err == nil {
+			return errors.NewLinkedError(errors.ConflictErr, "/ratings/"+existing.Uuid.String())
+		}
+		if err != errors.NotFoundErr {
+			return err
+		}
+
+		rating.MovieID = movie.ID
+		service.screen(rating)
+		if result, err = service.ratingRepo.Store(ctx, rating); err != nil {
+			return err
+		}
+		result.Movie = &movie
 
-	result, err := service.ratingRepo.Store(ctx, rating)
+		return service.refreshRatingStats(ctx, movie.ID)
+	})
 	if err != nil {
 		return domain.Rating{}, err
 	}
-	result.Movie = &movie
 
 	return result, nil
 }
 
-func (service *ratingService) Update(ctx context.Context, rating *domain.Rating) error {
+func (service *ratingService) Upsert(ctx context.Context, rating *domain.Rating) (domain.Rating, bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.ratingRepo.FindByIDForUpdate(ctx, rating.Uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NotFoundErr
+	var result domain.Rating
+	var created bool
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		movie, err := service.findMovieForUpdate(ctx, rating.Movie.Uuid)
+		if err != nil {
+			return err
 		}
-		return err
+
+		existing, err := service.ratingRepo.FindByUserAndMovie(ctx, rating.UserID, movie.ID)
+		switch {
+		case err == nil:
+			existing.Rating = rating.Rating
+			existing.Comment = rating.Comment
+			existing.UpdatedAt = time.Now()
+			existing.Version++
+			service.screen(&existing)
+			if err = service.ratingRepo.Replace(ctx, &existing); err != nil {
+				return err
+			}
+			result = existing
+		case err == errors.NotFoundErr:
+			rating.MovieID = movie.ID
+			service.screen(rating)
+			if result, err = service.ratingRepo.Store(ctx, rating); err != nil {
+				return err
+			}
+			created = true
+		default:
+			return err
+		}
+		result.Movie = &movie
+
+		return service.refreshRatingStats(ctx, movie.ID)
+	})
+	if err != nil {
+		return domain.Rating{}, false, err
 	}
 
-	if err := service.ratingRepo.Update(ctx, rating); err != nil {
-		return err
+	return result, created, nil
+}
+
+func (service *ratingService) Update(ctx context.Context, rating *domain.Rating, version uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.ratingRepo.FindByIDForUpdate(ctx, rating.Uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errors.PreconditionFailedErr
+		}
+		rating.Version = existing.Version + 1
+
+		service.screen(rating)
+		if err = service.ratingRepo.Update(ctx, rating); err != nil {
+			return err
+		}
+
+		return service.refreshRatingStats(ctx, existing.MovieID)
+	})
+}
+
+func (service *ratingService) Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(rating *domain.Rating) error) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.ratingRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errors.PreconditionFailedErr
+		}
+
+		patched := existing
+		if err = apply(&patched); err != nil {
+			return err
+		}
+		patched.UpdatedAt = time.Now()
+		patched.Version = existing.Version + 1
+
+		// Replace writes the comment even when the patch cleared it
+		service.screen(&patched)
+		if err = service.ratingRepo.Replace(ctx, &patched); err != nil {
+			return err
+		}
+
+		return service.refreshRatingStats(ctx, existing.MovieID)
+	})
+}
+
+func (service *ratingService) SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.ratingRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errors.PreconditionFailedErr
+		}
+
+		if err = service.ratingRepo.SoftDelete(ctx, uuid); err != nil {
+			return err
+		}
+
+		return service.refreshRatingStats(ctx, existing.MovieID)
+	})
+}
+
+func (service *ratingService) Delete(ctx context.Context, uuid uuid.UUID, version uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.ratingRepo.FindByIDWithTrashedForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errors.PreconditionFailedErr
+		}
+
+		// its votes, reports and comments go with it through the foreign keys
+		if err = service.ratingRepo.Delete(ctx, uuid); err != nil {
+			return err
+		}
+
+		return service.refreshRatingStats(ctx, existing.MovieID)
+	})
+}
+
+func (service *ratingService) FetchTrashed(ctx context.Context, page int, perPage int) ([]domain.Rating, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	ratings, err := service.ratingRepo.FetchTrashed(ctx, time.Time{}, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
 	}
 
-	return nil
+	return ratings, pagination, nil
 }
 
-func (service *ratingService) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+// Restore brings the rating back into the aggregates of its movie, the movie and the user must not be in the trash
+func (service *ratingService) Restore(ctx context.Context, uuid uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.ratingRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.ratingRepo.FindByIDWithTrashedForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if !existing.DeletedAt.Valid {
+			return errors.NotFoundErr
+		}
+
+		if err = service.ratingRepo.Restore(ctx, uuid); err != nil {
+			if err == errors.NotFoundErr {
+				return echo.NewHTTPError(http.StatusConflict, "The movie or the user of the rating is in the trash.")
+			}
+			return err
+		}
+
+		return service.refreshRatingStats(ctx, existing.MovieID)
+	})
+}
+
+func (service *ratingService) Vote(ctx context.Context, uuid uuid.UUID, userID uint, helpful bool) (domain.Rating, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	var result domain.Rating
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		// locking the rating serializes concurrent votes on it, so that the recomputed counts include every vote
+		rating, err := service.ratingRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+
+		// reviews held by moderation are hidden from voters, as FindByID hides them
+		if rating.ModerationStatus != domain.ModerationStatusPublished {
 			return errors.NotFoundErr
 		}
-		return err
-	}
 
-	if err := service.ratingRepo.SoftDelete(ctx, uuid); err != nil {
+		if rating.UserID == userID {
+			return echo.NewHTTPError(http.StatusForbidden, "You cannot vote on your own review.")
+		}
+
+		if err = service.voteRepo.Upsert(ctx, &domain.RatingVote{
+			RatingID: rating.ID,
+			UserID:   userID,
+			Helpful:  helpful,
+		}); err != nil {
+			return err
+		}
+
+		result, err = service.refreshVoteCounts(ctx, rating)
 		return err
+	})
+	if err != nil {
+		return domain.Rating{}, err
 	}
 
-	return nil
+	return result, nil
 }
 
-func (service *ratingService) Delete(ctx context.Context, uuid uuid.UUID) error {
+func (service *ratingService) RetractVote(ctx context.Context, uuid uuid.UUID, userID uint) (domain.Rating, error) {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.ratingRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
+	var result domain.Rating
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		rating, err := service.ratingRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+
+		if rating.ModerationStatus != domain.ModerationStatusPublished {
 			return errors.NotFoundErr
 		}
+
+		if err = service.voteRepo.Delete(ctx, rating.ID, userID); err != nil {
+			return err
+		}
+
+		result, err = service.refreshVoteCounts(ctx, rating)
 		return err
+	})
+	if err != nil {
+		return domain.Rating{}, err
 	}
 
-	if err := service.ratingRepo.Delete(ctx, uuid); err != nil {
-		return err
+	return result, nil
+}
+
+func (service *ratingService) ScheduleReconcile(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := service.reconcile(ctx); err != nil {
+				utils.Logger.Error(fmt.Sprintf("failed to reconcile the weighted ratings: %s", err))
+			}
+		}
+	}
+}
+
+func (service *ratingService) reconcile(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.movieRepo.RefreshWeightedRatings(ctx, configs.Env.Rating.WeightedMinimumVotes)
+}
+
+// refreshVoteCounts updates the denormalized vote counts of the rating and reloads it
+func (service *ratingService) refreshVoteCounts(ctx context.Context, rating domain.Rating) (domain.Rating, error) {
+	if err := service.ratingRepo.RefreshVoteCounts(ctx, rating.ID); err != nil {
+		return domain.Rating{}, err
 	}
 
-	return nil
+	return service.ratingRepo.FindByID(ctx, rating.Uuid)
+}
+
+// screen holds the review for moderation when its comment matches the moderation rules
+func (service *ratingService) screen(rating *domain.Rating) {
+	if rating.Comment != "" && service.moderation.Screen(rating.Comment) {
+		rating.ModerationStatus = domain.ModerationStatusPendingReview
+	}
+}
+
+func (service *ratingService) findMovieForUpdate(ctx context.Context, uuid uuid.UUID) (domain.Movie, error) {
+	movie, err := service.movieRepo.FindByIDForUpdate(ctx, uuid)
+	if err != nil {
+		if err == errors.NotFoundErr {
+			return domain.Movie{}, echo.NewHTTPError(http.StatusBadRequest, "The movie is not valid.")
+		}
+		return domain.Movie{}, err
+	}
+
+	return movie, nil
+}
+
+// refreshRatingStats keeps the rating aggregates of the movie in step with its ratings
+func (service *ratingService) refreshRatingStats(ctx context.Context, movieID uint) error {
+	return service.movieRepo.RefreshRatingStats(ctx, []uint{movieID}, configs.Env.Rating.WeightedMinimumVotes)
 }
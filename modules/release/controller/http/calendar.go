@@ -0,0 +1,81 @@
+package http
+
+import (
+	"fmt"
+	"go-movie-api/domain"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	calendarProductID = "-//Movie API//Release Calendar//EN"
+	// calendarLineLimit is the longest content line allowed by RFC 5545, in octets
+	calendarLineLimit = 75
+)
+
+var calendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeCalendar writes the releases as an RFC 5545 calendar with one all day event per release
+func writeCalendar(w io.Writer, releases []domain.Release, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendarProductID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Movie releases",
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, release := range releases {
+		if release.Movie == nil {
+			continue
+		}
+
+		summary := fmt.Sprintf("%s (%s, %s)", release.Movie.Title, release.Type, release.Country)
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s-%s-%s@go-movie-api", release.Movie.Uuid, strings.ToLower(release.Country), release.Type),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+release.ReleaseDate.Format("20060102"),
+			"DTEND;VALUE=DATE:"+release.ReleaseDate.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+calendarTextEscaper.Replace(summary),
+		)
+		if release.Certification != "" {
+			lines = append(lines, "DESCRIPTION:"+calendarTextEscaper.Replace("Certification: "+release.Certification))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldLine(line)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// foldLine ends the line with CRLF, splitting it into continuation lines that start with a space when it is too long.
+// Lines are only split between UTF-8 sequences.
+func foldLine(line string) string {
+	var builder strings.Builder
+	limit := calendarLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = calendarLineLimit - 1
+	}
+	builder.WriteString(line)
+	builder.WriteString("\r\n")
+
+	return builder.String()
+}
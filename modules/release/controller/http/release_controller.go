@@ -0,0 +1,121 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const mimeTextCalendar = "text/calendar"
+
+type ReleaseController struct {
+	domain.ReleaseService
+}
+
+func NewReleaseController(router *echo.Echo, releaseService domain.ReleaseService) {
+	controller := &ReleaseController{
+		ReleaseService: releaseService,
+	}
+
+	router.GET("/movies/:uuid/releases", controller.Index)
+	router.PUT("/movies/:uuid/releases", controller.Replace, middleware.AuthMiddleware.Handler)
+	router.GET("/releases/calendar", controller.Calendar)
+}
+
+func (controller *ReleaseController) Index(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.ReleaseService.FetchByMovie(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.Release, 0)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+// Replace sets every release of the movie
+func (controller *ReleaseController) Replace(ec echo.Context) error {
+	var request replaceRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	releases := make([]domain.Release, 0, len(request.Releases))
+	for _, release := range request.Releases {
+		releaseDate, _ := time.Parse(time.DateOnly, release.ReleaseDate)
+		releases = append(releases, domain.Release{
+			Country:       release.Country,
+			Type:          release.Type,
+			ReleaseDate:   releaseDate,
+			Certification: release.Certification,
+		})
+	}
+
+	data, err := controller.ReleaseService.ReplaceByMovie(ec.Request().Context(), id, releases)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+// Calendar lists the upcoming releases as JSON, or as an iCalendar feed for ?format=ics or an Accept of text/calendar
+func (controller *ReleaseController) Calendar(ec echo.Context) error {
+	var request calendarRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	filter := domain.ReleaseCalendarFilter{
+		Country: request.Country,
+		Type:    request.Type,
+	}
+	if request.From != "" {
+		filter.From, _ = time.Parse(time.DateOnly, request.From)
+	}
+	if request.To != "" {
+		filter.To, _ = time.Parse(time.DateOnly, request.To)
+	}
+
+	data, err := controller.ReleaseService.Calendar(ec.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	if request.Format == "ics" || request.Format == "" && strings.Contains(ec.Request().Header.Get(echo.HeaderAccept), mimeTextCalendar) {
+		ec.Response().Header().Set(echo.HeaderContentType, mimeTextCalendar+"; charset=utf-8")
+		ec.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="releases.ics"`)
+		ec.Response().WriteHeader(http.StatusOK)
+		return writeCalendar(ec.Response(), data, time.Now())
+	}
+
+	if data == nil {
+		data = make([]domain.Release, 0)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,20 @@
+package http
+
+type releaseRequest struct {
+	Country       string `json:"country" validate:"required,iso3166_1_alpha2"`
+	Type          string `json:"type" validate:"required,oneof=theatrical digital physical"`
+	ReleaseDate   string `json:"release_date" validate:"required,datetime=2006-01-02"`
+	Certification string `json:"certification" validate:"omitempty,max=20"`
+}
+
+type replaceRequest struct {
+	Releases []releaseRequest `json:"releases" validate:"dive"`
+}
+
+type calendarRequest struct {
+	Country string `query:"country" validate:"omitempty,iso3166_1_alpha2"`
+	Type    string `query:"type" validate:"omitempty,oneof=theatrical digital physical"`
+	From    string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To      string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Format  string `query:"format" validate:"omitempty,oneof=json ics"`
+}
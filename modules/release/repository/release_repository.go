@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"gorm.io/gorm"
+)
+
+type releaseRepository struct {
+	db *gorm.DB
+}
+
+func NewReleaseRepository(gormDB *gorm.DB) domain.ReleaseRepository {
+	return &releaseRepository{db: gormDB}
+}
+
+func (repo *releaseRepository) FetchByMovie(ctx context.Context, movieID uint) ([]domain.Release, error) {
+	var releases []domain.Release
+
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ?", movieID).
+		Order("country ASC").
+		Order("release_date ASC").
+		Find(&releases)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return releases, nil
+}
+
+func (repo *releaseRepository) ReplaceByMovie(ctx context.Context, movieID uint, releases []domain.Release) error {
+	db := database.Conn(ctx, repo.db)
+
+	if result := db.Where("movie_id = ?", movieID).Delete(&domain.Release{}); result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if len(releases) == 0 {
+		return nil
+	}
+
+	if result := db.Omit("Movie").Create(&releases); result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *releaseRepository) FetchCalendar(ctx context.Context, filter domain.ReleaseCalendarFilter) ([]domain.Release, error) {
+	var releases []domain.Release
+
+	db := repo.db.WithContext(ctx).
+		Joins("JOIN movies ON movies.id = releases.movie_id AND movies.deleted_at IS NULL").
+		Where("releases.release_date BETWEEN ? AND ?", filter.From, filter.To)
+	if filter.Country != "" {
+		db = db.Where("releases.country = ?", filter.Country)
+	}
+	if filter.Type != "" {
+		db = db.Where("releases.type = ?", filter.Type)
+	}
+
+	result := db.
+		Preload("Movie").
+		Scopes(preloadTranslations(ctx)).
+		Order("releases.release_date ASC").
+		Order("releases.id ASC").
+		Find(&releases)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return releases, nil
+}
+
+// preloadTranslations loads the translations of the released movies in the locales of the request
+func preloadTranslations(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		locales := utils.Locales(ctx)
+		if len(locales) == 0 {
+			return db
+		}
+
+		return db.Preload("Movie.Translations", "locale IN ?", locales)
+	}
+}
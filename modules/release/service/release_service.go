@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultCalendarDays is the length of the calendar when no end date is asked for
+	defaultCalendarDays = 90
+	// maxCalendarDays bounds the calendar so a single request cannot list every release
+	maxCalendarDays = 366
+)
+
+type releaseService struct {
+	releaseRepo domain.ReleaseRepository
+	movieRepo   domain.MovieRepository
+	transactor  domain.Transactor
+	timeout     time.Duration
+}
+
+func NewReleaseService(releaseRepo domain.ReleaseRepository, movieRepo domain.MovieRepository, transactor domain.Transactor, timeout time.Duration) domain.ReleaseService {
+	return &releaseService{
+		releaseRepo: releaseRepo,
+		movieRepo:   movieRepo,
+		transactor:  transactor,
+		timeout:     timeout,
+	}
+}
+
+func (service *releaseService) FetchByMovie(ctx context.Context, movieUuid uuid.UUID) ([]domain.Release, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movies, err := service.movieRepo.FindByIDs(ctx, []uuid.UUID{movieUuid})
+	if err != nil {
+		return nil, err
+	}
+	if len(movies) == 0 {
+		return nil, errors.NotFoundErr
+	}
+
+	return service.releaseRepo.FetchByMovie(ctx, movies[0].ID)
+}
+
+func (service *releaseService) ReplaceByMovie(ctx context.Context, movieUuid uuid.UUID, releases []domain.Release) ([]domain.Release, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	seen := make(map[string]bool, len(releases))
+	for _, release := range releases {
+		key := release.Country + "/" + release.Type
+		if seen[key] {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "A country can only have one release of each type.")
+		}
+		seen[key] = true
+	}
+
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		movie, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+		if err != nil {
+			return err
+		}
+
+		for i := range releases {
+			releases[i].MovieID = movie.ID
+		}
+
+		return service.releaseRepo.ReplaceByMovie(ctx, movie.ID, releases)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+func (service *releaseService) Calendar(ctx context.Context, filter domain.ReleaseCalendarFilter) ([]domain.Release, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	if filter.From.IsZero() {
+		filter.From = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if filter.To.IsZero() {
+		filter.To = filter.From.AddDate(0, 0, defaultCalendarDays)
+	}
+	if filter.To.Before(filter.From) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "The calendar must end after it starts.")
+	}
+	if filter.To.Sub(filter.From) > maxCalendarDays*24*time.Hour {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "The calendar cannot span more than a year.")
+	}
+
+	releases, err := service.releaseRepo.FetchCalendar(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range releases {
+		if releases[i].Movie != nil {
+			releases[i].Movie.Localize(utils.Locales(ctx))
+		}
+	}
+
+	return releases, nil
+}
@@ -0,0 +1,6 @@
+package http
+
+type diffRequest struct {
+	From int `query:"from" validate:"required,min=1"`
+	To   int `query:"to" validate:"required,min=1"`
+}
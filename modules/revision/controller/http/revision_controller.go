@@ -0,0 +1,142 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+)
+
+type RevisionController struct {
+	domain.RevisionService
+}
+
+func NewRevisionController(router *echo.Echo, revisionService domain.RevisionService) {
+	controller := &RevisionController{
+		RevisionService: revisionService,
+	}
+
+	admin := []echo.MiddlewareFunc{middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler}
+
+	subjects := map[string]string{
+		"/movies": domain.RevisionSubjectMovie,
+		"/genres": domain.RevisionSubjectGenre,
+	}
+	for prefix, subject := range subjects {
+		router.GET(prefix+"/:uuid/revisions", controller.Index(subject), admin...)
+		router.GET(prefix+"/:uuid/revisions/diff", controller.Diff(subject), admin...)
+		router.GET(prefix+"/:uuid/revisions/:rev", controller.Show(subject), admin...)
+		router.POST(prefix+"/:uuid/revisions/:rev/restore", controller.Restore(subject), admin...)
+	}
+}
+
+// Index lists the revisions of a movie or genre, the newest first
+func (controller *RevisionController) Index(subject string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		id, err := uuid.Parse(ec.Param("uuid"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+		}
+
+		page, err := strconv.Atoi(ec.QueryParam("page"))
+		if err != nil || page <= 0 {
+			page = 1
+		}
+
+		perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+		if err != nil || perPage <= 0 {
+			perPage = 100
+		}
+
+		data, pagination, err := controller.RevisionService.Fetch(ec.Request().Context(), subject, id, page, perPage)
+		if err != nil {
+			return err
+		}
+
+		if data == nil {
+			data = make([]domain.Revision, 0)
+		}
+
+		return ec.JSON(http.StatusOK, response.Result{
+			Meta: pagination,
+			Data: data,
+		})
+	}
+}
+
+func (controller *RevisionController) Show(subject string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		id, version, err := parseRevision(ec)
+		if err != nil {
+			return err
+		}
+
+		data, err := controller.RevisionService.FindByVersion(ec.Request().Context(), subject, id, version)
+		if err != nil {
+			return err
+		}
+
+		return ec.JSON(http.StatusOK, data)
+	}
+}
+
+// Diff lists the fields that changed between the versions from and to
+func (controller *RevisionController) Diff(subject string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		id, err := uuid.Parse(ec.Param("uuid"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+		}
+
+		var request diffRequest
+		if err = ec.Bind(&request); err != nil {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		}
+
+		if err = ec.Validate(request); err != nil {
+			return err
+		}
+
+		data, err := controller.RevisionService.Diff(ec.Request().Context(), subject, id, request.From, request.To)
+		if err != nil {
+			return err
+		}
+
+		return ec.JSON(http.StatusOK, response.Result{Data: data})
+	}
+}
+
+// Restore rolls the movie or genre back to a revision, the rollback is recorded as a revision of its own
+func (controller *RevisionController) Restore(subject string) echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		id, version, err := parseRevision(ec)
+		if err != nil {
+			return err
+		}
+
+		data, err := controller.RevisionService.Restore(ec.Request().Context(), subject, id, version)
+		if err != nil {
+			return err
+		}
+
+		return ec.JSON(http.StatusOK, data)
+	}
+}
+
+// parseRevision reads the id of the movie or genre and the version of the revision from the path
+func parseRevision(ec echo.Context) (uuid.UUID, int, error) {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return uuid.UUID{}, 0, echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	version, err := strconv.Atoi(ec.Param("rev"))
+	if err != nil || version <= 0 {
+		return uuid.UUID{}, 0, echo.NewHTTPError(http.StatusBadRequest, "the revision is not valid.")
+	}
+
+	return id, version, nil
+}
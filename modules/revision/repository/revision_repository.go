@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+)
+
+// subject is where the revisions of a subject point to and how its snapshot is built, the snapshots must
+// match the ones the revisions migration backfilled
+type subject struct {
+	table    string
+	column   string
+	snapshot string
+}
+
+var subjects = map[string]subject{
+	domain.RevisionSubjectMovie: {
+		table:  "movies",
+		column: "movie_id",
+		snapshot: `jsonb_build_object(
+			'title', movies.title,
+			'year', movies.year,
+			'duration', movies.duration,
+			'synopsis', COALESCE(movies.synopsis, ''),
+			'genres', COALESCE((SELECT jsonb_agg(jsonb_build_object('id', genres.uuid, 'name', genres.name) ORDER BY genres.name, genres.uuid)
+				FROM movie_genres
+				JOIN genres ON genres.id = movie_genres.genre_id AND genres.deleted_at IS NULL
+				WHERE movie_genres.movie_id = movies.id), '[]'::jsonb))`,
+	},
+	domain.RevisionSubjectGenre: {
+		table:    "genres",
+		column:   "genre_id",
+		snapshot: `jsonb_build_object('name', genres.name)`,
+	},
+}
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(gormDB *gorm.DB) domain.RevisionRepository {
+	return &revisionRepository{db: gormDB}
+}
+
+func (repo *revisionRepository) Store(ctx context.Context, revision *domain.Revision) error {
+	s, subjectID := subjects[domain.RevisionSubjectMovie], revision.MovieID
+	if revision.GenreID != nil {
+		s, subjectID = subjects[domain.RevisionSubjectGenre], revision.GenreID
+	}
+
+	// the caller locks the subject, so that two changes of it cannot take the same version
+	query := fmt.Sprintf(`
+		INSERT INTO revisions (%[2]s, version, user_id, action, restored_from, snapshot)
+		SELECT %[1]s.id, COALESCE((SELECT MAX(version) FROM revisions WHERE %[2]s = %[1]s.id), 0) + 1,
+			@user_id, @action, @restored_from, %[3]s
+		FROM %[1]s
+		WHERE %[1]s.id = @subject_id
+		RETURNING id, created_at, version, snapshot`, s.table, s.column, s.snapshot)
+	result := database.Conn(ctx, repo.db).Raw(query,
+		sql.Named("subject_id", subjectID),
+		sql.Named("user_id", revision.UserID),
+		sql.Named("action", revision.Action),
+		sql.Named("restored_from", revision.RestoredFrom),
+	).Scan(revision)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *revisionRepository) Fetch(ctx context.Context, subject string, subjectID uint, pagination *utils.Pagination) ([]domain.Revision, error) {
+	var revisions []domain.Revision
+
+	column := subjects[subject].column
+	filtered := database.Conn(ctx, repo.db).Where(column+" = ?", subjectID)
+	result := database.Conn(ctx, repo.db).
+		Where(column+" = ?", subjectID).
+		Scopes(utils.Paginate(revisions, pagination, filtered)).
+		Preload("User").
+		Order("version DESC").
+		Find(&revisions)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return revisions, nil
+}
+
+func (repo *revisionRepository) FindByVersion(ctx context.Context, subject string, subjectID uint, version int) (domain.Revision, error) {
+	var revision domain.Revision
+
+	result := database.Conn(ctx, repo.db).
+		Where(subjects[subject].column+" = ? AND version = ?", subjectID, version).
+		Preload("User").
+		First(&revision)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.Revision{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.Revision{}, result.Error
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// movieSnapshot and genreSnapshot are the fields a restore writes back, as the revision repository snapshots them
+type movieSnapshot struct {
+	Title    string `json:"title"`
+	Year     int32  `json:"year"`
+	Duration int32  `json:"duration"`
+	Synopsis string `json:"synopsis"`
+	Genres   []struct {
+		ID uuid.UUID `json:"id"`
+	} `json:"genres"`
+}
+
+type genreSnapshot struct {
+	Name string `json:"name"`
+}
+
+type revisionService struct {
+	revisionRepo        domain.RevisionRepository
+	movieRepo           domain.MovieRepository
+	genreRepo           domain.GenreRepository
+	provenanceRepo      domain.ProvenanceRepository
+	autocompleteService domain.AutocompleteService
+	transactor          domain.Transactor
+	timeout             time.Duration
+}
+
+func NewRevisionService(revisionRepo domain.RevisionRepository, movieRepo domain.MovieRepository, genreRepo domain.GenreRepository, provenanceRepo domain.ProvenanceRepository, autocompleteService domain.AutocompleteService, transactor domain.Transactor, timeout time.Duration) domain.RevisionService {
+	return &revisionService{
+		revisionRepo:        revisionRepo,
+		movieRepo:           movieRepo,
+		genreRepo:           genreRepo,
+		provenanceRepo:      provenanceRepo,
+		autocompleteService: autocompleteService,
+		transactor:          transactor,
+		timeout:             timeout,
+	}
+}
+
+func (service *revisionService) Record(ctx context.Context, subject string, subjectID uint, action string) error {
+	revision := domain.Revision{
+		UserID: utils.UserID(ctx),
+		Action: action,
+	}
+	if subject == domain.RevisionSubjectGenre {
+		revision.GenreID = &subjectID
+	} else {
+		revision.MovieID = &subjectID
+	}
+
+	return service.revisionRepo.Store(ctx, &revision)
+}
+
+func (service *revisionService) Fetch(ctx context.Context, subject string, uuid uuid.UUID, page int, perPage int) ([]domain.Revision, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	subjectID, err := service.subjectID(ctx, subject, uuid)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	revisions, err := service.revisionRepo.Fetch(ctx, subject, subjectID, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return revisions, pagination, nil
+}
+
+func (service *revisionService) FindByVersion(ctx context.Context, subject string, uuid uuid.UUID, version int) (domain.Revision, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	subjectID, err := service.subjectID(ctx, subject, uuid)
+	if err != nil {
+		return domain.Revision{}, err
+	}
+
+	return service.revisionRepo.FindByVersion(ctx, subject, subjectID, version)
+}
+
+func (service *revisionService) Diff(ctx context.Context, subject string, uuid uuid.UUID, from int, to int) ([]domain.RevisionChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	subjectID, err := service.subjectID(ctx, subject, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := service.revisionRepo.FindByVersion(ctx, subject, subjectID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := service.revisionRepo.FindByVersion(ctx, subject, subjectID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for field := range before.Snapshot {
+		fields[field] = true
+	}
+	for field := range after.Snapshot {
+		fields[field] = true
+	}
+
+	changes := make([]domain.RevisionChange, 0)
+	for field := range fields {
+		if !reflect.DeepEqual(before.Snapshot[field], after.Snapshot[field]) {
+			changes = append(changes, domain.RevisionChange{
+				Field: field,
+				From:  before.Snapshot[field],
+				To:    after.Snapshot[field],
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes, nil
+}
+
+func (service *revisionService) Restore(ctx context.Context, subject string, uuid uuid.UUID, version int) (domain.Revision, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	var restored domain.Revision
+	err := service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var subjectID uint
+		var err error
+		if subject == domain.RevisionSubjectGenre {
+			subjectID, err = service.restoreGenre(ctx, uuid, version)
+		} else {
+			subjectID, err = service.restoreMovie(ctx, uuid, version)
+		}
+		if err != nil {
+			return err
+		}
+
+		restored = domain.Revision{
+			UserID:       utils.UserID(ctx),
+			Action:       domain.RevisionActionRestored,
+			RestoredFrom: &version,
+		}
+		if subject == domain.RevisionSubjectGenre {
+			restored.GenreID = &subjectID
+		} else {
+			restored.MovieID = &subjectID
+		}
+
+		return service.revisionRepo.Store(ctx, &restored)
+	})
+	if err != nil {
+		return domain.Revision{}, err
+	}
+	service.autocompleteService.Invalidate()
+
+	return restored, nil
+}
+
+// restoreMovie writes the fields and genres of the version back to the locked movie, like a manual edit
+func (service *revisionService) restoreMovie(ctx context.Context, movieUuid uuid.UUID, version int) (uint, error) {
+	movie, err := service.movieRepo.FindByIDForUpdate(ctx, movieUuid)
+	if err != nil {
+		return 0, err
+	}
+
+	revision, err := service.revisionRepo.FindByVersion(ctx, domain.RevisionSubjectMovie, movie.ID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	var snapshot movieSnapshot
+	if err = revision.Snapshot.Decode(&snapshot); err != nil {
+		return 0, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(snapshot.Genres))
+	for _, genre := range snapshot.Genres {
+		ids = append(ids, genre.ID)
+	}
+	genres, err := service.genreRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	if len(genres) != len(ids) {
+		return 0, echo.NewHTTPError(http.StatusConflict, "A genre of the revision no longer exists.")
+	}
+
+	restored := domain.Movie{
+		Title:    snapshot.Title,
+		Year:     snapshot.Year,
+		Duration: snapshot.Duration,
+		Synopsis: snapshot.Synopsis,
+	}
+	err = service.movieRepo.UpdateFields(ctx, movie.ID, map[string]interface{}{
+		domain.MovieFieldTitle:    restored.Title,
+		domain.MovieFieldYear:     restored.Year,
+		domain.MovieFieldDuration: restored.Duration,
+		domain.MovieFieldSynopsis: restored.Synopsis,
+		"version":                 movie.Version + 1,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err = service.movieRepo.ReplaceGenres(ctx, movie.ID, genres); err != nil {
+		return 0, err
+	}
+
+	return movie.ID, service.provenanceRepo.Record(ctx, movie.ID, restored.ImportableFields(), domain.FieldSourceManual)
+}
+
+func (service *revisionService) restoreGenre(ctx context.Context, uuid uuid.UUID, version int) (uint, error) {
+	genre, err := service.genreRepo.FindByIDForUpdate(ctx, uuid)
+	if err != nil {
+		return 0, err
+	}
+
+	revision, err := service.revisionRepo.FindByVersion(ctx, domain.RevisionSubjectGenre, genre.ID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	var snapshot genreSnapshot
+	if err = revision.Snapshot.Decode(&snapshot); err != nil {
+		return 0, err
+	}
+
+	return genre.ID, service.genreRepo.Update(ctx, &domain.Genre{Uuid: genre.Uuid, Name: snapshot.Name, Version: genre.Version + 1})
+}
+
+// subjectID finds the id of the movie or genre, trashed ones have no history to show
+func (service *revisionService) subjectID(ctx context.Context, subject string, subjectUuid uuid.UUID) (uint, error) {
+	if subject == domain.RevisionSubjectGenre {
+		genres, err := service.genreRepo.FindByIDs(ctx, []uuid.UUID{subjectUuid})
+		if err != nil {
+			return 0, err
+		}
+		if len(genres) == 0 {
+			return 0, errors.NotFoundErr
+		}
+		return genres[0].ID, nil
+	}
+
+	movies, err := service.movieRepo.FindByIDs(ctx, []uuid.UUID{subjectUuid})
+	if err != nil {
+		return 0, err
+	}
+	if len(movies) == 0 {
+		return 0, errors.NotFoundErr
+	}
+	return movies[0].ID, nil
+}
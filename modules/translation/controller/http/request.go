@@ -0,0 +1,10 @@
+package http
+
+type movieRequest struct {
+	Title    string `json:"title" form:"title" validate:"required,max=255"`
+	Synopsis string `json:"synopsis" form:"synopsis" validate:"omitempty"`
+}
+
+type genreRequest struct {
+	Name string `json:"name" form:"name" validate:"required,max=255"`
+}
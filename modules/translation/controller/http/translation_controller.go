@@ -0,0 +1,161 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils"
+	"go-movie-api/utils/response"
+	"net/http"
+)
+
+type TranslationController struct {
+	domain.TranslationService
+}
+
+func NewTranslationController(router *echo.Echo, translationService domain.TranslationService) {
+	controller := &TranslationController{
+		TranslationService: translationService,
+	}
+
+	admin := []echo.MiddlewareFunc{middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler}
+
+	router.GET("/movies/:uuid/translations", controller.IndexMovie)
+	router.PUT("/movies/:uuid/translations/:locale", controller.PutMovie, admin...)
+	router.DELETE("/movies/:uuid/translations/:locale", controller.DestroyMovie, admin...)
+
+	router.GET("/genres/:uuid/translations", controller.IndexGenre)
+	router.PUT("/genres/:uuid/translations/:locale", controller.PutGenre, admin...)
+	router.DELETE("/genres/:uuid/translations/:locale", controller.DestroyGenre, admin...)
+}
+
+func (controller *TranslationController) IndexMovie(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.TranslationService.FetchByMovie(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.MovieTranslation, 0)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *TranslationController) PutMovie(ec echo.Context) error {
+	var request movieRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, locale, err := parseParams(ec)
+	if err != nil {
+		return err
+	}
+
+	data, err := controller.TranslationService.PutMovie(ec.Request().Context(), id, &domain.MovieTranslation{
+		Locale:   locale,
+		Title:    request.Title,
+		Synopsis: request.Synopsis,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *TranslationController) DestroyMovie(ec echo.Context) error {
+	id, locale, err := parseParams(ec)
+	if err != nil {
+		return err
+	}
+
+	if err = controller.TranslationService.DeleteMovie(ec.Request().Context(), id, locale); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+func (controller *TranslationController) IndexGenre(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	data, err := controller.TranslationService.FetchByGenre(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.GenreTranslation, 0)
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *TranslationController) PutGenre(ec echo.Context) error {
+	var request genreRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, locale, err := parseParams(ec)
+	if err != nil {
+		return err
+	}
+
+	data, err := controller.TranslationService.PutGenre(ec.Request().Context(), id, &domain.GenreTranslation{
+		Locale: locale,
+		Name:   request.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *TranslationController) DestroyGenre(ec echo.Context) error {
+	id, locale, err := parseParams(ec)
+	if err != nil {
+		return err
+	}
+
+	if err = controller.TranslationService.DeleteGenre(ec.Request().Context(), id, locale); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
+
+// parseParams reads the movie or genre id and the locale of the path, the locale is returned in its canonical form
+func parseParams(ec echo.Context) (uuid.UUID, string, error) {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return uuid.Nil, "", echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	locale, ok := utils.NormalizeLocale(ec.Param("locale"))
+	if !ok {
+		return uuid.Nil, "", echo.NewHTTPError(http.StatusBadRequest, "the locale is not valid.")
+	}
+
+	return id, locale, nil
+}
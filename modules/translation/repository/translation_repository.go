@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type translationRepository struct {
+	db *gorm.DB
+}
+
+func NewTranslationRepository(gormDB *gorm.DB) domain.TranslationRepository {
+	return &translationRepository{db: gormDB}
+}
+
+func (repo *translationRepository) FetchByMovie(ctx context.Context, movieID uint) ([]domain.MovieTranslation, error) {
+	var translations []domain.MovieTranslation
+
+	result := database.Conn(ctx, repo.db).Where("movie_id = ?", movieID).Order("locale ASC").Find(&translations)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return translations, nil
+}
+
+func (repo *translationRepository) UpsertMovie(ctx context.Context, translation *domain.MovieTranslation) (domain.MovieTranslation, error) {
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "movie_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "synopsis", "updated_at"}),
+		}, clause.Returning{}).
+		Create(translation)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return domain.MovieTranslation{}, result.Error
+	}
+
+	return *translation, nil
+}
+
+func (repo *translationRepository) DeleteMovie(ctx context.Context, movieID uint, locale string) error {
+	result := database.Conn(ctx, repo.db).
+		Where("movie_id = ? AND locale = ?", movieID, locale).
+		Delete(&domain.MovieTranslation{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *translationRepository) FetchByGenre(ctx context.Context, genreID uint) ([]domain.GenreTranslation, error) {
+	var translations []domain.GenreTranslation
+
+	result := database.Conn(ctx, repo.db).Where("genre_id = ?", genreID).Order("locale ASC").Find(&translations)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return translations, nil
+}
+
+func (repo *translationRepository) UpsertGenre(ctx context.Context, translation *domain.GenreTranslation) (domain.GenreTranslation, error) {
+	result := database.Conn(ctx, repo.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "genre_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+		}, clause.Returning{}).
+		Create(translation)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return domain.GenreTranslation{}, result.Error
+	}
+
+	return *translation, nil
+}
+
+func (repo *translationRepository) DeleteGenre(ctx context.Context, genreID uint, locale string) error {
+	result := database.Conn(ctx, repo.db).
+		Where("genre_id = ? AND locale = ?", genreID, locale).
+		Delete(&domain.GenreTranslation{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
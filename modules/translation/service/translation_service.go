@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/domain"
+	errors "go-movie-api/utils/helper"
+	"time"
+)
+
+type translationService struct {
+	translationRepo domain.TranslationRepository
+	movieRepo       domain.MovieRepository
+	genreRepo       domain.GenreRepository
+	timeout         time.Duration
+}
+
+func NewTranslationService(translationRepo domain.TranslationRepository, movieRepo domain.MovieRepository, genreRepo domain.GenreRepository, timeout time.Duration) domain.TranslationService {
+	return &translationService{
+		translationRepo: translationRepo,
+		movieRepo:       movieRepo,
+		genreRepo:       genreRepo,
+		timeout:         timeout,
+	}
+}
+
+func (service *translationService) FetchByMovie(ctx context.Context, movieUuid uuid.UUID) ([]domain.MovieTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.findMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.translationRepo.FetchByMovie(ctx, movieID)
+}
+
+func (service *translationService) PutMovie(ctx context.Context, movieUuid uuid.UUID, translation *domain.MovieTranslation) (domain.MovieTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.findMovie(ctx, movieUuid)
+	if err != nil {
+		return domain.MovieTranslation{}, err
+	}
+
+	translation.MovieID = movieID
+	return service.translationRepo.UpsertMovie(ctx, translation)
+}
+
+func (service *translationService) DeleteMovie(ctx context.Context, movieUuid uuid.UUID, locale string) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movieID, err := service.findMovie(ctx, movieUuid)
+	if err != nil {
+		return err
+	}
+
+	return service.translationRepo.DeleteMovie(ctx, movieID, locale)
+}
+
+func (service *translationService) FetchByGenre(ctx context.Context, genreUuid uuid.UUID) ([]domain.GenreTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	genreID, err := service.findGenre(ctx, genreUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.translationRepo.FetchByGenre(ctx, genreID)
+}
+
+func (service *translationService) PutGenre(ctx context.Context, genreUuid uuid.UUID, translation *domain.GenreTranslation) (domain.GenreTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	genreID, err := service.findGenre(ctx, genreUuid)
+	if err != nil {
+		return domain.GenreTranslation{}, err
+	}
+
+	translation.GenreID = genreID
+	return service.translationRepo.UpsertGenre(ctx, translation)
+}
+
+func (service *translationService) DeleteGenre(ctx context.Context, genreUuid uuid.UUID, locale string) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	genreID, err := service.findGenre(ctx, genreUuid)
+	if err != nil {
+		return err
+	}
+
+	return service.translationRepo.DeleteGenre(ctx, genreID, locale)
+}
+
+// findMovie returns the id of the movie, translations of a deleted movie cannot be managed
+func (service *translationService) findMovie(ctx context.Context, movieUuid uuid.UUID) (uint, error) {
+	movies, err := service.movieRepo.FindByIDs(ctx, []uuid.UUID{movieUuid})
+	if err != nil {
+		return 0, err
+	}
+	if len(movies) == 0 {
+		return 0, errors.NotFoundErr
+	}
+
+	return movies[0].ID, nil
+}
+
+// findGenre returns the id of the genre, translations of a deleted genre cannot be managed
+func (service *translationService) findGenre(ctx context.Context, genreUuid uuid.UUID) (uint, error) {
+	genres, err := service.genreRepo.FindByIDs(ctx, []uuid.UUID{genreUuid})
+	if err != nil {
+		return 0, err
+	}
+	if len(genres) == 0 {
+		return 0, errors.NotFoundErr
+	}
+
+	return genres[0].ID, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"time"
+)
+
+// purgeBatchSize is how many expired rows of a kind are read at a time
+const purgeBatchSize = 100
+
+// trashKind lists the expired rows of one table and deletes them one by one, through the service so that
+// the aggregates the row counts towards are kept up to date
+type trashKind struct {
+	name   string
+	fetch  func(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]uuid.UUID, error)
+	delete func(ctx context.Context, uuid uuid.UUID, version uint) error
+}
+
+type retentionService struct {
+	kinds   []trashKind
+	timeout time.Duration
+}
+
+func NewRetentionService(movieRepo domain.MovieRepository, genreRepo domain.GenreRepository, ratingRepo domain.RatingRepository, userRepo domain.UserRepository, movieService domain.MovieService, genreService domain.GenreService, ratingService domain.RatingService, userService domain.UserService, timeout time.Duration) domain.RetentionService {
+	return &retentionService{
+		// ratings go first, purging a movie or user would take its ratings along anyway
+		kinds: []trashKind{
+			{
+				name: "ratings",
+				fetch: func(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]uuid.UUID, error) {
+					ratings, err := ratingRepo.FetchTrashed(ctx, deletedBefore, pagination)
+					ids := make([]uuid.UUID, 0, len(ratings))
+					for _, rating := range ratings {
+						ids = append(ids, rating.Uuid)
+					}
+					return ids, err
+				},
+				delete: ratingService.Delete,
+			},
+			{
+				name: "movies",
+				fetch: func(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]uuid.UUID, error) {
+					movies, err := movieRepo.FetchTrashed(ctx, deletedBefore, pagination)
+					ids := make([]uuid.UUID, 0, len(movies))
+					for _, movie := range movies {
+						ids = append(ids, movie.Uuid)
+					}
+					return ids, err
+				},
+				delete: movieService.Delete,
+			},
+			{
+				name: "genres",
+				fetch: func(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]uuid.UUID, error) {
+					genres, err := genreRepo.FetchTrashed(ctx, deletedBefore, pagination)
+					ids := make([]uuid.UUID, 0, len(genres))
+					for _, genre := range genres {
+						ids = append(ids, genre.Uuid)
+					}
+					return ids, err
+				},
+				delete: genreService.Delete,
+			},
+			{
+				name: "users",
+				fetch: func(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]uuid.UUID, error) {
+					users, err := userRepo.FetchTrashed(ctx, deletedBefore, pagination)
+					ids := make([]uuid.UUID, 0, len(users))
+					for _, user := range users {
+						ids = append(ids, user.Uuid)
+					}
+					return ids, err
+				},
+				delete: userService.Delete,
+			},
+		},
+		timeout: timeout,
+	}
+}
+
+// Purge logs a row that fails to be purged and goes on with the next, the row is left for the next run
+func (service *retentionService) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged := 0
+	for _, kind := range service.kinds {
+		failed := make(map[uuid.UUID]bool)
+		for {
+			ids, err := service.fetch(ctx, kind, deletedBefore, len(failed))
+			if err != nil {
+				return purged, fmt.Errorf("listing trashed %s: %w", kind.name, err)
+			}
+
+			listed := false
+			for _, id := range ids {
+				if failed[id] {
+					continue
+				}
+				listed = true
+
+				// the row may have been purged by an admin since it was listed
+				err = kind.delete(ctx, id, domain.AnyVersion)
+				if err == helper.NotFoundErr {
+					continue
+				}
+				if err != nil {
+					utils.Logger.Error(fmt.Sprintf("failed to purge %s %s: %s", kind.name, id, err))
+					failed[id] = true
+					continue
+				}
+				purged++
+			}
+			if !listed {
+				break
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// fetch reads the page following the failed rows. The rows of the previous pages are gone by then but for those
+// that failed, which stay at the start of the listing.
+func (service *retentionService) fetch(ctx context.Context, kind trashKind, deletedBefore time.Time, failed int) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    1 + failed/purgeBatchSize,
+		PerPage: purgeBatchSize,
+	}
+	return kind.fetch(ctx, deletedBefore, &pagination)
+}
+
+func (service *retentionService) Schedule(ctx context.Context, retention time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := service.Purge(ctx, time.Now().Add(-retention))
+			if err != nil {
+				utils.Logger.Error(fmt.Sprintf("failed to purge the trash: %s", err))
+				continue
+			}
+			utils.Logger.Info(fmt.Sprintf("purged %d rows from the trash", purged))
+		}
+	}
+}
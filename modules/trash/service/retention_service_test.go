@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go-movie-api/utils"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// fakeTrash lists its rows in a fixed order like the trash listings, deleting a row takes it out of the listing
+type fakeTrash struct {
+	rows    []uuid.UUID
+	failing map[uuid.UUID]bool
+}
+
+func (trash *fakeTrash) fetch(_ context.Context, _ time.Time, pagination *utils.Pagination) ([]uuid.UUID, error) {
+	start := pagination.GetOffset()
+	if start >= len(trash.rows) {
+		return nil, nil
+	}
+	end := start + pagination.GetLimit()
+	if end > len(trash.rows) {
+		end = len(trash.rows)
+	}
+	return append([]uuid.UUID(nil), trash.rows[start:end]...), nil
+}
+
+func (trash *fakeTrash) delete(_ context.Context, id uuid.UUID, _ uint) error {
+	if trash.failing[id] {
+		return errors.New("the row is locked")
+	}
+	for i, row := range trash.rows {
+		if row == id {
+			trash.rows = append(trash.rows[:i], trash.rows[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestPurgeSkipsFailingRows(t *testing.T) {
+	utils.Logger = zap.NewNop()
+
+	trash := &fakeTrash{failing: make(map[uuid.UUID]bool)}
+	for i := 0; i < 3*purgeBatchSize+10; i++ {
+		id := uuid.New()
+		trash.rows = append(trash.rows, id)
+		// more failures than a batch holds, so that whole pages of failed rows come back
+		if i < 2*purgeBatchSize && i%3 != 2 {
+			trash.failing[id] = true
+		}
+	}
+	others := &fakeTrash{rows: []uuid.UUID{uuid.New(), uuid.New()}}
+
+	service := &retentionService{
+		kinds: []trashKind{
+			{name: "ratings", fetch: trash.fetch, delete: trash.delete},
+			{name: "movies", fetch: others.fetch, delete: others.delete},
+		},
+		timeout: time.Second,
+	}
+
+	purged, err := service.Purge(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := 3*purgeBatchSize + 10 - len(trash.failing) + 2; purged != want {
+		t.Errorf("purged %d rows, want %d", purged, want)
+	}
+	if len(trash.rows) != len(trash.failing) {
+		t.Errorf("%d rows left, want the %d failing ones", len(trash.rows), len(trash.failing))
+	}
+	for _, id := range trash.rows {
+		if !trash.failing[id] {
+			t.Errorf("row %s was left behind", id)
+		}
+	}
+	if len(others.rows) != 0 {
+		t.Errorf("the failures stopped the purge of the next kind, %d rows left", len(others.rows))
+	}
+}
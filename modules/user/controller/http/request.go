@@ -5,3 +5,10 @@ type updateRequest struct {
 	Username string `json:"username" form:"username" validate:"omitempty,min=6"`
 	Email    string `json:"email" form:"email" validate:"omitempty,email"`
 }
+
+// patchRequest holds the fields of a user a PATCH request can change, the patch applies to its JSON
+type patchRequest struct {
+	FullName string `json:"full_name" validate:"required"`
+	Username string `json:"username" validate:"required,min=6"`
+	Email    string `json:"email" validate:"required,email"`
+}
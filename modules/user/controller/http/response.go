@@ -0,0 +1,12 @@
+package http
+
+import (
+	"go-movie-api/domain"
+	"time"
+)
+
+// trashedUser is a user in the trash, with the time it went there
+type trashedUser struct {
+	domain.User
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -5,6 +5,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"go-movie-api/domain"
 	"go-movie-api/middleware"
+	"go-movie-api/utils"
+	"go-movie-api/utils/etag"
+	"go-movie-api/utils/helper"
+	"go-movie-api/utils/patch"
 	"go-movie-api/utils/response"
 	"net/http"
 	"strconv"
@@ -23,10 +27,18 @@ func NewUserController(router *echo.Echo, userService domain.UserService) {
 	userGroup.GET("", controller.Index)
 	userGroup.GET("/:uuid", controller.Show)
 	userGroup.PUT("/:uuid", controller.Update)
+	userGroup.PATCH("/:uuid", controller.Patch)
 	userGroup.DELETE("/:uuid", controller.Destroy)
+	userGroup.POST("/:uuid/restore", controller.Restore, middleware.AuthMiddleware.AdminHandler)
+
+	router.GET("/admin/trash/users", controller.Trash, middleware.AuthMiddleware.Handler, middleware.AuthMiddleware.AdminHandler)
 }
 
 func (controller *UserController) Index(ec echo.Context) error {
+	if utils.CursorRequested(ec) {
+		return controller.indexCursor(ec)
+	}
+
 	page, err := strconv.Atoi(ec.QueryParam("page"))
 	if err != nil || page <= 0 {
 		page = 1
@@ -53,6 +65,28 @@ func (controller *UserController) Index(ec echo.Context) error {
 	})
 }
 
+func (controller *UserController) indexCursor(ec echo.Context) error {
+	cursorPagination, err := utils.NewCursorPagination(ec)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	data, cursorPagination, err := controller.UserService.FetchCursor(ctx, cursorPagination)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.User, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: cursorPagination,
+		Data: data,
+	})
+}
+
 func (controller *UserController) Show(ec echo.Context) error {
 	id, err := uuid.Parse(ec.Param("uuid"))
 	if err != nil {
@@ -64,7 +98,7 @@ func (controller *UserController) Show(ec echo.Context) error {
 		return err
 	}
 
-	return ec.JSON(http.StatusOK, data)
+	return etag.JSON(ec, data.Version, data)
 }
 
 func (controller *UserController) Update(ec echo.Context) error {
@@ -82,11 +116,61 @@ func (controller *UserController) Update(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
 	err = controller.UserService.Update(ec.Request().Context(), &domain.User{
 		Uuid:     id,
 		FullName: request.FullName,
 		Username: request.Username,
 		Email:    request.Email,
+	}, version)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+// Patch changes the user with a JSON Merge Patch or a JSON Patch of the fields of patchRequest
+func (controller *UserController) Patch(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	document, err := patch.FromRequest(ec)
+	if err != nil {
+		return err
+	}
+
+	err = controller.UserService.Patch(ec.Request().Context(), id, version, func(user *domain.User) error {
+		request := patchRequest{
+			FullName: user.FullName,
+			Username: user.Username,
+			Email:    user.Email,
+		}
+
+		if err := document.ApplyTo(&request); err != nil {
+			return err
+		}
+
+		if err := ec.Validate(request); err != nil {
+			return err
+		}
+
+		user.FullName = request.FullName
+		user.Username = request.Username
+		user.Email = request.Email
+
+		return nil
 	})
 	if err != nil {
 		return err
@@ -101,10 +185,64 @@ func (controller *UserController) Destroy(ec echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
 	}
 
-	err = controller.UserService.SoftDelete(ec.Request().Context(), id)
+	version, err := etag.IfMatch(ec)
+	if err != nil {
+		return err
+	}
+
+	// hard=true deletes the user for good, from the trash or not, which only admins may do
+	if hard, _ := strconv.ParseBool(ec.QueryParam("hard")); hard {
+		if !middleware.IsAdmin(ec) {
+			return helper.ForbiddenErr
+		}
+		err = controller.UserService.Delete(ec.Request().Context(), id, version)
+	} else {
+		err = controller.UserService.SoftDelete(ec.Request().Context(), id, version)
+	}
 	if err != nil {
 		return err
 	}
 
 	return ec.JSON(http.StatusOK, response.DeleteSuccess)
 }
+
+// Trash lists the soft deleted users, the most recently deleted first
+func (controller *UserController) Trash(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	data, pagination, err := controller.UserService.FetchTrashed(ec.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	trashed := make([]trashedUser, 0, len(data))
+	for _, user := range data {
+		trashed = append(trashed, trashedUser{User: user, DeletedAt: user.DeletedAt.Time})
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: trashed,
+	})
+}
+
+func (controller *UserController) Restore(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	if err = controller.UserService.Restore(ec.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.RestoreSuccess)
+}
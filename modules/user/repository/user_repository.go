@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"github.com/google/uuid"
+	"go-movie-api/database"
 	"go-movie-api/domain"
 	"go-movie-api/utils"
 	"go-movie-api/utils/helper"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
 type userRepository struct {
@@ -77,10 +79,24 @@ func (repo *userRepository) FetchPagination(ctx context.Context, pagination *uti
 	return users, nil
 }
 
+func (repo *userRepository) FetchCursor(ctx context.Context, pagination *utils.CursorPagination) ([]domain.User, error) {
+	var users []domain.User
+
+	result := repo.db.WithContext(ctx).
+		Scopes(utils.CursorPaginate(users, "users", nil, pagination, repo.db)).
+		Find(&users)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return utils.CursorPage(users, pagination, repo.db)
+}
+
 func (repo *userRepository) FindByIDForUpdate(ctx context.Context, uuid uuid.UUID) (domain.User, error) {
 	var user domain.User
 
-	result := repo.db.WithContext(ctx).
+	result := database.Conn(ctx, repo.db).
 		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("uuid = ?", uuid.String()).
 		First(&user)
@@ -108,7 +124,7 @@ func (repo *userRepository) Store(ctx context.Context, user *domain.User) (domai
 }
 
 func (repo *userRepository) Update(ctx context.Context, user *domain.User) error {
-	result := repo.db.WithContext(ctx).Model(user).Where("uuid = ?", user.Uuid.String()).Updates(user)
+	result := database.Conn(ctx, repo.db).Model(user).Where("uuid = ?", user.Uuid.String()).Updates(user)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -116,8 +132,21 @@ func (repo *userRepository) Update(ctx context.Context, user *domain.User) error
 	return nil
 }
 
+func (repo *userRepository) Ban(ctx context.Context, userID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.User{}).
+		Where("id = ? AND banned_at IS NULL", userID).
+		Update("banned_at", time.Now())
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
 func (repo *userRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Where("uuid = ?", uuid.String()).Delete(&domain.User{})
+	result := database.Conn(ctx, repo.db).Where("uuid = ?", uuid.String()).Delete(&domain.User{})
 	if result.Error != nil {
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
@@ -127,11 +156,67 @@ func (repo *userRepository) SoftDelete(ctx context.Context, uuid uuid.UUID) erro
 }
 
 func (repo *userRepository) Delete(ctx context.Context, uuid uuid.UUID) error {
-	result := repo.db.WithContext(ctx).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.User{})
+	result := database.Conn(ctx, repo.db).Unscoped().Where("uuid = ?", uuid.String()).Delete(&domain.User{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *userRepository) FindByIDWithTrashedForUpdate(ctx context.Context, uuid uuid.UUID) (domain.User, error) {
+	var user domain.User
+
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = ?", uuid.String()).
+		First(&user)
 	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.User{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.User{}, result.Error
+	}
+
+	return user, nil
+}
+
+func (repo *userRepository) FetchTrashed(ctx context.Context, deletedBefore time.Time, pagination *utils.Pagination) ([]domain.User, error) {
+	var users []domain.User
+
+	filtered := database.Conn(ctx, repo.db).Scopes(database.Trashed(deletedBefore))
+	result := database.Conn(ctx, repo.db).
+		Scopes(database.Trashed(deletedBefore), utils.Paginate(users, pagination, filtered)).
+		Order("deleted_at DESC, id DESC").
+		Find(&users)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return users, nil
+}
+
+func (repo *userRepository) Restore(ctx context.Context, uuid uuid.UUID) error {
+	result := database.Conn(ctx, repo.db).
+		Unscoped().
+		Model(&domain.User{}).
+		Where("uuid = ? AND deleted_at IS NOT NULL", uuid.String()).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return helper.ConflictErr
+		}
 		utils.Logger.Error(result.Error.Error())
 		return result.Error
 	}
 
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
 	return nil
 }
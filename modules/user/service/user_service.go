@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"go-movie-api/configs"
 	"go-movie-api/domain"
 	"go-movie-api/utils"
 	errorHelper "go-movie-api/utils/helper"
@@ -13,14 +14,22 @@ import (
 )
 
 type userService struct {
-	userRepo domain.UserRepository
-	timeout  time.Duration
+	userRepo    domain.UserRepository
+	ratingRepo  domain.RatingRepository
+	movieRepo   domain.MovieRepository
+	commentRepo domain.CommentRepository
+	transactor  domain.Transactor
+	timeout     time.Duration
 }
 
-func NewUserService(userRepo domain.UserRepository, timeout time.Duration) domain.UserService {
+func NewUserService(userRepo domain.UserRepository, ratingRepo domain.RatingRepository, movieRepo domain.MovieRepository, commentRepo domain.CommentRepository, transactor domain.Transactor, timeout time.Duration) domain.UserService {
 	return &userService{
-		userRepo: userRepo,
-		timeout:  timeout,
+		userRepo:    userRepo,
+		ratingRepo:  ratingRepo,
+		movieRepo:   movieRepo,
+		commentRepo: commentRepo,
+		transactor:  transactor,
+		timeout:     timeout,
 	}
 }
 
@@ -61,6 +70,18 @@ func (service *userService) FetchPagination(ctx context.Context, page int, perPa
 	return users, pagination, nil
 }
 
+func (service *userService) FetchCursor(ctx context.Context, pagination utils.CursorPagination) ([]domain.User, utils.CursorPagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	users, err := service.userRepo.FetchCursor(ctx, &pagination)
+	if err != nil {
+		return nil, utils.CursorPagination{}, err
+	}
+
+	return users, pagination, nil
+}
+
 func (service *userService) FindByID(ctx context.Context, uuid uuid.UUID) (domain.User, error) {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
@@ -94,57 +115,132 @@ func (service *userService) Store(ctx context.Context, user *domain.User) (domai
 	return result, nil
 }
 
-func (service *userService) Update(ctx context.Context, user *domain.User) error {
+func (service *userService) Update(ctx context.Context, user *domain.User, version uint) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	_, err := service.userRepo.FindByIDForUpdate(ctx, user.Uuid)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errorHelper.NotFoundErr
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.userRepo.FindByIDForUpdate(ctx, user.Uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errorHelper.NotFoundErr
+			}
+			return err
 		}
-		return err
-	}
-
-	if err = service.userRepo.Update(ctx, user); err != nil {
-		return err
-	}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errorHelper.PreconditionFailedErr
+		}
+		user.Version = existing.Version + 1
 
-	return nil
+		return service.userRepo.Update(ctx, user)
+	})
 }
 
-func (service *userService) SoftDelete(ctx context.Context, uuid uuid.UUID) error {
+func (service *userService) Patch(ctx context.Context, uuid uuid.UUID, version uint, apply func(user *domain.User) error) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.userRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errorHelper.NotFoundErr
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := service.userRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errorHelper.NotFoundErr
+			}
+			return err
+		}
+		if version != domain.AnyVersion && existing.Version != version {
+			return errorHelper.PreconditionFailedErr
 		}
-		return err
-	}
 
-	if err := service.userRepo.SoftDelete(ctx, uuid); err != nil {
-		return err
-	}
+		patched := existing
+		if err = apply(&patched); err != nil {
+			return err
+		}
 
-	return nil
+		return service.userRepo.Update(ctx, &domain.User{
+			Uuid:     existing.Uuid,
+			FullName: patched.FullName,
+			Username: patched.Username,
+			Email:    patched.Email,
+			Version:  existing.Version + 1,
+		})
+	})
 }
 
-func (service *userService) Delete(ctx context.Context, uuid uuid.UUID) error {
+func (service *userService) SoftDelete(ctx context.Context, uuid uuid.UUID, version uint) error {
 	ctx, cancel := context.WithTimeout(ctx, service.timeout)
 	defer cancel()
 
-	if _, err := service.userRepo.FindByIDForUpdate(ctx, uuid); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errorHelper.NotFoundErr
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		user, err := service.userRepo.FindByIDForUpdate(ctx, uuid)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errorHelper.NotFoundErr
+			}
+			return err
 		}
-		return err
-	}
+		if version != domain.AnyVersion && user.Version != version {
+			return errorHelper.PreconditionFailedErr
+		}
+
+		return service.userRepo.SoftDelete(ctx, uuid)
+	})
+}
+
+func (service *userService) Delete(ctx context.Context, uuid uuid.UUID, version uint) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
 
-	if err := service.userRepo.Delete(ctx, uuid); err != nil {
-		return err
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		user, err := service.userRepo.FindByIDWithTrashedForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if version != domain.AnyVersion && user.Version != version {
+			return errorHelper.PreconditionFailedErr
+		}
+
+		movieIDs, err := service.ratingRepo.FetchMovieIDsByUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		// the comments of the user stay in their threads without their author, so that the replies to them stay too
+		if err = service.commentRepo.SoftDeleteByUser(ctx, user.ID); err != nil {
+			return err
+		}
+
+		// the ratings, sessions, votes and reports of the user go with it through the foreign keys
+		if err = service.userRepo.Delete(ctx, uuid); err != nil {
+			return err
+		}
+
+		if len(movieIDs) == 0 {
+			return nil
+		}
+		return service.movieRepo.RefreshRatingStats(ctx, movieIDs, configs.Env.Rating.WeightedMinimumVotes)
+	})
+}
+
+func (service *userService) FetchTrashed(ctx context.Context, page int, perPage int) ([]domain.User, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	users, err := service.userRepo.FetchTrashed(ctx, time.Time{}, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
 	}
 
-	return nil
+	return users, pagination, nil
+}
+
+func (service *userService) Restore(ctx context.Context, uuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.userRepo.Restore(ctx, uuid)
 }
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type indexRequest struct {
+	GenreID string `query:"genre_id" validate:"omitempty,uuid"`
+	Watched *bool  `query:"watched" validate:"omitempty"`
+}
+
+type storeRequest struct {
+	MovieUuid uuid.UUID `json:"movie_id" form:"movie_id" validate:"required"`
+}
+
+type watchedRequest struct {
+	Watched   *bool      `json:"watched" form:"watched" validate:"required"`
+	WatchedAt *time.Time `json:"watched_at" form:"watched_at" validate:"omitempty"`
+}
+
+type reorderRequest struct {
+	MovieUuids []uuid.UUID `json:"movie_ids" form:"movie_ids" validate:"required"`
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/middleware"
+	"go-movie-api/utils/response"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WatchlistController struct {
+	domain.WatchlistService
+}
+
+func NewWatchlistController(router *echo.Echo, watchlistService domain.WatchlistService) {
+	controller := &WatchlistController{
+		WatchlistService: watchlistService,
+	}
+
+	group := router.Group("/me/watchlist", middleware.AuthMiddleware.Handler)
+	group.GET("", controller.Index)
+	group.POST("", controller.Store)
+	group.PUT("/order", controller.Reorder)
+	group.PUT("/:uuid/watched", controller.MarkWatched)
+	group.DELETE("/:uuid", controller.Destroy)
+}
+
+func (controller *WatchlistController) Index(ec echo.Context) error {
+	page, err := strconv.Atoi(ec.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(ec.QueryParam("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 100
+	}
+
+	var request indexRequest
+	if err = ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err = ec.Validate(request); err != nil {
+		return err
+	}
+
+	filter := domain.WatchlistFilter{Watched: request.Watched}
+	if request.GenreID != "" {
+		filter.GenreID = uuid.MustParse(request.GenreID)
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, pagination, err := controller.WatchlistService.FetchPagination(ec.Request().Context(), authUser.ID, filter, page, perPage)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = make([]domain.WatchlistEntry, 0)
+	}
+
+	return ec.JSON(http.StatusOK, response.Result{
+		Meta: pagination,
+		Data: data,
+	})
+}
+
+func (controller *WatchlistController) Store(ec echo.Context) error {
+	var request storeRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.WatchlistService.Store(ec.Request().Context(), authUser.ID, request.MovieUuid)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, data)
+}
+
+// MarkWatched marks the movie as watched at watched_at, now by default, or clears the mark when watched is false
+func (controller *WatchlistController) MarkWatched(ec echo.Context) error {
+	var request watchedRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	var watchedAt *time.Time
+	if *request.Watched {
+		watchedAt = request.WatchedAt
+		if watchedAt == nil {
+			now := time.Now()
+			watchedAt = &now
+		}
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	data, err := controller.WatchlistService.MarkWatched(ec.Request().Context(), authUser.ID, id, watchedAt)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, data)
+}
+
+func (controller *WatchlistController) Reorder(ec echo.Context) error {
+	var request reorderRequest
+	if err := ec.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := ec.Validate(request); err != nil {
+		return err
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err := controller.WatchlistService.Reorder(ec.Request().Context(), authUser.ID, request.MovieUuids)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.UpdateSuccess)
+}
+
+func (controller *WatchlistController) Destroy(ec echo.Context) error {
+	id, err := uuid.Parse(ec.Param("uuid"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the id is not valid.")
+	}
+
+	authUser := ec.Get(middleware.AuthUserKey).(*domain.User)
+
+	err = controller.WatchlistService.Delete(ec.Request().Context(), authUser.ID, id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, response.DeleteSuccess)
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go-movie-api/database"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	"go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type watchlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWatchlistRepository(gormDB *gorm.DB) domain.WatchlistRepository {
+	return &watchlistRepository{db: gormDB}
+}
+
+func (repo *watchlistRepository) FetchPagination(ctx context.Context, userID uint, filter domain.WatchlistFilter, pagination *utils.Pagination) ([]domain.WatchlistEntry, error) {
+	var entries []domain.WatchlistEntry
+
+	filtered := database.Conn(ctx, repo.db).Where("user_id = ?", userID).Scopes(filterWatchlist(filter))
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ?", userID).
+		Scopes(filterWatchlist(filter), utils.Paginate(entries, pagination, filtered)).
+		Preload("Movie").
+		Preload("Movie.Genres").
+		Order("position ASC").
+		Order("id ASC").
+		Find(&entries)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	return entries, nil
+}
+
+func (repo *watchlistRepository) FindByUserAndMovie(ctx context.Context, userID uint, movieID uint) (domain.WatchlistEntry, error) {
+	var entry domain.WatchlistEntry
+
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		First(&entry)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.WatchlistEntry{}, helper.NotFoundErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.WatchlistEntry{}, result.Error
+	}
+
+	return entry, nil
+}
+
+func (repo *watchlistRepository) FetchMovieIDs(ctx context.Context, userID uint) (map[uuid.UUID]uint, error) {
+	var rows []struct {
+		ID   uint
+		Uuid uuid.UUID
+	}
+
+	result := database.Conn(ctx, repo.db).
+		Table("watchlist_entries").
+		Select("movies.id, movies.uuid").
+		Joins("JOIN movies ON movies.id = watchlist_entries.movie_id").
+		Where("watchlist_entries.user_id = ?", userID).
+		Scan(&rows)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return nil, result.Error
+	}
+
+	ids := make(map[uuid.UUID]uint, len(rows))
+	for _, row := range rows {
+		ids[row.Uuid] = row.ID
+	}
+
+	return ids, nil
+}
+
+func (repo *watchlistRepository) Lock(ctx context.Context, userID uint) error {
+	var id uint
+
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", userID).
+		Scan(&id)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *watchlistRepository) NextPosition(ctx context.Context, userID uint) (int32, error) {
+	var position int32
+
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.WatchlistEntry{}).
+		Select("COALESCE(MAX(position), 0) + 1").
+		Where("user_id = ?", userID).
+		Scan(&position)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return 0, result.Error
+	}
+
+	return position, nil
+}
+
+func (repo *watchlistRepository) Store(ctx context.Context, entry *domain.WatchlistEntry) (domain.WatchlistEntry, error) {
+	result := database.Conn(ctx, repo.db).Clauses(clause.Returning{}).Omit("Movie").Create(entry)
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return domain.WatchlistEntry{}, helper.ConflictErr
+		}
+		utils.Logger.Error(result.Error.Error())
+		return domain.WatchlistEntry{}, result.Error
+	}
+
+	return *entry, nil
+}
+
+func (repo *watchlistRepository) UpdateWatchedAt(ctx context.Context, entry *domain.WatchlistEntry) error {
+	result := database.Conn(ctx, repo.db).
+		Model(entry).
+		Select("watched_at", "updated_at").
+		Updates(entry)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *watchlistRepository) UpdatePosition(ctx context.Context, userID uint, movieID uint, position int32) error {
+	result := database.Conn(ctx, repo.db).
+		Model(&domain.WatchlistEntry{}).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		Update("position", position)
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func (repo *watchlistRepository) Delete(ctx context.Context, userID uint, movieID uint) error {
+	result := database.Conn(ctx, repo.db).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		Delete(&domain.WatchlistEntry{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return helper.NotFoundErr
+	}
+
+	return nil
+}
+
+func (repo *watchlistRepository) DeleteByMovie(ctx context.Context, movieID uint) error {
+	result := database.Conn(ctx, repo.db).Where("movie_id = ?", movieID).Delete(&domain.WatchlistEntry{})
+	if result.Error != nil {
+		utils.Logger.Error(result.Error.Error())
+		return result.Error
+	}
+
+	return nil
+}
+
+func filterWatchlist(filter domain.WatchlistFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.GenreID != uuid.Nil {
+			genreMovies := db.Session(&gorm.Session{NewDB: true}).
+				Table("movie_genres").
+				Select("movie_genres.movie_id").
+				Joins("JOIN genres ON genres.id = movie_genres.genre_id").
+				Where("genres.uuid = ?", filter.GenreID)
+			db = db.Where("watchlist_entries.movie_id IN (?)", genreMovies)
+		}
+
+		if filter.Watched != nil {
+			if *filter.Watched {
+				db = db.Where("watchlist_entries.watched_at IS NOT NULL")
+			} else {
+				db = db.Where("watchlist_entries.watched_at IS NULL")
+			}
+		}
+
+		return db
+	}
+}
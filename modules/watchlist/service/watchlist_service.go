@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/domain"
+	"go-movie-api/utils"
+	errors "go-movie-api/utils/helper"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type watchlistService struct {
+	watchlistRepo domain.WatchlistRepository
+	movieRepo     domain.MovieRepository
+	transactor    domain.Transactor
+	timeout       time.Duration
+}
+
+func NewWatchlistService(watchlistRepo domain.WatchlistRepository, movieRepo domain.MovieRepository, transactor domain.Transactor, timeout time.Duration) domain.WatchlistService {
+	return &watchlistService{
+		watchlistRepo: watchlistRepo,
+		movieRepo:     movieRepo,
+		transactor:    transactor,
+		timeout:       timeout,
+	}
+}
+
+func (service *watchlistService) FetchPagination(ctx context.Context, userID uint, filter domain.WatchlistFilter, page int, perPage int) ([]domain.WatchlistEntry, utils.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	pagination := utils.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+	entries, err := service.watchlistRepo.FetchPagination(ctx, userID, filter, &pagination)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return entries, pagination, nil
+}
+
+func (service *watchlistService) Store(ctx context.Context, userID uint, movieUuid uuid.UUID) (domain.WatchlistEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movie, err := service.movieRepo.FindByID(ctx, movieUuid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.WatchlistEntry{}, echo.NewHTTPError(http.StatusBadRequest, "The movie is not valid.")
+		}
+		return domain.WatchlistEntry{}, err
+	}
+
+	var result domain.WatchlistEntry
+	err = service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		// the lock keeps concurrent additions from taking the same position
+		if err := service.watchlistRepo.Lock(ctx, userID); err != nil {
+			return err
+		}
+
+		position, err := service.watchlistRepo.NextPosition(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		result, err = service.watchlistRepo.Store(ctx, &domain.WatchlistEntry{
+			UserID:   userID,
+			MovieID:  movie.ID,
+			Position: position,
+		})
+		return err
+	})
+	if err != nil {
+		return domain.WatchlistEntry{}, err
+	}
+	movie.Ratings = nil
+	result.Movie = &movie
+
+	return result, nil
+}
+
+func (service *watchlistService) MarkWatched(ctx context.Context, userID uint, movieUuid uuid.UUID, watchedAt *time.Time) (domain.WatchlistEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movie, entry, err := service.findEntry(ctx, userID, movieUuid)
+	if err != nil {
+		return domain.WatchlistEntry{}, err
+	}
+
+	entry.WatchedAt = watchedAt
+	if err = service.watchlistRepo.UpdateWatchedAt(ctx, &entry); err != nil {
+		return domain.WatchlistEntry{}, err
+	}
+	entry.Movie = &movie
+
+	return entry, nil
+}
+
+func (service *watchlistService) Reorder(ctx context.Context, userID uint, movieUuids []uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	return service.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := service.watchlistRepo.Lock(ctx, userID); err != nil {
+			return err
+		}
+
+		movieIDs, err := service.watchlistRepo.FetchMovieIDs(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if len(movieUuids) != len(movieIDs) {
+			return echo.NewHTTPError(http.StatusBadRequest, "The order must list every movie of the watchlist once.")
+		}
+
+		seen := make(map[uuid.UUID]bool, len(movieUuids))
+		for _, movieUuid := range movieUuids {
+			if _, ok := movieIDs[movieUuid]; !ok || seen[movieUuid] {
+				return echo.NewHTTPError(http.StatusBadRequest, "The order must list every movie of the watchlist once.")
+			}
+			seen[movieUuid] = true
+		}
+
+		for i, movieUuid := range movieUuids {
+			if err = service.watchlistRepo.UpdatePosition(ctx, userID, movieIDs[movieUuid], int32(i+1)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (service *watchlistService) Delete(ctx context.Context, userID uint, movieUuid uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	movie, err := service.movieRepo.FindByID(ctx, movieUuid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFoundErr
+		}
+		return err
+	}
+
+	return service.watchlistRepo.Delete(ctx, userID, movie.ID)
+}
+
+func (service *watchlistService) findEntry(ctx context.Context, userID uint, movieUuid uuid.UUID) (domain.Movie, domain.WatchlistEntry, error) {
+	movie, err := service.movieRepo.FindByID(ctx, movieUuid)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.Movie{}, domain.WatchlistEntry{}, errors.NotFoundErr
+		}
+		return domain.Movie{}, domain.WatchlistEntry{}, err
+	}
+	movie.Ratings = nil
+
+	entry, err := service.watchlistRepo.FindByUserAndMovie(ctx, userID, movie.ID)
+	if err != nil {
+		return domain.Movie{}, domain.WatchlistEntry{}, err
+	}
+
+	return movie, entry, nil
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps blobs as files under a directory and serves them itself, see ServeHTTP
+type LocalStore struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+	private    bool
+}
+
+// NewLocalStore creates a LocalStore writing under root, blobs are served from baseURL
+func NewLocalStore(root string, baseURL string, signingKey string, private bool) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	if private && signingKey == "" {
+		return nil, errors.New("a private local store needs a signing key")
+	}
+
+	return &LocalStore{
+		root:       root,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: []byte(signingKey),
+		private:    private,
+	}, nil
+}
+
+// Route is the path of the base URL, where ServeHTTP has to be mounted. A base URL without a path is refused,
+// the blobs would be served from the root and shadow the API.
+func (store *LocalStore) Route() (string, error) {
+	parsed, err := url.Parse(store.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("the base URL of the local store is not valid: %w", err)
+	}
+
+	route := strings.TrimSuffix(parsed.Path, "/")
+	if route == "" {
+		return "", errors.New("the base URL of the local store needs a path, e.g. http://localhost:9000/media")
+	}
+
+	return route, nil
+}
+
+func (store *LocalStore) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	name, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// write next to the destination and rename, so a blob is never served half written
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, body); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), name)
+}
+
+func (store *LocalStore) Delete(_ context.Context, key string) error {
+	name, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (store *LocalStore) URL(key string) string {
+	return store.baseURL + "/" + key
+}
+
+// SignedURL adds the expiry time and its HMAC signature to the URL, they are checked by ServeHTTP
+func (store *LocalStore) SignedURL(key string, expiry time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", store.sign(key, expires))
+
+	return store.URL(key) + "?" + query.Encode(), nil
+}
+
+// ServeHTTP serves the blob named by the request path, a private store only serves signed URLs that have not expired
+func (store *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+	if store.private && !store.verify(key, r.URL.Query().Get("expires"), r.URL.Query().Get("signature")) {
+		http.Error(w, "the signature is not valid.", http.StatusForbidden)
+		return
+	}
+
+	name, err := store.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if info, err := os.Stat(name); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeFile(w, r, name)
+}
+
+func (store *LocalStore) verify(key string, expires string, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(store.sign(key, expires)))
+}
+
+func (store *LocalStore) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, store.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps the key to a file under the root, rejecting keys that would leave it
+func (store *LocalStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", errors.New("the blob key is not valid")
+	}
+
+	return filepath.Join(store.root, filepath.FromSlash(cleaned)), nil
+}
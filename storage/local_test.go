@@ -0,0 +1,35 @@
+package storage
+
+import "testing"
+
+func TestLocalStoreRoute(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		route   string
+		ok      bool
+	}{
+		{baseURL: "http://localhost:9000/media", route: "/media", ok: true},
+		{baseURL: "http://localhost:9000/media/", route: "/media", ok: true},
+		{baseURL: "/files/blobs", route: "/files/blobs", ok: true},
+		{baseURL: "http://localhost:9000", ok: false},
+		{baseURL: "http://localhost:9000/", ok: false},
+		{baseURL: "", ok: false},
+		{baseURL: "http://[::1", ok: false},
+	}
+
+	for _, test := range tests {
+		store, err := NewLocalStore(t.TempDir(), test.baseURL, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		route, err := store.Route()
+		if test.ok != (err == nil) {
+			t.Errorf("Route() of %q: error %v", test.baseURL, err)
+			continue
+		}
+		if route != test.route {
+			t.Errorf("Route() of %q = %q, want %q", test.baseURL, route, test.route)
+		}
+	}
+}
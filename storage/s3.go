@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateFormat      = "20060102T150405Z"
+	// s3SignedHeaders are the headers sign covers
+	s3SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	// s3MaxExpiry is the longest validity of a presigned URL allowed by S3
+	s3MaxExpiry = 7 * 24 * time.Hour
+)
+
+// S3Config addresses a bucket of S3 or of an S3 compatible server, like a local MinIO
+type S3Config struct {
+	// Endpoint is the scheme and host of the server, empty for AWS in Region
+	Endpoint string
+	Region   string
+	Bucket   string
+	// AccessKey and SecretKey sign every request with AWS Signature Version 4
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket in the path instead of the host, most S3 compatible servers need it
+	PathStyle bool
+	// BaseURL replaces the bucket address in public URLs, e.g. for a CDN in front of the bucket
+	BaseURL string
+}
+
+// S3Store keeps blobs as objects of an S3 bucket, requests are signed by hand to avoid pulling in the AWS SDK
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Store creates an S3Store for the bucket of config
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, errors.New("the s3 store needs a bucket and a region")
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (store *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	// the payload hash is part of the signature, so the body is buffered once to hash it
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if size >= 0 && int64(len(payload)) != size {
+		return fmt.Errorf("the blob %s is %d bytes, expected %d", key, len(payload), size)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, store.objectURL(key).String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.ContentLength = int64(len(payload))
+	request.Header.Set("Content-Type", contentType)
+	store.sign(request, payload, time.Now().UTC())
+
+	return store.do(request)
+}
+
+func (store *S3Store) Delete(ctx context.Context, key string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, store.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	store.sign(request, nil, time.Now().UTC())
+
+	return store.do(request)
+}
+
+func (store *S3Store) URL(key string) string {
+	if store.config.BaseURL != "" {
+		return store.config.BaseURL + "/" + escapePath(key)
+	}
+
+	return store.objectURL(key).String()
+}
+
+// SignedURL presigns a GET of the object with the expiry in the query string
+func (store *S3Store) SignedURL(key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > s3MaxExpiry {
+		return "", fmt.Errorf("the signed url expiry must be between 1s and %s", s3MaxExpiry)
+	}
+
+	return store.presign(key, expiry, time.Now().UTC()), nil
+}
+
+func (store *S3Store) presign(key string, expiry time.Duration, now time.Time) string {
+	object := store.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", store.config.AccessKey+"/"+store.scope(now))
+	query.Set("X-Amz-Date", now.Format(s3DateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	query.Set("X-Amz-Signature", store.signature(presignedCanonicalRequest(object, query), now))
+	object.RawQuery = canonicalQuery(query)
+
+	return object.String()
+}
+
+// presignedCanonicalRequest is the canonical request of a presigned GET, which signs only the host
+func presignedCanonicalRequest(object *url.URL, query url.Values) string {
+	return strings.Join([]string{
+		http.MethodGet,
+		object.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + object.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+}
+
+// objectURL addresses the object in path style or virtual hosted style
+func (store *S3Store) objectURL(key string) *url.URL {
+	object := *store.endpoint
+	escaped := "/" + escapePath(key)
+
+	if store.config.PathStyle {
+		escaped = "/" + store.config.Bucket + escaped
+	} else {
+		object.Host = store.config.Bucket + "." + object.Host
+	}
+
+	object.Path, _ = url.PathUnescape(escaped)
+	object.RawPath = escaped
+
+	return &object
+}
+
+// sign adds the Signature Version 4 Authorization header to the request
+func (store *S3Store) sign(request *http.Request, payload []byte, now time.Time) {
+	payloadHash := sha256.Sum256(payload)
+	hashed := hex.EncodeToString(payloadHash[:])
+
+	request.Header.Set("X-Amz-Date", now.Format(s3DateFormat))
+	request.Header.Set("X-Amz-Content-Sha256", hashed)
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, store.config.AccessKey, store.scope(now), s3SignedHeaders, store.signature(canonicalRequest(request), now)))
+}
+
+// canonicalRequest is the canonical request of a request with the headers set by sign
+func canonicalRequest(request *http.Request) string {
+	hashed := request.Header.Get("X-Amz-Content-Sha256")
+
+	return strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQuery(request.URL.Query()),
+		"host:" + request.URL.Host + "\n" +
+			"x-amz-content-sha256:" + hashed + "\n" +
+			"x-amz-date:" + request.Header.Get("X-Amz-Date") + "\n",
+		s3SignedHeaders,
+		hashed,
+	}, "\n")
+}
+
+func (store *S3Store) signature(canonicalRequest string, now time.Time) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3DateFormat),
+		store.scope(now),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+store.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, store.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (store *S3Store) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + store.config.Region + "/" + s3Service + "/aws4_request"
+}
+
+func (store *S3Store) do(request *http.Request) error {
+	response, err := store.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("s3 %s %s: %s %s", request.Method, request.URL.Path, response.Status, message)
+	}
+
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery sorts the parameters and escapes them the way Signature Version 4 expects
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escape(key, false)+"="+escape(value, false))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func escapePath(key string) string {
+	return escape(key, true)
+}
+
+// escape percent-encodes every byte but the unreserved characters, and the slashes when keepSlash is set
+func escape(value string, keepSlash bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || keepSlash && c == '/' {
+			builder.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", c)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"fmt"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// NewBlobStore creates the blob store of the configured driver
+func NewBlobStore() (domain.BlobStore, error) {
+	config := configs.Env.Storage
+
+	switch config.Driver {
+	case DriverLocal, "":
+		return NewLocalStore(config.Local.Path, config.Local.BaseURL, config.Local.SigningKey, config.Private)
+	case DriverS3:
+		return NewS3Store(S3Config{
+			Endpoint:  config.S3.Endpoint,
+			Region:    config.S3.Region,
+			Bucket:    config.S3.Bucket,
+			AccessKey: config.S3.AccessKey,
+			SecretKey: config.S3.SecretKey,
+			PathStyle: config.S3.PathStyle,
+			BaseURL:   config.S3.BaseURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+	}
+}
+
+// NewSnapshotStore creates the store of the export snapshots. With the local driver it writes under
+// export.snapshot.path, which unlike the directory of the local blob store is not served. S3 objects are
+// private unless the bucket policy opens them, snapshots must only be handed out through signed URLs.
+func NewSnapshotStore() (domain.BlobStore, error) {
+	if configs.Env.Storage.Driver != DriverLocal && configs.Env.Storage.Driver != "" {
+		return NewBlobStore()
+	}
+
+	root := configs.Env.Export.Snapshot.Path
+	if root == "" {
+		return nil, fmt.Errorf("the local snapshot store needs export.snapshot.path")
+	}
+
+	return NewLocalStore(root, "", "", false)
+}
@@ -0,0 +1,20 @@
+package utils
+
+import "context"
+
+type userIDKey struct{}
+
+// ContextWithUserID stores the id of the user acting in ctx, so that services can attribute their changes
+func ContextWithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID returns the id of the user acting in ctx, nil for anonymous requests and commands
+func UserID(ctx context.Context) *uint {
+	userID, ok := ctx.Value(userIDKey{}).(uint)
+	if !ok {
+		return nil
+	}
+
+	return &userID
+}
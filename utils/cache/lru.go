@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a fixed size, concurrency safe cache that evicts the least recently used entry when it is full
+type LRU[K comparable, V any] struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  *list.List
+	items    map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewLRU creates an LRU cache holding at most capacity entries
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &LRU[K, V]{
+		capacity: capacity,
+		entries:  list.New(),
+		items:    make(map[K]*list.Element, capacity),
+	}
+}
+
+// Get returns the cached value of key and marks it as recently used
+func (cache *LRU[K, V]) Get(key K) (V, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	cache.entries.MoveToFront(element)
+
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Add stores value under key, evicting the least recently used entry if the cache is full
+func (cache *LRU[K, V]) Add(key K, value V) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.items[key]; ok {
+		element.Value.(*entry[K, V]).value = value
+		cache.entries.MoveToFront(element)
+		return
+	}
+
+	cache.items[key] = cache.entries.PushFront(&entry[K, V]{key: key, value: value})
+	if cache.entries.Len() > cache.capacity {
+		oldest := cache.entries.Back()
+		cache.entries.Remove(oldest)
+		delete(cache.items, oldest.Value.(*entry[K, V]).key)
+	}
+}
+
+// Purge removes every entry
+func (cache *LRU[K, V]) Purge() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries.Init()
+	cache.items = make(map[K]*list.Element, cache.capacity)
+}
+
+// Len returns the number of cached entries
+func (cache *LRU[K, V]) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.entries.Len()
+}
@@ -0,0 +1,361 @@
+package utils
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InvalidCursorErr is returned when a cursor cannot be decoded
+var InvalidCursorErr = errors.New("the cursor is not valid.")
+
+// CursorPagination describes a keyset paginated page. The cursors are opaque to clients,
+// they encode the sort they were made for and the sort key values and id of the first or last row of a page.
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+	WithTotal  bool   `json:"-"`
+
+	cursor *cursor
+	fields []SortField
+	// nullable tells which fields can hold NULL
+	nullable []bool
+}
+
+type cursor struct {
+	Sort     string   `json:"s"`
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// CursorRequested reports whether a listing is requested in cursor mode instead of page mode, by a cursor or by
+// mode=cursor for the first page. A limit alone does not switch modes.
+func CursorRequested(ec echo.Context) bool {
+	return ec.QueryParams().Has("cursor") || ec.QueryParam("mode") == "cursor"
+}
+
+// NewCursorPagination reads the cursor, limit and with_total query parameters.
+// The total is only counted when with_total=true since skipping the COUNT(*) is the point of cursor mode.
+func NewCursorPagination(ec echo.Context) (CursorPagination, error) {
+	limit, err := strconv.Atoi(ec.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	withTotal, _ := strconv.ParseBool(ec.QueryParam("with_total"))
+	pagination := CursorPagination{
+		Limit:     limit,
+		WithTotal: withTotal,
+	}
+
+	if value := ec.QueryParam("cursor"); value != "" {
+		decoded, err := decodeCursor(value)
+		if err != nil {
+			return CursorPagination{}, err
+		}
+		pagination.cursor = &decoded
+	}
+
+	return pagination, nil
+}
+
+// CursorPaginate returns a scope selecting the rows after, or before for a previous cursor, the pagination's cursor.
+// Rows are ordered by sort followed by id, db is used to count the total when it is requested.
+func CursorPaginate(value interface{}, table string, sort []SortField, pagination *CursorPagination, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
+	pagination.fields = keysetFields(sort)
+	pagination.nullable = nullableFields(db, value, pagination.fields)
+
+	if pagination.WithTotal {
+		var totalData int64
+		db.Model(value).Count(&totalData)
+		pagination.Total = &totalData
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		backward := pagination.cursor != nil && pagination.cursor.Backward
+
+		if pagination.cursor != nil {
+			// the values of a cursor only mean something in the order it was made for
+			if pagination.cursor.Sort != sortSpec(pagination.fields) {
+				_ = db.AddError(InvalidCursorErr)
+				return db
+			}
+
+			condition, err := keysetCondition(table, pagination.fields, pagination.nullable, pagination.cursor)
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			db = db.Where(condition)
+		}
+
+		columns := make([]clause.OrderByColumn, 0, len(pagination.fields))
+		for _, field := range pagination.fields {
+			columns = append(columns, clause.OrderByColumn{
+				Column: clause.Column{Table: table, Name: field.Field},
+				Desc:   field.Desc != backward,
+			})
+		}
+
+		// one extra row tells whether another page exists
+		return db.Clauses(clause.OrderBy{Columns: columns}).Limit(pagination.Limit + 1)
+	}
+}
+
+// CursorPage trims the extra row selected by CursorPaginate, restores the order of a previous page
+// and fills the next and previous cursors from the first and last rows
+func CursorPage[T any](rows []T, pagination *CursorPagination, db *gorm.DB) ([]T, error) {
+	backward := pagination.cursor != nil && pagination.cursor.Backward
+	hasMore := len(rows) > pagination.Limit
+	if hasMore {
+		rows = rows[:pagination.Limit]
+	}
+
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return rows, nil
+	}
+
+	hasNext := hasMore || backward
+	hasPrev := pagination.cursor != nil && (!backward || hasMore)
+
+	var err error
+	if hasNext {
+		if pagination.NextCursor, err = encodeCursor(db, rows[len(rows)-1], pagination.fields, false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if pagination.PrevCursor, err = encodeCursor(db, rows[0], pagination.fields, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+// keysetFields appends id as the final tie breaker unless the sort already contains it
+func keysetFields(sort []SortField) []SortField {
+	fields := make([]SortField, 0, len(sort)+1)
+	for _, field := range sort {
+		fields = append(fields, field)
+		if field.Field == "id" {
+			return fields
+		}
+	}
+
+	return append(fields, SortField{Field: "id"})
+}
+
+// sortSpec writes the fields like the sort parameter, e.g. "-year,id"
+func sortSpec(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// nullableFields tells which fields of the model of value are pointers or scanners, only those can hold NULL.
+// Every field is taken as nullable when the model cannot be parsed.
+func nullableFields(db *gorm.DB, value interface{}, fields []SortField) []bool {
+	nullable := make([]bool, len(fields))
+	statement := &gorm.Statement{DB: db}
+	parsed := statement.Parse(value) == nil
+
+	for i, field := range fields {
+		if !parsed {
+			nullable[i] = true
+			continue
+		}
+		if schemaField := statement.Schema.LookUpField(field.Field); schemaField != nil {
+			_, scanner := reflect.New(schemaField.FieldType).Interface().(sql.Scanner)
+			nullable[i] = schemaField.FieldType.Kind() == reflect.Pointer || scanner
+		}
+	}
+
+	return nullable
+}
+
+// keysetCondition builds (f0 > v0) OR (f0 = v0 AND f1 > v1) OR ..., flipping each comparison for descending fields.
+// NULL sorts after every value, as Postgres orders it by default.
+func keysetCondition(table string, fields []SortField, nullable []bool, cursor *cursor) (clause.Expression, error) {
+	if len(cursor.Values) != len(fields) {
+		return nil, InvalidCursorErr
+	}
+
+	values := make([]interface{}, 0, len(cursor.Values))
+	for _, encoded := range cursor.Values {
+		value, err := decodeCursorValue(encoded)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	alternatives := make([]clause.Expression, 0, len(fields))
+	for i, field := range fields {
+		conditions := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			column := clause.Column{Table: table, Name: fields[j].Field}
+			if values[j] == nil {
+				conditions = append(conditions, clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}})
+				continue
+			}
+			conditions = append(conditions, clause.Expr{SQL: "? = ?", Vars: []interface{}{column, values[j]}})
+		}
+
+		column := clause.Column{Table: table, Name: field.Field}
+		greater := field.Desc == cursor.Backward
+		switch {
+		case greater && values[i] == nil:
+			// nothing is greater than NULL
+			continue
+		case greater && nullable[i]:
+			conditions = append(conditions, clause.Expr{SQL: "(? > ? OR ? IS NULL)", Vars: []interface{}{column, values[i], column}})
+		case greater:
+			conditions = append(conditions, clause.Expr{SQL: "? > ?", Vars: []interface{}{column, values[i]}})
+		case values[i] == nil:
+			conditions = append(conditions, clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{column}})
+		default:
+			conditions = append(conditions, clause.Expr{SQL: "? < ?", Vars: []interface{}{column, values[i]}})
+		}
+
+		alternatives = append(alternatives, clause.And(conditions...))
+	}
+
+	if len(alternatives) == 0 {
+		return clause.Expr{SQL: "FALSE"}, nil
+	}
+
+	return clause.Or(alternatives...), nil
+}
+
+func encodeCursor(db *gorm.DB, row interface{}, fields []SortField, backward bool) (string, error) {
+	statement := &gorm.Statement{DB: db, Context: db.Statement.Context}
+	if err := statement.Parse(row); err != nil {
+		return "", err
+	}
+
+	rowValue := reflect.Indirect(reflect.ValueOf(row))
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		schemaField := statement.Schema.LookUpField(field.Field)
+		if schemaField == nil {
+			return "", fmt.Errorf("unknown cursor field %q", field.Field)
+		}
+
+		value, _ := schemaField.ValueOf(statement.Context, rowValue)
+		encoded, err := encodeCursorValue(value)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, encoded)
+	}
+
+	data, err := json.Marshal(cursor{Sort: sortSpec(fields), Values: values, Backward: backward})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor{}, InvalidCursorErr
+	}
+
+	var decoded cursor
+	if err = json.Unmarshal(data, &decoded); err != nil || len(decoded.Values) == 0 {
+		return cursor{}, InvalidCursorErr
+	}
+
+	return decoded, nil
+}
+
+// encodeCursorValue prefixes every value with its kind so that it is decoded back to the same Go type
+func encodeCursorValue(value interface{}) (string, error) {
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return "n:", nil
+		}
+		return encodeCursorValue(reflected.Elem().Interface())
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "n:", nil
+	case time.Time:
+		return "t:" + v.Format(time.RFC3339Nano), nil
+	case string:
+		return "s:" + v, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("i:%d", v), nil
+	case float32:
+		return "f:" + strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return "f:" + strconv.FormatFloat(v, 'g', -1, 64), nil
+	case driver.Valuer:
+		// e.g. gorm.DeletedAt or sql.NullString
+		inner, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		return encodeCursorValue(inner)
+	}
+
+	return "", fmt.Errorf("unsupported cursor value %T", value)
+}
+
+func decodeCursorValue(encoded string) (interface{}, error) {
+	kind, value, ok := strings.Cut(encoded, ":")
+	if !ok {
+		return nil, InvalidCursorErr
+	}
+
+	var decoded interface{}
+	var err error
+	switch kind {
+	case "n":
+		return nil, nil
+	case "t":
+		decoded, err = time.Parse(time.RFC3339Nano, value)
+	case "s":
+		decoded = value
+	case "i":
+		decoded, err = strconv.ParseInt(value, 10, 64)
+	case "f":
+		decoded, err = strconv.ParseFloat(value, 64)
+	default:
+		err = InvalidCursorErr
+	}
+	if err != nil {
+		return nil, InvalidCursorErr
+	}
+
+	return decoded, nil
+}
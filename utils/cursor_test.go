@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils/tests"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cursorRow is a model with nullable sort keys, Rating and Year are NULL where they are nil
+type cursorRow struct {
+	ID     uint
+	Rating *float32
+	Year   *int32
+	Title  string
+}
+
+func newCursorDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// compareValues orders the values of a column as Postgres does, NULL after every value
+func compareValues(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+	case float64:
+		b := b.(float64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+
+	return 0
+}
+
+// matches evaluates a keyset condition on a row of column values, comparisons with NULL do not hold
+func matches(t *testing.T, expression clause.Expression, row map[string]interface{}) bool {
+	t.Helper()
+
+	switch expression := expression.(type) {
+	case clause.OrConditions:
+		for _, alternative := range expression.Exprs {
+			if matches(t, alternative, row) {
+				return true
+			}
+		}
+		return false
+	case clause.AndConditions:
+		for _, condition := range expression.Exprs {
+			if !matches(t, condition, row) {
+				return false
+			}
+		}
+		return true
+	case clause.Expr:
+		if expression.SQL == "FALSE" {
+			return false
+		}
+		value := row[expression.Vars[0].(clause.Column).Name]
+		switch expression.SQL {
+		case "? IS NULL":
+			return value == nil
+		case "? IS NOT NULL":
+			return value != nil
+		}
+		if value == nil {
+			return expression.SQL == "(? > ? OR ? IS NULL)"
+		}
+		switch expression.SQL {
+		case "? = ?":
+			return compareValues(value, expression.Vars[1]) == 0
+		case "? < ?":
+			return compareValues(value, expression.Vars[1]) < 0
+		case "? > ?", "(? > ? OR ? IS NULL)":
+			return compareValues(value, expression.Vars[1]) > 0
+		}
+	}
+
+	t.Fatalf("unexpected condition %#v", expression)
+	return false
+}
+
+// TestKeysetCondition checks, for every row of a sorted table, that the condition of a cursor on the row selects
+// exactly the rows after it, or before it for a previous cursor
+func TestKeysetCondition(t *testing.T) {
+	var rows []map[string]interface{}
+	id := int64(0)
+	for _, rating := range []interface{}{nil, 7.5, 9.0} {
+		for _, year := range []interface{}{nil, int64(1995), int64(1998)} {
+			for _, title := range []string{"Heat", "Ronin"} {
+				id++
+				rows = append(rows, map[string]interface{}{"rating": rating, "year": year, "title": title, "id": id})
+			}
+		}
+	}
+
+	sorts := [][]SortField{
+		{{Field: "rating", Desc: true}, {Field: "year"}, {Field: "title", Desc: true}},
+		{{Field: "year", Desc: true}, {Field: "rating", Desc: true}},
+		{{Field: "rating"}, {Field: "title"}},
+		{{Field: "title"}, {Field: "year"}, {Field: "rating"}},
+		{{Field: "id", Desc: true}},
+	}
+	nullableColumns := map[string]bool{"rating": true, "year": true}
+
+	for _, sortFields := range sorts {
+		fields := keysetFields(sortFields)
+		nullable := make([]bool, len(fields))
+		for i, field := range fields {
+			nullable[i] = nullableColumns[field.Field]
+		}
+
+		sorted := append([]map[string]interface{}(nil), rows...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			for _, field := range fields {
+				order := compareValues(sorted[i][field.Field], sorted[j][field.Field])
+				if field.Desc {
+					order = -order
+				}
+				if order != 0 {
+					return order < 0
+				}
+			}
+			return false
+		})
+
+		for k, row := range sorted {
+			values := make([]string, 0, len(fields))
+			for _, field := range fields {
+				encoded, err := encodeCursorValue(row[field.Field])
+				if err != nil {
+					t.Fatal(err)
+				}
+				values = append(values, encoded)
+			}
+
+			for _, backward := range []bool{false, true} {
+				condition, err := keysetCondition("movies", fields, nullable, &cursor{Sort: sortSpec(fields), Values: values, Backward: backward})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got []interface{}
+				for _, candidate := range sorted {
+					if matches(t, condition, candidate) {
+						got = append(got, candidate["id"])
+					}
+				}
+				want := sorted[k+1:]
+				if backward {
+					want = sorted[:k]
+				}
+				if fmt.Sprint(got) != fmt.Sprint(ids(want)) {
+					t.Errorf("sort %s, cursor on row %v, backward %t: rows %v, want %v", sortSpec(fields), row, backward, got, ids(want))
+				}
+			}
+		}
+	}
+}
+
+func ids(rows []map[string]interface{}) []interface{} {
+	ids := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row["id"])
+	}
+	return ids
+}
+
+func TestNullableFields(t *testing.T) {
+	db := newCursorDB(t)
+	fields := keysetFields([]SortField{{Field: "rating"}, {Field: "year"}, {Field: "title"}})
+
+	nullable := nullableFields(db, &cursorRow{}, fields)
+	if fmt.Sprint(nullable) != "[true true false false]" {
+		t.Errorf("nullable of rating, year, title, id = %v", nullable)
+	}
+}
+
+func TestCursorPage(t *testing.T) {
+	db := newCursorDB(t)
+	year := int32(1995)
+	rows := []cursorRow{
+		{ID: 1, Year: &year, Title: "Heat"},
+		{ID: 2, Title: "Ronin"},
+		{ID: 3, Title: "Thief"},
+	}
+	sortFields := []SortField{{Field: "year", Desc: true}}
+
+	// a first page with a row more than the limit
+	pagination := CursorPagination{Limit: 2}
+	CursorPaginate(&cursorRow{}, "movies", sortFields, &pagination, db)
+	page, err := CursorPage(append([]cursorRow(nil), rows...), &pagination, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != 1 || page[1].ID != 2 || pagination.NextCursor == "" || pagination.PrevCursor != "" {
+		t.Fatalf("first page = %+v, next %q, prev %q", page, pagination.NextCursor, pagination.PrevCursor)
+	}
+	next, err := decodeCursor(pagination.NextCursor)
+	if err != nil || next.Sort != "-year,id" || next.Backward || fmt.Sprint(next.Values) != "[n: i:2]" {
+		t.Errorf("next cursor = %+v, %v", next, err)
+	}
+
+	// a previous page is selected in the reverse order, with a row more than the limit, and put back in order
+	prev := &cursor{Sort: "-year,id", Values: []string{"n:", "i:3"}, Backward: true}
+	pagination = CursorPagination{Limit: 1, cursor: prev}
+	CursorPaginate(&cursorRow{}, "movies", sortFields, &pagination, db)
+	page, err = CursorPage([]cursorRow{rows[1], rows[0]}, &pagination, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != 2 || pagination.NextCursor == "" || pagination.PrevCursor == "" {
+		t.Fatalf("previous page = %+v, next %q, prev %q", page, pagination.NextCursor, pagination.PrevCursor)
+	}
+	before, err := decodeCursor(pagination.PrevCursor)
+	if err != nil || !before.Backward || fmt.Sprint(before.Values) != "[n: i:2]" {
+		t.Errorf("previous cursor = %+v, %v", before, err)
+	}
+
+	// the first page reached backwards has no previous page
+	pagination = CursorPagination{Limit: 2, cursor: prev}
+	CursorPaginate(&cursorRow{}, "movies", sortFields, &pagination, db)
+	page, err = CursorPage([]cursorRow{rows[1], rows[0]}, &pagination, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != 1 || pagination.NextCursor == "" || pagination.PrevCursor != "" {
+		t.Errorf("first page reached backwards = %+v, next %q, prev %q", page, pagination.NextCursor, pagination.PrevCursor)
+	}
+}
+
+func TestCursorPaginateOrder(t *testing.T) {
+	db := newCursorDB(t)
+	sortFields := []SortField{{Field: "year", Desc: true}, {Field: "title"}}
+
+	for _, backward := range []bool{false, true} {
+		pagination := CursorPagination{Limit: 10, cursor: &cursor{Sort: "-year,title,id", Values: []string{"i:1995", "s:Heat", "i:1"}, Backward: backward}}
+		var rows []cursorRow
+		statement := db.Model(&cursorRow{}).Scopes(CursorPaginate(&cursorRow{}, "movies", sortFields, &pagination, db)).Find(&rows).Statement
+
+		orderBy := statement.Clauses["ORDER BY"].Expression.(clause.OrderBy)
+		var got []string
+		for _, column := range orderBy.Columns {
+			got = append(got, fmt.Sprintf("%s %t", column.Column.Name, column.Desc))
+		}
+		want := "[year true title false id false]"
+		if backward {
+			want = "[year false title true id true]"
+		}
+		if fmt.Sprint(got) != want {
+			t.Errorf("order of a cursor backward %t = %v, want %s", backward, got, want)
+		}
+		if limit := statement.Clauses["LIMIT"].Expression.(clause.Limit); *limit.Limit != 11 {
+			t.Errorf("limit %d, want one row more than the page", *limit.Limit)
+		}
+	}
+}
+
+func TestInvalidCursors(t *testing.T) {
+	encode := func(data string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(data))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "not JSON", cursor: encode(`year=1995`)},
+		{name: "without values", cursor: encode(`{"s":"-year,id","v":[]}`)},
+		{name: "value without kind", cursor: encode(`{"s":"-year,id","v":["1995","i:1"]}`)},
+		{name: "value of an unknown kind", cursor: encode(`{"s":"-year,id","v":["x:1995","i:1"]}`)},
+		{name: "integer that is not one", cursor: encode(`{"s":"-year,id","v":["i:1995.5","i:1"]}`)},
+		{name: "time that is not one", cursor: encode(`{"s":"-year,id","v":["t:yesterday","i:1"]}`)},
+		{name: "too few values", cursor: encode(`{"s":"-year,id","v":["i:1"]}`)},
+		{name: "too many values", cursor: encode(`{"s":"-year,id","v":["i:1995","i:1","i:2"]}`)},
+		{name: "another sort", cursor: encode(`{"s":"year,id","v":["i:1995","i:1"]}`)},
+	}
+
+	db := newCursorDB(t)
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", "/movies?cursor="+url.QueryEscape(test.cursor), nil)
+		ec := echo.New().NewContext(request, httptest.NewRecorder())
+
+		pagination, err := NewCursorPagination(ec)
+		if err == nil {
+			var rows []cursorRow
+			err = db.Model(&cursorRow{}).
+				Scopes(CursorPaginate(&cursorRow{}, "movies", []SortField{{Field: "year", Desc: true}}, &pagination, db)).
+				Find(&rows).Error
+		}
+		if !errors.Is(err, InvalidCursorErr) {
+			t.Errorf("%s: error %v, want InvalidCursorErr", test.name, err)
+		}
+	}
+}
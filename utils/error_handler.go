@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"go-movie-api/token"
@@ -24,12 +25,20 @@ func ErrorHandler(err error, ec echo.Context) {
 		errorMsg = err.Error()
 	}
 
+	// Point the client to the related resource if the error carries a link
+	var link string
+	var linkedError *helper.LinkedError
+	if errors.As(err, &linkedError) {
+		link = linkedError.Link
+		ec.Response().Header().Set(echo.HeaderLocation, link)
+	}
+
 	// record error to log
 	Logger.Error(errorMsg)
 
 	// Return JSON with status code and error message
 	if !ec.Response().Committed {
-		ec.JSON(statusCode, response.Error{Message: errorMsg, Status: statusCode})
+		ec.JSON(statusCode, response.Error{Message: errorMsg, Status: statusCode, Link: link})
 	}
 }
 
@@ -38,18 +47,20 @@ func getStatusCode(err error) int {
 		return http.StatusOK
 	}
 
-	switch err {
-	case helper.InternalServerErr:
+	switch {
+	case errors.Is(err, helper.InternalServerErr):
 		return http.StatusInternalServerError
-	case helper.NotFoundErr:
+	case errors.Is(err, helper.NotFoundErr):
 		return http.StatusNotFound
-	case helper.ConflictErr:
+	case errors.Is(err, helper.ConflictErr):
 		return http.StatusConflict
-	case helper.BadParamInputErr, helper.IncorrectCredentialErr:
+	case errors.Is(err, helper.BadParamInputErr), errors.Is(err, helper.IncorrectCredentialErr), errors.Is(err, InvalidCursorErr):
 		return http.StatusBadRequest
-	case helper.ForbiddenErr:
+	case errors.Is(err, helper.PreconditionFailedErr):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, helper.ForbiddenErr):
 		return http.StatusForbidden
-	case helper.UnauthorizedErr, token.InvalidTokenErr, token.ExpiredTokenErr:
+	case errors.Is(err, helper.UnauthorizedErr), errors.Is(err, token.InvalidTokenErr), errors.Is(err, token.ExpiredTokenErr):
 		return http.StatusUnauthorized
 	default:
 		return http.StatusInternalServerError
@@ -0,0 +1,115 @@
+// Package etag tags the representations of versioned resources and evaluates the If-Match and If-None-Match
+// preconditions of requests against them.
+package etag
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
+	"go-movie-api/domain"
+	"go-movie-api/utils/helper"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// Tag is the entity tag of a representation of the version of a resource, e.g. "3-8c2f1d0e5b7a6943". The hash
+// of the body tells apart the representations of one version, in other locales or with newer rating aggregates.
+func Tag(version uint, body []byte) string {
+	hash := fnv.New64a()
+	hash.Write(body)
+
+	return fmt.Sprintf(`"%d-%x"`, version, hash.Sum64())
+}
+
+// Version reads the version out of a strong entity tag made by Tag, a list of tags has none
+func Version(tag string) (uint, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' || strings.ContainsAny(tag[1:len(tag)-1], `",`) {
+		return 0, false
+	}
+
+	value, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	version, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+
+	return uint(version), true
+}
+
+// IfMatch returns the version the If-Match header of the request expects, domain.AnyVersion when the header is
+// "*" or missing, unless the configuration requires it. Writes compare a single version, so a list of tags or a
+// weak tag never matches.
+func IfMatch(ec echo.Context) (uint, error) {
+	header := strings.TrimSpace(ec.Request().Header.Get(HeaderIfMatch))
+	if header == "" {
+		if configs.Env.ETag.RequireIfMatch {
+			return 0, echo.NewHTTPError(http.StatusPreconditionRequired, "the If-Match header is required.")
+		}
+		return domain.AnyVersion, nil
+	}
+
+	if header == "*" {
+		return domain.AnyVersion, nil
+	}
+
+	version, ok := Version(header)
+	if !ok {
+		return 0, helper.PreconditionFailedErr
+	}
+
+	return version, nil
+}
+
+// JSON writes data, the representation of the version of a resource, with its entity tag, or only the tag with
+// 304 Not Modified when the If-None-Match header of the request holds it
+func JSON(ec echo.Context, version uint, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tag := Tag(version, body)
+	ec.Response().Header().Set(HeaderETag, tag)
+	// the body is translated in the locales of the request, a cache must not revalidate another locale with its tag
+	vary(ec.Response().Header(), "Accept-Language")
+	if noneMatch(ec.Request().Header.Get(HeaderIfNoneMatch), tag) {
+		return ec.NoContent(http.StatusNotModified)
+	}
+
+	return ec.JSONBlob(http.StatusOK, body)
+}
+
+// noneMatch tells whether the If-None-Match header holds the tag, comparing the tags weakly
+func noneMatch(header string, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// vary adds name to the Vary header unless it is listed already
+func vary(header http.Header, name string) {
+	for _, value := range header.Values(echo.HeaderVary) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return
+			}
+		}
+	}
+
+	header.Add(echo.HeaderVary, name)
+}
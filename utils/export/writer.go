@@ -0,0 +1,233 @@
+// Package export writes rows of named columns as CSV, NDJSON or Parquet, streaming them to the underlying writer.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-movie-api/utils/parquet"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// ContentTypes are the media types of the formats
+var ContentTypes = map[string]string{
+	FormatCSV:     "text/csv; charset=utf-8",
+	FormatNDJSON:  "application/x-ndjson",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+// ListSeparator joins the items of list columns in CSV and Parquet, NDJSON writes them as arrays
+const ListSeparator = "|"
+
+// parquetRowGroupSize bounds the rows a Parquet export holds in memory before writing them
+const parquetRowGroupSize = 10000
+
+// Column is a named column, its values are string, int32, float32, bool, time.Time, []string for list columns or nil
+type Column struct {
+	Name     string
+	Type     parquet.Type
+	Optional bool
+	List     bool
+}
+
+type Writer interface {
+	// Write adds a row, values are in column order
+	Write(values ...interface{}) error
+	// Close writes what is buffered, it does not close the underlying writer
+	Close() error
+}
+
+// NewWriter writes rows of the columns to w in the format
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case FormatParquet:
+		return newParquetWriter(w, columns), nil
+	}
+
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.Name)
+	}
+
+	writer := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := writer.w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (writer *csvWriter) Write(values ...interface{}) error {
+	for i, value := range values {
+		switch value := value.(type) {
+		case nil:
+			writer.record[i] = ""
+		case string:
+			writer.record[i] = value
+		case int32:
+			writer.record[i] = strconv.FormatInt(int64(value), 10)
+		case float32:
+			writer.record[i] = strconv.FormatFloat(float64(value), 'f', -1, 32)
+		case bool:
+			writer.record[i] = strconv.FormatBool(value)
+		case time.Time:
+			writer.record[i] = value.UTC().Format(time.RFC3339)
+		case []string:
+			writer.record[i] = strings.Join(value, ListSeparator)
+		default:
+			return fmt.Errorf("export: cannot write %T", value)
+		}
+	}
+
+	return writer.w.Write(writer.record)
+}
+
+func (writer *csvWriter) Close() error {
+	writer.w.Flush()
+	return writer.w.Error()
+}
+
+// ndjsonWriter writes each row as a JSON object with the keys in column order
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []Column
+}
+
+func (writer *ndjsonWriter) Write(values ...interface{}) error {
+	writer.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			writer.w.WriteByte(',')
+		}
+
+		if moment, ok := value.(time.Time); ok {
+			value = moment.UTC().Format(time.RFC3339)
+		}
+		if list, ok := value.([]string); ok && list == nil {
+			value = []string{}
+		}
+
+		key, _ := json.Marshal(writer.columns[i].Name)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		writer.w.Write(key)
+		writer.w.WriteByte(':')
+		writer.w.Write(encoded)
+	}
+	writer.w.WriteString("}\n")
+
+	return nil
+}
+
+func (writer *ndjsonWriter) Close() error {
+	return writer.w.Flush()
+}
+
+type parquetWriter struct {
+	buffered *bufio.Writer
+	w        *parquet.Writer
+}
+
+func newParquetWriter(w io.Writer, columns []Column) *parquetWriter {
+	parquetColumns := make([]parquet.Column, 0, len(columns))
+	for _, column := range columns {
+		parquetColumns = append(parquetColumns, parquet.Column{Name: column.Name, Type: column.Type, Optional: column.Optional})
+	}
+
+	buffered := bufio.NewWriter(w)
+	return &parquetWriter{buffered: buffered, w: parquet.NewWriter(buffered, parquetColumns, parquetRowGroupSize)}
+}
+
+func (writer *parquetWriter) Write(values ...interface{}) error {
+	for i, value := range values {
+		if list, ok := value.([]string); ok {
+			values[i] = strings.Join(list, ListSeparator)
+		}
+	}
+
+	return writer.w.Write(values...)
+}
+
+func (writer *parquetWriter) Close() error {
+	if err := writer.w.Close(); err != nil {
+		return err
+	}
+
+	return writer.buffered.Flush()
+}
+
+// Negotiate picks the format of an export from the Accept header, CSV unless another format is asked for
+func Negotiate(accept string) string {
+	for _, format := range []string{FormatNDJSON, FormatParquet} {
+		mediaType, _, _ := strings.Cut(ContentTypes[format], ";")
+		if strings.Contains(accept, mediaType) {
+			return format
+		}
+	}
+
+	return FormatCSV
+}
+
+// Download is the response to an export named name, e.g. movies.csv. The writers buffer, so nothing reaches it
+// before the rows are read, and it only makes the response a download on its first write: an export whose query
+// fails is answered as an error instead of as a broken download.
+type Download struct {
+	response http.ResponseWriter
+	name     string
+	format   string
+	started  bool
+}
+
+func NewDownload(response http.ResponseWriter, name string, format string) *Download {
+	return &Download{response: response, name: name, format: format}
+}
+
+func (download *Download) Write(p []byte) (int, error) {
+	download.start()
+	return download.response.Write(p)
+}
+
+// Commit sends the headers of an export that wrote nothing, e.g. an NDJSON export without rows
+func (download *Download) Commit() {
+	if !download.started {
+		download.start()
+		download.response.WriteHeader(http.StatusOK)
+	}
+}
+
+func (download *Download) start() {
+	if download.started {
+		return
+	}
+	download.started = true
+
+	header := download.response.Header()
+	header.Set("Content-Type", ContentTypes[download.format])
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, download.name, download.format))
+}
@@ -21,6 +21,28 @@ var (
 	// UnauthorizedErr will throw if the current request is unauthorized
 	UnauthorizedErr = errors.New("Unauthorized")
 
+	// PreconditionFailedErr will throw if the requested item changed since the version the request expects
+	PreconditionFailedErr = errors.New("Requested data was modified since it was read")
+
 	// IncorrectCredentialErr will throw if the email or password credential is incorrect
 	IncorrectCredentialErr = errors.New("Login failed. Email or password is incorrect.")
 )
+
+// LinkedError is an error that points the client to a related resource, e.g. the rating a conflict is about
+type LinkedError struct {
+	Err  error
+	Link string
+}
+
+func (e *LinkedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LinkedError) Unwrap() error {
+	return e.Err
+}
+
+// NewLinkedError wraps err with a link to a related resource
+func NewLinkedError(err error, link string) error {
+	return &LinkedError{Err: err, Link: link}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"golang.org/x/text/language"
+)
+
+// maxLocales bounds the fallback chain built from an Accept-Language header
+const maxLocales = 10
+
+type localesKey struct{}
+
+// ParseLocales turns an Accept-Language header into a fallback chain of locales, most preferred first.
+// Every language is followed by its parents, e.g. "pt-BR, en;q=0.5" becomes [pt-BR pt en].
+func ParseLocales(acceptLanguage string) []string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil
+	}
+
+	var locales []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for ; tag != language.Und && len(locales) < maxLocales; tag = tag.Parent() {
+			locale := tag.String()
+			if seen[locale] {
+				continue
+			}
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+
+	return locales
+}
+
+// NormalizeLocale returns the canonical form of a BCP 47 locale, e.g. "pt-br" becomes "pt-BR"
+func NormalizeLocale(locale string) (string, bool) {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return "", false
+	}
+
+	return tag.String(), true
+}
+
+// ContextWithLocales stores the fallback chain of the request in ctx
+func ContextWithLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, localesKey{}, locales)
+}
+
+// Locales returns the fallback chain stored in ctx, nil means the untranslated text is wanted
+func Locales(ctx context.Context) []string {
+	locales, _ := ctx.Value(localesKey{}).([]string)
+	return locales
+}
@@ -0,0 +1,97 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol types used by the Parquet metadata
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol, fields must be written in increasing id order
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastID  int16
+	parents []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.zigzag(int64(id))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) varint(value uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	t.buf.Write(buf[:binary.PutUvarint(buf[:], value)])
+}
+
+func (t *thriftWriter) zigzag(value int64) {
+	t.varint(uint64(value<<1 ^ value>>63))
+}
+
+func (t *thriftWriter) i32(id int16, value int32) {
+	t.fieldHeader(id, thriftI32)
+	t.zigzag(int64(value))
+}
+
+func (t *thriftWriter) i64(id int16, value int64) {
+	t.fieldHeader(id, thriftI64)
+	t.zigzag(value)
+}
+
+func (t *thriftWriter) binary(id int16, value string) {
+	t.fieldHeader(id, thriftBinary)
+	t.varint(uint64(len(value)))
+	t.buf.WriteString(value)
+}
+
+// list starts a list field, its size elements follow
+func (t *thriftWriter) list(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		t.buf.WriteByte(0xF0 | elementType)
+		t.varint(uint64(size))
+	}
+}
+
+// structField starts a struct field, it is closed by end
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.begin()
+}
+
+// begin starts a struct, either a list element or the top level struct
+func (t *thriftWriter) begin() {
+	t.parents = append(t.parents, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) end() {
+	t.buf.WriteByte(0)
+	t.lastID = t.parents[len(t.parents)-1]
+	t.parents = t.parents[:len(t.parents)-1]
+}
+
+// listI32 writes an element of a list of i32
+func (t *thriftWriter) listI32(value int32) {
+	t.zigzag(int64(value))
+}
+
+// listBinary writes an element of a list of binary
+func (t *thriftWriter) listBinary(value string) {
+	t.varint(uint64(len(value)))
+	t.buf.WriteString(value)
+}
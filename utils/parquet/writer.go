@@ -0,0 +1,381 @@
+// Package parquet writes flat Parquet files: uncompressed and PLAIN encoded, with one data page per column chunk.
+// Rows are buffered until a row group is full, so memory grows with the row group size and not with the file.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+var magic = []byte("PAR1")
+
+type Type int
+
+const (
+	// String is a UTF-8 BYTE_ARRAY
+	String Type = iota
+	Int32
+	Int64
+	Float
+	Double
+	Boolean
+	// Timestamp is an INT64 of milliseconds since the Unix epoch in UTC
+	Timestamp
+)
+
+// Parquet physical types, repetitions, converted types and encodings
+const (
+	physicalBoolean   = 0
+	physicalInt32     = 1
+	physicalInt64     = 2
+	physicalFloat     = 4
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData = 0
+)
+
+type Column struct {
+	Name string
+	Type Type
+	// Optional columns accept nil values
+	Optional bool
+}
+
+func (column Column) physicalType() int32 {
+	switch column.Type {
+	case String:
+		return physicalByteArray
+	case Int32:
+		return physicalInt32
+	case Int64, Timestamp:
+		return physicalInt64
+	case Float:
+		return physicalFloat
+	case Double:
+		return physicalDouble
+	}
+
+	return physicalBoolean
+}
+
+type columnBuffer struct {
+	values bytes.Buffer
+	// bools are bit packed when the page is written
+	bools []bool
+	// levels are the definition levels of an optional column, 0 for null and 1 for a value
+	levels []bool
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+type rowGroup struct {
+	rows   int64
+	size   int64
+	chunks []columnChunk
+}
+
+type Writer struct {
+	w            io.Writer
+	offset       int64
+	columns      []Column
+	buffers      []columnBuffer
+	rowGroupSize int
+	rows         int
+	rowGroups    []rowGroup
+}
+
+// NewWriter writes rows of the columns to w, rowGroupSize rows per row group
+func NewWriter(w io.Writer, columns []Column, rowGroupSize int) *Writer {
+	return &Writer{
+		w:            w,
+		columns:      columns,
+		buffers:      make([]columnBuffer, len(columns)),
+		rowGroupSize: rowGroupSize,
+	}
+}
+
+// Write adds a row, values are in column order and typed string, int32, int64, float32, float64, bool or time.Time
+func (writer *Writer) Write(values ...interface{}) error {
+	if len(values) != len(writer.columns) {
+		return fmt.Errorf("parquet: %d values for %d columns", len(values), len(writer.columns))
+	}
+
+	for i, value := range values {
+		if !writer.columns[i].accepts(value) {
+			return fmt.Errorf("parquet: column %s cannot hold %T", writer.columns[i].Name, value)
+		}
+	}
+	for i, value := range values {
+		writer.buffers[i].add(writer.columns[i], value)
+	}
+
+	writer.rows++
+	if writer.rows >= writer.rowGroupSize {
+		return writer.flush()
+	}
+
+	return nil
+}
+
+// Close writes the buffered rows and the footer, it does not close the underlying writer
+func (writer *Writer) Close() error {
+	if err := writer.flush(); err != nil {
+		return err
+	}
+	if writer.offset == 0 {
+		if err := writer.write(magic); err != nil {
+			return err
+		}
+	}
+
+	footer := writer.footer()
+	if err := writer.write(footer); err != nil {
+		return err
+	}
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if err := writer.write(length[:]); err != nil {
+		return err
+	}
+
+	return writer.write(magic)
+}
+
+// accepts reports whether the column can hold the value, values are checked before any is buffered
+func (column Column) accepts(value interface{}) bool {
+	var ok bool
+	switch value.(type) {
+	case nil:
+		ok = column.Optional
+	case string:
+		ok = column.Type == String
+	case int32:
+		ok = column.Type == Int32
+	case int64:
+		ok = column.Type == Int64
+	case float32:
+		ok = column.Type == Float
+	case float64:
+		ok = column.Type == Double
+	case bool:
+		ok = column.Type == Boolean
+	case time.Time:
+		ok = column.Type == Timestamp
+	}
+
+	return ok
+}
+
+func (buffer *columnBuffer) add(column Column, value interface{}) {
+	if column.Optional {
+		buffer.levels = append(buffer.levels, value != nil)
+	}
+
+	switch value := value.(type) {
+	case string:
+		_ = binary.Write(&buffer.values, binary.LittleEndian, uint32(len(value)))
+		buffer.values.WriteString(value)
+	case int32, int64:
+		_ = binary.Write(&buffer.values, binary.LittleEndian, value)
+	case float32:
+		_ = binary.Write(&buffer.values, binary.LittleEndian, math.Float32bits(value))
+	case float64:
+		_ = binary.Write(&buffer.values, binary.LittleEndian, math.Float64bits(value))
+	case bool:
+		buffer.bools = append(buffer.bools, value)
+	case time.Time:
+		_ = binary.Write(&buffer.values, binary.LittleEndian, value.UnixMilli())
+	}
+}
+
+// flush writes the buffered rows as a row group
+func (writer *Writer) flush() error {
+	if writer.rows == 0 {
+		return nil
+	}
+	if writer.offset == 0 {
+		if err := writer.write(magic); err != nil {
+			return err
+		}
+	}
+
+	group := rowGroup{rows: int64(writer.rows)}
+	for i, column := range writer.columns {
+		page := writer.buffers[i].page(column)
+		header := pageHeader(writer.rows, len(page))
+
+		chunk := columnChunk{offset: writer.offset, size: int64(len(header) + len(page))}
+		if err := writer.write(header); err != nil {
+			return err
+		}
+		if err := writer.write(page); err != nil {
+			return err
+		}
+
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+		writer.buffers[i] = columnBuffer{}
+	}
+
+	writer.rowGroups = append(writer.rowGroups, group)
+	writer.rows = 0
+
+	return nil
+}
+
+func (writer *Writer) write(data []byte) error {
+	n, err := writer.w.Write(data)
+	writer.offset += int64(n)
+
+	return err
+}
+
+// page encodes the definition levels of an optional column, then the values
+func (buffer *columnBuffer) page(column Column) []byte {
+	var page bytes.Buffer
+	if column.Optional {
+		levels := encodeLevels(buffer.levels)
+		_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+		page.Write(levels)
+	}
+
+	if column.Type == Boolean {
+		packed := make([]byte, (len(buffer.bools)+7)/8)
+		for i, flag := range buffer.bools {
+			if flag {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		page.Write(packed)
+	} else {
+		page.Write(buffer.values.Bytes())
+	}
+
+	return page.Bytes()
+}
+
+// encodeLevels writes levels of bit width 1 as RLE runs of the hybrid RLE/bit-packing encoding
+func encodeLevels(levels []bool) []byte {
+	var encoded bytes.Buffer
+	var buf [binary.MaxVarintLen64]byte
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+
+		encoded.Write(buf[:binary.PutUvarint(buf[:], uint64(end-start)<<1)])
+		if levels[start] {
+			encoded.WriteByte(1)
+		} else {
+			encoded.WriteByte(0)
+		}
+		start = end
+	}
+
+	return encoded.Bytes()
+}
+
+func pageHeader(rows int, size int) []byte {
+	var t thriftWriter
+	t.begin()
+	t.i32(1, pageTypeData)
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.structField(5)
+	t.i32(1, int32(rows))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.end()
+	t.end()
+
+	return t.buf.Bytes()
+}
+
+func (writer *Writer) footer() []byte {
+	var rows int64
+	for _, group := range writer.rowGroups {
+		rows += group.rows
+	}
+
+	var t thriftWriter
+	t.begin()
+	t.i32(1, 1)
+
+	t.list(2, thriftStruct, len(writer.columns)+1)
+	t.begin()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(writer.columns)))
+	t.end()
+	for _, column := range writer.columns {
+		t.begin()
+		t.i32(1, column.physicalType())
+		if column.Optional {
+			t.i32(3, repetitionOptional)
+		} else {
+			t.i32(3, repetitionRequired)
+		}
+		t.binary(4, column.Name)
+		switch column.Type {
+		case String:
+			t.i32(6, convertedUTF8)
+		case Timestamp:
+			t.i32(6, convertedTimestampMillis)
+		}
+		t.end()
+	}
+
+	t.i64(3, rows)
+
+	t.list(4, thriftStruct, len(writer.rowGroups))
+	for _, group := range writer.rowGroups {
+		t.begin()
+		t.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			column := writer.columns[i]
+			t.begin()
+			t.i64(2, chunk.offset)
+			t.structField(3)
+			t.i32(1, column.physicalType())
+			t.list(2, thriftI32, 2)
+			t.listI32(encodingPlain)
+			t.listI32(encodingRLE)
+			t.list(3, thriftBinary, 1)
+			t.listBinary(column.Name)
+			t.i32(4, 0)
+			t.i64(5, group.rows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.end()
+			t.end()
+		}
+		t.i64(2, group.size)
+		t.i64(3, group.rows)
+		t.end()
+	}
+
+	t.binary(6, "go-movie-api")
+	t.end()
+
+	return t.buf.Bytes()
+}
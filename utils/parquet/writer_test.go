@@ -0,0 +1,316 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// thriftReader decodes Thrift compact structs into maps of field id to value, lists become slices
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) zigzag() int64 {
+	value := r.varint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0F)
+	}
+}
+
+func (r *thriftReader) value(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		size := int(r.varint())
+		r.pos += size
+		return string(r.data[r.pos-size : r.pos])
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0F)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+
+	panic("unexpected thrift type")
+}
+
+// readColumn decodes the values of the data page at offset, nil for the nulls of an optional column
+func readColumn(t *testing.T, data []byte, offset int64, column Column) []interface{} {
+	t.Helper()
+
+	r := &thriftReader{data: data, pos: int(offset)}
+	header := r.structure()
+	if header[1] != int64(pageTypeData) || header[2] != header[3] {
+		t.Fatalf("page header of %s = %v", column.Name, header)
+	}
+	dataPage := header[5].(map[int16]interface{})
+	count := int(dataPage[1].(int64))
+	page := data[r.pos : r.pos+int(header[3].(int64))]
+
+	levels := make([]bool, count)
+	for i := range levels {
+		levels[i] = true
+	}
+	if column.Optional {
+		size := int(binary.LittleEndian.Uint32(page))
+		levels = levels[:0]
+		runs := &thriftReader{data: page[4 : 4+size]}
+		for runs.pos < len(runs.data) {
+			run := runs.varint()
+			if run&1 != 0 {
+				t.Fatalf("definition levels of %s are bit packed", column.Name)
+			}
+			defined := runs.byte() == 1
+			for i := uint64(0); i < run>>1; i++ {
+				levels = append(levels, defined)
+			}
+		}
+		page = page[4+size:]
+	}
+	if len(levels) != count {
+		t.Fatalf("%d definition levels in the page of %s, want %d", len(levels), column.Name, count)
+	}
+
+	values := make([]interface{}, 0, count)
+	var bit int
+	for _, defined := range levels {
+		if !defined {
+			values = append(values, nil)
+			continue
+		}
+		switch column.Type {
+		case String:
+			size := int(binary.LittleEndian.Uint32(page))
+			values = append(values, string(page[4:4+size]))
+			page = page[4+size:]
+		case Int32:
+			values = append(values, int32(binary.LittleEndian.Uint32(page)))
+			page = page[4:]
+		case Int64:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case Timestamp:
+			values = append(values, time.UnixMilli(int64(binary.LittleEndian.Uint64(page))).UTC())
+			page = page[8:]
+		case Float:
+			values = append(values, math.Float32frombits(binary.LittleEndian.Uint32(page)))
+			page = page[4:]
+		case Double:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case Boolean:
+			values = append(values, page[bit/8]&(1<<(bit%8)) != 0)
+			bit++
+		}
+	}
+
+	return values
+}
+
+func TestWriterReadBack(t *testing.T) {
+	columns := []Column{
+		{Name: "title", Type: String},
+		{Name: "year", Type: Int32},
+		{Name: "votes", Type: Int64},
+		{Name: "rating", Type: Float, Optional: true},
+		{Name: "score", Type: Double, Optional: true},
+		{Name: "adult", Type: Boolean},
+		{Name: "released", Type: Boolean, Optional: true},
+		{Name: "created_at", Type: Timestamp, Optional: true},
+		{Name: "synopsis", Type: String, Optional: true},
+	}
+	createdAt := time.Date(2023, 5, 1, 12, 30, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{"Heat", int32(1995), int64(120), float32(8.5), 8.25, false, true, createdAt, "A heist."},
+		{"Ronin", int32(1998), int64(0), nil, nil, false, nil, nil, nil},
+		{"Alien", int32(1979), int64(42), float32(9), nil, true, false, createdAt.Add(time.Hour), ""},
+		{"Manhunter", int32(1986), int64(7), nil, 7.5, false, nil, nil, "Graham."},
+		{"Thief", int32(1981), int64(3), float32(7), 6.5, true, true, createdAt, nil},
+	}
+
+	var file bytes.Buffer
+	writer := NewWriter(&file, columns, 2)
+	for _, row := range rows {
+		if err := writer.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := file.Bytes()
+	if !bytes.Equal(data[:4], magic) || !bytes.Equal(data[len(data)-4:], magic) {
+		t.Fatalf("the file does not start and end with %s", magic)
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerOffset := len(data) - 8 - footerLength
+	metadata := (&thriftReader{data: data[footerOffset : len(data)-8]}).structure()
+
+	if metadata[1] != int64(1) || metadata[3] != int64(len(rows)) {
+		t.Errorf("version %v and rows %v, want 1 and %d", metadata[1], metadata[3], len(rows))
+	}
+
+	schema := metadata[2].([]interface{})
+	if len(schema) != len(columns)+1 || schema[0].(map[int16]interface{})[5] != int64(len(columns)) {
+		t.Fatalf("schema = %v", schema)
+	}
+	for i, column := range columns {
+		element := schema[i+1].(map[int16]interface{})
+		repetition := int64(repetitionRequired)
+		if column.Optional {
+			repetition = repetitionOptional
+		}
+		if element[1] != int64(column.physicalType()) || element[3] != repetition || element[4] != column.Name {
+			t.Errorf("schema element of %s = %v", column.Name, element)
+		}
+		converted, ok := element[6]
+		switch column.Type {
+		case String:
+			if converted != int64(convertedUTF8) {
+				t.Errorf("converted type of %s = %v, want UTF8", column.Name, converted)
+			}
+		case Timestamp:
+			if converted != int64(convertedTimestampMillis) {
+				t.Errorf("converted type of %s = %v, want TIMESTAMP_MILLIS", column.Name, converted)
+			}
+		default:
+			if ok {
+				t.Errorf("converted type of %s = %v, want none", column.Name, converted)
+			}
+		}
+	}
+
+	groups := metadata[4].([]interface{})
+	if len(groups) != 3 {
+		t.Fatalf("%d row groups, want 3", len(groups))
+	}
+	read := make([][]interface{}, len(columns))
+	end := int64(len(magic))
+	for _, group := range groups {
+		group := group.(map[int16]interface{})
+		groupRows := group[3].(int64)
+		chunks := group[1].([]interface{})
+		if len(chunks) != len(columns) {
+			t.Fatalf("%d column chunks, want %d", len(chunks), len(columns))
+		}
+
+		var groupSize int64
+		for i, column := range columns {
+			chunk := chunks[i].(map[int16]interface{})
+			meta := chunk[3].(map[int16]interface{})
+			offset := chunk[2].(int64)
+			if offset != end || meta[9] != offset {
+				t.Errorf("chunk of %s at %v with its page at %v, want both at %d", column.Name, offset, meta[9], end)
+			}
+			if meta[1] != int64(column.physicalType()) || !reflect.DeepEqual(meta[3], []interface{}{column.Name}) ||
+				meta[5] != groupRows || meta[6] != meta[7] {
+				t.Errorf("column chunk metadata of %s = %v", column.Name, meta)
+			}
+
+			read[i] = append(read[i], readColumn(t, data, offset, column)...)
+			end = offset + meta[7].(int64)
+			groupSize += meta[7].(int64)
+		}
+		if group[2] != groupSize {
+			t.Errorf("row group size %v, want %d", group[2], groupSize)
+		}
+	}
+	if end != int64(footerOffset) {
+		t.Errorf("the column chunks end at %d, the footer starts at %d", end, footerOffset)
+	}
+
+	for i, column := range columns {
+		for j, row := range rows {
+			if !reflect.DeepEqual(read[i][j], row[i]) {
+				t.Errorf("row %d of %s = %v, want %v", j, column.Name, read[i][j], row[i])
+			}
+		}
+	}
+}
+
+func TestWriterWithoutRows(t *testing.T) {
+	var file bytes.Buffer
+	writer := NewWriter(&file, []Column{{Name: "title", Type: String}}, 10)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := file.Bytes()
+	if !bytes.Equal(data[:4], magic) || !bytes.Equal(data[len(data)-4:], magic) {
+		t.Fatalf("the file does not start and end with %s", magic)
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if footerLength != len(data)-12 {
+		t.Fatalf("footer of %d bytes in a file of %d", footerLength, len(data))
+	}
+	metadata := (&thriftReader{data: data[4 : len(data)-8]}).structure()
+	if metadata[3] != int64(0) || len(metadata[4].([]interface{})) != 0 {
+		t.Errorf("metadata of an empty file = %v", metadata)
+	}
+}
+
+func TestWriterRejectsValues(t *testing.T) {
+	columns := []Column{{Name: "title", Type: String}, {Name: "rating", Type: Float, Optional: true}}
+	tests := [][]interface{}{
+		{"Heat"},
+		{nil, float32(8)},
+		{"Heat", 8.0},
+		{42, float32(8)},
+	}
+
+	for _, values := range tests {
+		var file bytes.Buffer
+		writer := NewWriter(&file, columns, 10)
+		if err := writer.Write(values...); err == nil {
+			t.Errorf("Write(%v) succeeded", values)
+		}
+		if err := writer.Write("Heat", nil); err != nil {
+			t.Fatal(err)
+		}
+		if writer.rows != 1 || writer.buffers[0].values.Len() != len("Heat")+4 {
+			t.Errorf("Write(%v) buffered part of the row", values)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902) documents to JSON documents.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	// MalformedErr is returned for a patch that is not valid JSON or not a valid patch of its media type
+	MalformedErr = errors.New("the patch is not valid")
+
+	// ConflictErr is returned for a JSON Patch operation that does not apply to the document, e.g. its path does not exist or its test fails
+	ConflictErr = errors.New("the patch cannot be applied")
+)
+
+// Apply applies the patch, of one of the patch media types, to the document
+func Apply(mediaType string, document []byte, patch []byte) ([]byte, error) {
+	switch mediaType {
+	case MediaTypeMergePatch:
+		return MergePatch(document, patch)
+	case MediaTypeJSONPatch:
+		return JSONPatch(document, patch)
+	}
+
+	return nil, fmt.Errorf("%w, the media type %q is not a patch.", MalformedErr, mediaType)
+}
+
+// MergePatch applies a JSON Merge Patch to the document, members set to null in the patch are removed
+func MergePatch(document []byte, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := decode(document, &target); err != nil {
+		return nil, err
+	}
+
+	var source interface{}
+	if err := decode(patch, &source); err != nil {
+		return nil, fmt.Errorf("%w, %s.", MalformedErr, err)
+	}
+
+	return json.Marshal(merge(target, source))
+}
+
+func merge(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = merge(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// operation is an operation of a JSON Patch, Value is nil when the member is missing and "null" when it is null
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies the operations of a JSON Patch to the document in order, none of them applies when one fails
+func JSONPatch(document []byte, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := decode(document, &target); err != nil {
+		return nil, err
+	}
+
+	var operations []operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w, it must be an array of operations.", MalformedErr)
+	}
+
+	for i, op := range operations {
+		var err error
+		if target, err = op.apply(target); err != nil {
+			return nil, fmt.Errorf("%w, in operation %d.", err, i)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func (op operation) apply(target interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w, the %s operation has no path", MalformedErr, op.Op)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(target, path, value)
+		case "replace":
+			return replace(target, path, value)
+		}
+		current, err := get(target, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, fmt.Errorf("%w, the value at %q is not the tested one", ConflictErr, *op.Path)
+		}
+		return target, nil
+	case "remove":
+		return remove(target, path)
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w, the %s operation has no from", MalformedErr, op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(target, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return add(target, path, clone(value))
+		}
+		if isPrefix(from, path) {
+			if len(from) == len(path) {
+				return target, nil
+			}
+			return nil, fmt.Errorf("%w, %q cannot be moved into itself", ConflictErr, *op.From)
+		}
+		if target, err = remove(target, from); err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	}
+
+	return nil, fmt.Errorf("%w, the operation %q is not known", MalformedErr, op.Op)
+}
+
+func (op operation) value() (interface{}, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%w, the %s operation has no value", MalformedErr, op.Op)
+	}
+
+	var value interface{}
+	if err := decode(op.Value, &value); err != nil {
+		return nil, fmt.Errorf("%w, %s", MalformedErr, err)
+	}
+
+	return value, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens, the empty pointer is the whole document
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w, the path %q does not start with a slash", MalformedErr, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, notFound(token)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, notFound(token)
+		}
+	}
+
+	return node, nil
+}
+
+// update replaces the container at all but the last token of the path by what fn makes of it
+func update(node interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	child, err := get(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = update(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		container[path[0]] = child
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(container)-1)
+		container[index] = child
+	}
+
+	return node, nil
+}
+
+func add(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(node, path, func(container interface{}, token string) (interface{}, error) {
+		switch container := container.(type) {
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil
+		case []interface{}:
+			index := len(container)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(container)); err != nil {
+					return nil, err
+				}
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+		return nil, notFound(token)
+	})
+}
+
+func remove(node interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w, the whole document cannot be removed", ConflictErr)
+	}
+
+	return update(node, path, func(container interface{}, token string) (interface{}, error) {
+		switch container := container.(type) {
+		case map[string]interface{}:
+			if _, ok := container[token]; !ok {
+				return nil, notFound(token)
+			}
+			delete(container, token)
+			return container, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:index], container[index+1:]...), nil
+		}
+		return nil, notFound(token)
+	})
+}
+
+func replace(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if _, err := get(node, path); err != nil {
+		return nil, err
+	}
+
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(node, path, func(container interface{}, token string) (interface{}, error) {
+		switch container := container.(type) {
+		case map[string]interface{}:
+			container[token] = value
+		case []interface{}:
+			index, _ := arrayIndex(token, len(container)-1)
+			container[index] = value
+		}
+		return container, nil
+	})
+}
+
+// arrayIndex parses an array index of at most max, leading zeros are not allowed
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, notFound(token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max {
+		return 0, notFound(token)
+	}
+
+	return index, nil
+}
+
+func notFound(token string) error {
+	return fmt.Errorf("%w, the path member %q does not exist", ConflictErr, token)
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equal compares two JSON values, numbers by their value and objects regardless of the order of their members
+func equal(a interface{}, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errX := a.Float64()
+		y, errY := b.Float64()
+		return errX == nil && errY == nil && x == y
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+// clone copies a JSON value deeply, so that a copied value does not change along with its source
+func clone(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for key, member := range value {
+			copied[key] = clone(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = clone(item)
+		}
+		return copied
+	}
+
+	return value
+}
+
+// decode unmarshals JSON keeping numbers as json.Number, so that they are written back as they were
+func decode(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after the JSON value")
+	}
+
+	return nil
+}
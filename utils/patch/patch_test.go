@@ -0,0 +1,235 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMergePatch runs the examples of RFC 7396, Appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		document string
+		patch    string
+		want     string
+	}{
+		{document: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{document: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{document: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{document: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{document: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{document: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{document: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{document: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{document: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{document: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{document: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{document: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{document: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		patched, err := MergePatch([]byte(test.document), []byte(test.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", test.document, test.patch, err)
+			continue
+		}
+		assertJSON(t, patched, test.want)
+	}
+}
+
+func TestMergePatchRejectsInvalidJSON(t *testing.T) {
+	_, err := MergePatch([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	if !errors.Is(err, MalformedErr) {
+		t.Errorf("MergePatch of invalid JSON: error %v, want MalformedErr", err)
+	}
+}
+
+// TestJSONPatch runs the examples of RFC 6902, Appendix A, and a few of its error cases
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+		err      error
+	}{
+		{
+			name:     "A.1 adding an object member",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:     `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:     "A.2 adding an array element",
+			document: `{"foo":["bar","baz"]}`,
+			patch:    `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:     `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:     "A.3 removing an object member",
+			document: `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"remove","path":"/baz"}]`,
+			want:     `{"foo":"bar"}`,
+		},
+		{
+			name:     "A.4 removing an array element",
+			document: `{"foo":["bar","qux","baz"]}`,
+			patch:    `[{"op":"remove","path":"/foo/1"}]`,
+			want:     `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:     "A.5 replacing a value",
+			document: `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:     `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:     "A.6 moving a value",
+			document: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:    `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:     `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:     "A.7 moving an array element",
+			document: `{"foo":["all","grass","cows","eat"]}`,
+			patch:    `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:     `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:     "A.8 testing a value, success",
+			document: `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch:    `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			want:     `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:     "A.9 testing a value, error",
+			document: `{"baz":"qux"}`,
+			patch:    `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:      ConflictErr,
+		},
+		{
+			name:     "A.10 adding a nested member object",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:     `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:     "A.11 ignoring unrecognized elements",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:     `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:     "A.12 adding to a nonexistent target",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:      ConflictErr,
+		},
+		{
+			name:     "A.14 ~ escape ordering",
+			document: `{"/":9,"~1":10}`,
+			patch:    `[{"op":"test","path":"/~01","value":10}]`,
+			want:     `{"/":9,"~1":10}`,
+		},
+		{
+			name:     "A.15 comparing strings and numbers",
+			document: `{"/":9,"~1":10}`,
+			patch:    `[{"op":"test","path":"/~01","value":"10"}]`,
+			err:      ConflictErr,
+		},
+		{
+			name:     "A.16 adding an array value",
+			document: `{"foo":["bar"]}`,
+			patch:    `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:     `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:     "copying a value",
+			document: `{"foo":{"bar":[1]}}`,
+			patch:    `[{"op":"copy","from":"/foo/bar","path":"/baz"},{"op":"add","path":"/baz/-","value":2}]`,
+			want:     `{"foo":{"bar":[1]},"baz":[1,2]}`,
+		},
+		{
+			name:     "moving a value into itself",
+			document: `{"foo":{"bar":1}}`,
+			patch:    `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			err:      ConflictErr,
+		},
+		{
+			name:     "removing the whole document",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"remove","path":""}]`,
+			err:      ConflictErr,
+		},
+		{
+			name:     "an operation without a path",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","value":"qux"}]`,
+			err:      MalformedErr,
+		},
+		{
+			name:     "an add without a value",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz"}]`,
+			err:      MalformedErr,
+		},
+		{
+			name:     "a path without a leading slash",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"foo","value":"baz"}]`,
+			err:      MalformedErr,
+		},
+		{
+			name:     "an unknown operation",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"append","path":"/foo","value":"baz"}]`,
+			err:      MalformedErr,
+		},
+		{
+			name:     "a patch that is not an array",
+			document: `{"foo":"bar"}`,
+			patch:    `{"op":"remove","path":"/foo"}`,
+			err:      MalformedErr,
+		},
+	}
+
+	for _, test := range tests {
+		patched, err := JSONPatch([]byte(test.document), []byte(test.patch))
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("%s: error %v, want %v", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		assertJSON(t, patched, test.want)
+	}
+}
+
+func TestApplyRejectsOtherMediaTypes(t *testing.T) {
+	_, err := Apply("application/json", []byte(`{}`), []byte(`{}`))
+	if !errors.Is(err, MalformedErr) {
+		t.Errorf("Apply of application/json: error %v, want MalformedErr", err)
+	}
+}
+
+// assertJSON compares the JSON values regardless of the order of object members
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var gotValue, wantValue interface{}
+	if err := decode(got, &gotValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := decode([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", want, err)
+	}
+	if !equal(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// HeaderAcceptPatch lists the patch media types in the responses of PATCH requests that sent another one
+const HeaderAcceptPatch = "Accept-Patch"
+
+// maxSize bounds the patch a request can send
+const maxSize = 1 << 20
+
+// Document is the patch a PATCH request sent
+type Document struct {
+	MediaType string
+	Body      []byte
+}
+
+// FromRequest reads the patch in the body of the request, which must have one of the patch media types
+func FromRequest(ec echo.Context) (Document, error) {
+	mediaType, _, err := mime.ParseMediaType(ec.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != MediaTypeMergePatch && mediaType != MediaTypeJSONPatch) {
+		ec.Response().Header().Set(HeaderAcceptPatch, strings.Join([]string{MediaTypeMergePatch, MediaTypeJSONPatch}, ", "))
+		return Document{}, echo.NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("the patch must be %s or %s.", MediaTypeMergePatch, MediaTypeJSONPatch))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ec.Request().Body, maxSize+1))
+	if err != nil {
+		return Document{}, err
+	}
+	if len(body) > maxSize {
+		return Document{}, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "the patch is too large.")
+	}
+
+	return Document{MediaType: mediaType, Body: body}, nil
+}
+
+// ApplyTo applies the patch to the JSON of v, a struct of the fields the patch can change, and decodes the result
+// back into v. A patch that changes other fields or gives a field a value of another type is unprocessable.
+func (document Document) ApplyTo(v interface{}) error {
+	current, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	patched, err := Apply(document.MediaType, current, document.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, MalformedErr):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case errors.Is(err, ConflictErr):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return err
+	}
+
+	// fields the patch removed are left at their zero value
+	target := reflect.ValueOf(v).Elem()
+	target.Set(reflect.Zero(target.Type()))
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(v); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("the patched document is not valid, %s.", err))
+	}
+
+	return nil
+}
@@ -8,4 +8,5 @@ type Error struct {
 	Message string `json:"message"`
 	Status  int    `json:"status,omitempty"`
 	Error   error  `json:"error,omitempty"`
+	Link    string `json:"link,omitempty"`
 }
@@ -10,6 +10,7 @@ type Success struct {
 }
 
 var (
-	DeleteSuccess = Success{Message: "Data successfully deleted!"}
-	UpdateSuccess = Success{Message: "Data successfully updated!"}
+	DeleteSuccess  = Success{Message: "Data successfully deleted!"}
+	UpdateSuccess  = Success{Message: "Data successfully updated!"}
+	RestoreSuccess = Success{Message: "Data successfully restored!"}
 )
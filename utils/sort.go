@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortField is a single field of a sort expression, e.g. "-year" is {Field: "year", Desc: true}
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a comma separated sort expression such as "-year,title".
+// Only fields listed in allowed are accepted so that the result is safe to use as column names.
+func ParseSort(value string, allowed []string) ([]SortField, error) {
+	var fields []SortField
+	if strings.TrimSpace(value) == "" {
+		return fields, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+
+		if !isAllowed(field.Field, allowed) {
+			return nil, fmt.Errorf("the sort field %q is not valid", field.Field)
+		}
+		if seen[field.Field] {
+			continue
+		}
+		seen[field.Field] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func isAllowed(field string, allowed []string) bool {
+	for _, value := range allowed {
+		if value == field {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"go-movie-api/configs"
+	"math"
 	"net/http"
 )
 
@@ -10,9 +12,36 @@ type RequestValidator struct {
 	Validator *validator.Validate
 }
 
+// NewValidator creates a validator with the custom rules of the API registered
+func NewValidator() (*validator.Validate, error) {
+	v := validator.New()
+	if err := v.RegisterValidation("rating_scale", ValidateRatingScale); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
 func (v *RequestValidator) Validate(request any) error {
 	if err := v.Validator.Struct(request); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 	return nil
 }
+
+// ValidateRatingScale checks that a rating lies on the configured scale, i.e. between rating.scale_min
+// and rating.scale_max and a whole number of rating.scale_step above the minimum
+func ValidateRatingScale(field validator.FieldLevel) bool {
+	scale := configs.Env.Rating
+	value := field.Field().Float()
+
+	if value < float64(scale.ScaleMin) || value > float64(scale.ScaleMax) {
+		return false
+	}
+	if scale.ScaleStep <= 0 {
+		return true
+	}
+
+	steps := (value - float64(scale.ScaleMin)) / float64(scale.ScaleStep)
+	return math.Abs(steps-math.Round(steps)) < 1e-6
+}